@@ -0,0 +1,144 @@
+// Package apperror defines the API error model: errors carrying an HTTP
+// status and a stable machine-readable code, plus field-level details for
+// validation failures. From maps any error, including PostgreSQL errors, onto
+// it so controllers do not inspect error strings.
+package apperror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Codes shared by errors that are not tied to one domain.
+const (
+	CodeInternal         = "internal_server_error"
+	CodeValidation       = "validation_failed"
+	CodeAlreadyExists    = "already_exists"
+	CodeInvalidReference = "invalid_reference"
+	CodeInvalidValue     = "invalid_value"
+	CodeConcurrentUpdate = "concurrent_update"
+	CodeTimeout          = "timeout"
+)
+
+// Errors for conditional requests made with If-Match.
+var (
+	ErrPreconditionRequired = New(http.StatusPreconditionRequired, "precondition_required", "the If-Match header is required")
+	ErrPreconditionFailed   = New(http.StatusPreconditionFailed, "precondition_failed", "the record was modified since it was read")
+)
+
+// FieldError describes a problem with one request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// CodeForStatus derives a code from the status text, such as not_found or
+// too_many_requests, for errors without a more specific one.
+func CodeForStatus(status int) string {
+	return strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+}
+
+// Validation reports invalid request fields.
+func Validation(fields ...FieldError) *Error {
+	return &Error{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    CodeValidation,
+		Message: "request validation failed",
+		Fields:  fields,
+	}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// From maps err onto the error model. Errors that cannot be mapped become a
+// 500 internal_error wrapping the original, which callers should log.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if mapped := fromPgError(pgErr); mapped != nil {
+			mapped.Err = err
+			return mapped
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Status: http.StatusServiceUnavailable, Code: CodeTimeout, Message: "the request timed out", Err: err}
+	}
+
+	return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Message: "internal server error", Err: err}
+}
+
+// fromPgError translates PostgreSQL SQLSTATE codes for constraint and input
+// errors; anything else is left to the caller.
+func fromPgError(pgErr *pgconn.PgError) *Error {
+	switch pgErr.Code {
+	case "23505": // unique_violation
+		field := constraintColumn(pgErr.TableName, pgErr.ConstraintName)
+		e := New(http.StatusConflict, CodeAlreadyExists, "a record with the same value already exists")
+		if field != "" {
+			e.Message = fmt.Sprintf("a record with this %s already exists", field)
+			e.Fields = []FieldError{{Field: field, Code: "taken", Message: field + " is already taken"}}
+		}
+		return e
+	case "23503": // foreign_key_violation
+		return New(http.StatusUnprocessableEntity, CodeInvalidReference, "a referenced record does not exist or is still in use")
+	case "23502": // not_null_violation
+		return Validation(FieldError{Field: pgErr.ColumnName, Code: "required", Message: pgErr.ColumnName + " is required"})
+	case "23514": // check_violation
+		return Validation(FieldError{Field: pgErr.ConstraintName, Code: "constraint", Message: "value violates " + pgErr.ConstraintName})
+	case "22001": // string_data_right_truncation
+		return New(http.StatusUnprocessableEntity, CodeInvalidValue, "a value is too long")
+	case "22P02", "22007", "22008": // invalid_text_representation, invalid and out-of-range datetime
+		return New(http.StatusBadRequest, CodeInvalidValue, "a value has an invalid format")
+	case "40001", "40P01": // serialization_failure, deadlock_detected
+		return New(http.StatusConflict, CodeConcurrentUpdate, "the record was modified concurrently, retry the request")
+	}
+
+	return nil
+}
+
+// constraintColumn recovers the column from PostgreSQL's default constraint
+// name, <table>_<column>_key.
+func constraintColumn(table, constraint string) string {
+	column, ok := strings.CutPrefix(constraint, table+"_")
+	if !ok || table == "" {
+		return ""
+	}
+	column, ok = strings.CutSuffix(column, "_key")
+	if !ok {
+		return ""
+	}
+	return column
+}
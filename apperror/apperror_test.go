@@ -0,0 +1,62 @@
+package apperror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestFrom(t *testing.T) {
+	notFound := New(http.StatusNotFound, "customer_not_found", "customer not found")
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		field  string
+	}{
+		{"app error", fmt.Errorf("loading: %w", notFound), http.StatusNotFound, "customer_not_found", ""},
+		{"unique with default name", &pgconn.PgError{Code: "23505", TableName: "users", ConstraintName: "users_email_key"}, http.StatusConflict, CodeAlreadyExists, "email"},
+		{"unique with custom name", &pgconn.PgError{Code: "23505", TableName: "tags", ConstraintName: "idx_tags_lower_name"}, http.StatusConflict, CodeAlreadyExists, ""},
+		{"foreign key", &pgconn.PgError{Code: "23503"}, http.StatusUnprocessableEntity, CodeInvalidReference, ""},
+		{"not null", &pgconn.PgError{Code: "23502", ColumnName: "name"}, http.StatusUnprocessableEntity, CodeValidation, "name"},
+		{"bad uuid text", &pgconn.PgError{Code: "22P02"}, http.StatusBadRequest, CodeInvalidValue, ""},
+		{"deadlock", &pgconn.PgError{Code: "40P01"}, http.StatusConflict, CodeConcurrentUpdate, ""},
+		{"unmapped pg error", &pgconn.PgError{Code: "42P01"}, http.StatusInternalServerError, CodeInternal, ""},
+		{"timeout", fmt.Errorf("query: %w", context.DeadlineExceeded), http.StatusServiceUnavailable, CodeTimeout, ""},
+		{"anything else", errors.New("boom"), http.StatusInternalServerError, CodeInternal, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := From(tt.err)
+			if got.Status != tt.status || got.Code != tt.code {
+				t.Errorf("From = %d %s, want %d %s", got.Status, got.Code, tt.status, tt.code)
+			}
+
+			var field string
+			if len(got.Fields) > 0 {
+				field = got.Fields[0].Field
+			}
+			if field != tt.field {
+				t.Errorf("field = %q, want %q", field, tt.field)
+			}
+
+			// The cause stays reachable for logging.
+			if !errors.Is(got, tt.err) && got != notFound {
+				t.Errorf("From(%v) does not wrap the original error", tt.err)
+			}
+		})
+	}
+}
+
+func TestCodeForStatus(t *testing.T) {
+	if got := CodeForStatus(http.StatusTooManyRequests); got != "too_many_requests" {
+		t.Errorf("CodeForStatus(429) = %q, want too_many_requests", got)
+	}
+}
@@ -0,0 +1,292 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"customize_crm/model"
+	"customize_crm/service"
+)
+
+// defaultRoles are created by seed-roles when missing.
+var defaultRoles = []model.Role{
+	{
+		Name:        "Admin",
+		Description: "Full access, including user administration",
+		Permissions: json.RawMessage(`["` + model.PermissionImpersonateUsers + `"]`),
+	},
+	{
+		Name:        "Manager",
+		Description: "Sees the records of their reports and teams",
+		Permissions: json.RawMessage(`[]`),
+	},
+	{
+		Name:        "Sales",
+		Description: "Works their own customers, opportunities and tasks",
+		Permissions: json.RawMessage(`[]`),
+	},
+}
+
+func runCreateAdmin(ctx context.Context, c *CLI, args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	username := flags.String("username", "", "login name")
+	email := flags.String("email", "", "email address")
+	firstName := flags.String("first-name", "", "first name")
+	lastName := flags.String("last-name", "", "last name")
+	password := flags.String("password", "", "password; read from stdin when omitted")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *username == "" || *email == "" || *firstName == "" || *lastName == "" {
+		return fmt.Errorf("usage: %s", usages["create-admin"])
+	}
+
+	role, err := c.userService.GetRoleByName(ctx, "Admin")
+	if err != nil {
+		return roleLookupError("Admin", err)
+	}
+
+	if err := c.readPassword(password); err != nil {
+		return err
+	}
+
+	user := &model.User{
+		Username:  *username,
+		Email:     *email,
+		FirstName: *firstName,
+		LastName:  *lastName,
+		RoleID:    role.ID,
+		IsActive:  true,
+	}
+
+	if err := c.userService.Create(ctx, user, *password); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Created admin %s (%s)\n", user.Username, user.ID)
+	return nil
+}
+
+func runResetPassword(ctx context.Context, c *CLI, args []string) error {
+	flags := flag.NewFlagSet("reset-password", flag.ContinueOnError)
+	username := flags.String("username", "", "login name")
+	password := flags.String("password", "", "new password; read from stdin when omitted")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *username == "" {
+		return fmt.Errorf("usage: %s", usages["reset-password"])
+	}
+
+	user, err := c.userService.GetByUsername(ctx, *username)
+	if err != nil {
+		return fmt.Errorf("user %q not found", *username)
+	}
+
+	if err := c.readPassword(password); err != nil {
+		return err
+	}
+
+	if err := c.userService.UpdatePassword(ctx, user.ID, *password); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Password reset for %s\n", user.Username)
+	return nil
+}
+
+func runSeedRoles(ctx context.Context, c *CLI, args []string) error {
+	for _, role := range defaultRoles {
+		created, err := c.userService.CreateRoleIfMissing(ctx, &role)
+		if err != nil {
+			return fmt.Errorf("role %s: %w", role.Name, err)
+		}
+
+		if created {
+			fmt.Fprintf(c.stdout, "Created role %s\n", role.Name)
+		} else {
+			fmt.Fprintf(c.stdout, "Role %s already exists\n", role.Name)
+		}
+	}
+
+	return nil
+}
+
+// runRotateJWTKey generates a new signing secret and, with -env-file, writes
+// it into that file. Every issued token is invalidated once the server is
+// restarted with the new secret.
+func runRotateJWTKey(ctx context.Context, c *CLI, args []string) error {
+	flags := flag.NewFlagSet("rotate-jwt-key", flag.ContinueOnError)
+	envFile := flags.String("env-file", "", "env file to update in place")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	secret, err := service.GenerateJWTSecret()
+	if err != nil {
+		return err
+	}
+
+	if *envFile == "" {
+		fmt.Fprintf(c.stdout, "JWT_SECRET=%s\n", secret)
+		return nil
+	}
+
+	if err := setEnvValue(*envFile, "JWT_SECRET", secret); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Updated JWT_SECRET in %s; restart the server to apply it. Existing tokens will stop working.\n", *envFile)
+	return nil
+}
+
+func runDeactivateUser(ctx context.Context, c *CLI, args []string) error {
+	flags := flag.NewFlagSet("deactivate-user", flag.ContinueOnError)
+	username := flags.String("username", "", "login name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *username == "" {
+		return fmt.Errorf("usage: %s", usages["deactivate-user"])
+	}
+
+	user, err := c.userService.GetByUsername(ctx, *username)
+	if err != nil {
+		return fmt.Errorf("user %q not found", *username)
+	}
+
+	user.IsActive = false
+	if err := c.userService.Update(ctx, user); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Deactivated %s\n", user.Username)
+	return nil
+}
+
+func runExportUsers(ctx context.Context, c *CLI, args []string) error {
+	flags := flag.NewFlagSet("export-users", flag.ContinueOnError)
+	format := flags.String("format", "csv", "csv or json")
+	output := flags.String("output", "", "file to write; stdout when omitted")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *format != "csv" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	users, err := c.userService.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := c.stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(users)
+	}
+
+	return writeUsersCSV(out, users)
+}
+
+func writeUsersCSV(out io.Writer, users []*model.User) error {
+	w := csv.NewWriter(out)
+
+	header := []string{
+		"id", "username", "email", "first_name", "last_name", "role_id",
+		"department", "manager_id", "is_active", "created_at",
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		var department, managerID string
+		if user.Department != nil {
+			department = *user.Department
+		}
+		if user.ManagerID != nil {
+			managerID = user.ManagerID.String()
+		}
+
+		record := []string{
+			user.ID.String(), user.Username, user.Email, user.FirstName, user.LastName,
+			user.RoleID.String(), department, managerID, strconv.FormatBool(user.IsActive),
+			user.CreatedAt.Format(time.RFC3339),
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// readPassword fills an empty password from the first line of stdin and
+// checks its length.
+func (c *CLI) readPassword(password *string) error {
+	if *password == "" {
+		fmt.Fprint(os.Stderr, "Password: ")
+		line, err := bufio.NewReader(c.stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		*password = strings.TrimRight(line, "\r\n")
+	}
+
+	if len(*password) < 8 {
+		return errors.New("password must be at least 8 characters")
+	}
+	return nil
+}
+
+// setEnvValue replaces key in an env file, appending it when absent.
+func setEnvValue(path, key, value string) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if len(content) == 0 {
+		lines = nil
+	}
+
+	found := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), key+"=") {
+			lines[i] = key + "=" + value
+			found = true
+		}
+	}
+	if !found {
+		lines = append(lines, key+"="+value)
+	}
+
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600)
+}
@@ -0,0 +1,170 @@
+// Package cli implements the operator subcommands that the binary runs in
+// place of the HTTP server, e.g. `customize_crm create-admin -username root`.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"customize_crm/config"
+	"customize_crm/migration"
+	"customize_crm/repository/postgres"
+	"customize_crm/service"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type runFunc func(ctx context.Context, c *CLI, args []string) error
+
+var commands = map[string]runFunc{
+	"migrate":         runMigrate,
+	"create-admin":    runCreateAdmin,
+	"reset-password":  runResetPassword,
+	"seed-roles":      runSeedRoles,
+	"rotate-jwt-key":  runRotateJWTKey,
+	"deactivate-user": runDeactivateUser,
+	"export-users":    runExportUsers,
+	"seed-demo":       runSeedDemo,
+}
+
+var usages = map[string]string{
+	"migrate":         "migrate up|down [steps]|status",
+	"create-admin":    "create-admin -username U -email E -first-name F -last-name L [-password P]",
+	"reset-password":  "reset-password -username U [-password P]",
+	"seed-roles":      "seed-roles",
+	"rotate-jwt-key":  "rotate-jwt-key [-env-file .env]",
+	"deactivate-user": "deactivate-user -username U",
+	"export-users":    "export-users [-format csv|json] [-output FILE]",
+	"seed-demo":       "seed-demo [-seed N] [-customers N] [-reps N] [-as-of YYYY-MM-DD] [-password P]",
+}
+
+// offline lists the commands that need neither the configuration nor the
+// database. rotate-jwt-key is how an operator fixes a missing or leaked
+// JWT_SECRET, so it has to work while the configuration is invalid.
+var offline = map[string]bool{
+	"rotate-jwt-key": true,
+}
+
+type CLI struct {
+	cfg         *config.Config
+	db          *pgxpool.Pool
+	userService *service.UserService
+	stdin       io.Reader
+	stdout      io.Writer
+}
+
+// IsOffline reports whether the named command runs without configuration or
+// database; Run may then be called with a nil cfg and db.
+func IsOffline(name string) bool {
+	return offline[name]
+}
+
+// Run executes the subcommand named by args[0].
+func Run(ctx context.Context, cfg *config.Config, db *pgxpool.Pool, args []string) error {
+	run, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n\n%s", args[0], Usage())
+	}
+
+	c := &CLI{
+		cfg:    cfg,
+		db:     db,
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+	}
+	if !offline[args[0]] {
+		c.userService = service.NewUserService(postgres.NewUserRepository(db), postgres.NewRoleRepository(db))
+	}
+
+	return run(ctx, c, args[1:])
+}
+
+// Usage lists every subcommand.
+func Usage() string {
+	names := make([]string, 0, len(usages))
+	for name := range usages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	usage := "Commands:\n"
+	for _, name := range names {
+		usage += "  " + usages[name] + "\n"
+	}
+	return usage
+}
+
+// MigrateUp applies every pending migration.
+func MigrateUp(ctx context.Context, db *pgxpool.Pool) error {
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(ctx)
+	for _, m := range applied {
+		slog.Info("applied migration", "version", m.Version, "name", m.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(applied) == 0 {
+		slog.Info("database schema is up to date", "version", migrator.Latest())
+	}
+	return nil
+}
+
+func runMigrate(ctx context.Context, c *CLI, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: %s", usages["migrate"])
+	}
+
+	migrator, err := migration.NewMigrator(c.db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		return MigrateUp(ctx, c.db)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+		}
+
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			slog.Info("reverted migration", "version", m.Version, "name", m.Name)
+		}
+		return err
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = "applied " + status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(c.stdout, "%04d_%-40s %s\n", status.Version, status.Name, applied)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+}
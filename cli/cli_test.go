@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotateJWTKeyRunsOffline(t *testing.T) {
+	if !IsOffline("rotate-jwt-key") {
+		t.Fatal("rotate-jwt-key needs the database")
+	}
+
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("DB_NAME=crm\nJWT_SECRET=old\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// No configuration or database, as when JWT_SECRET is missing.
+	if err := Run(context.Background(), nil, nil, []string{"rotate-jwt-key", "-env-file", envFile}); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 || lines[0] != "DB_NAME=crm" {
+		t.Fatalf("env file = %q", content)
+	}
+	secret, ok := strings.CutPrefix(lines[1], "JWT_SECRET=")
+	if !ok || secret == "old" || len(secret) < 32 {
+		t.Errorf("JWT_SECRET line = %q, want a new secret", lines[1])
+	}
+}
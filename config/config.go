@@ -0,0 +1,419 @@
+// Package config loads the application configuration from defaults, an
+// optional YAML file, a .env file and the environment, in increasing order of
+// precedence.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	Server      ServerConfig      `yaml:"server"`
+	Database    DatabaseConfig    `yaml:"database"`
+	JWT         JWTConfig         `yaml:"jwt"`
+	WebAuthn    WebAuthnConfig    `yaml:"webauthn"`
+	SMTP        SMTPConfig        `yaml:"smtp"`
+	Invitation  InvitationConfig  `yaml:"invitation"`
+	Log         LogConfig         `yaml:"log"`
+	Metrics     MetricsConfig     `yaml:"metrics"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+	RateLimit   RateLimitConfig   `yaml:"rate_limit"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+}
+
+// ServerConfig.ShutdownDrainSeconds is how long /readyz reports not ready
+// before the server stops accepting connections on shutdown. TrustedProxies
+// lists the addresses or CIDR ranges of reverse proxies whose X-Forwarded-For
+// and X-Real-IP headers are believed; clients connecting from anywhere else
+// are identified by their socket address.
+type ServerConfig struct {
+	Port                 string   `yaml:"port" env:"SERVER_PORT"`
+	ShutdownDrainSeconds int      `yaml:"shutdown_drain_seconds" env:"SERVER_SHUTDOWN_DRAIN_SECONDS"`
+	TrustedProxies       []string `yaml:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES"`
+}
+
+type DatabaseConfig struct {
+	Host           string `yaml:"host" env:"DB_HOST"`
+	Port           string `yaml:"port" env:"DB_PORT"`
+	User           string `yaml:"user" env:"DB_USER"`
+	Password       string `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name           string `yaml:"name" env:"DB_NAME"`
+	SSLMode        string `yaml:"ssl_mode" env:"DB_SSL_MODE"`
+	MaxConnections int    `yaml:"max_connections" env:"DB_MAX_CONNECTIONS"`
+	AutoMigrate    bool   `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+}
+
+type JWTConfig struct {
+	Secret                          string `yaml:"secret" env:"JWT_SECRET" secret:"true"`
+	AccessTokenExpiryMinutes        int    `yaml:"access_token_expiry_minutes" env:"JWT_ACCESS_TOKEN_EXPIRY_MINUTES"`
+	RefreshTokenExpiryDays          int    `yaml:"refresh_token_expiry_days" env:"JWT_REFRESH_TOKEN_EXPIRY_DAYS"`
+	ImpersonationTokenExpiryMinutes int    `yaml:"impersonation_token_expiry_minutes" env:"JWT_IMPERSONATION_TOKEN_EXPIRY_MINUTES"`
+}
+
+type WebAuthnConfig struct {
+	RPID          string   `yaml:"rp_id" env:"WEBAUTHN_RP_ID"`
+	RPDisplayName string   `yaml:"rp_display_name" env:"WEBAUTHN_RP_DISPLAY_NAME"`
+	RPOrigins     []string `yaml:"rp_origins" env:"WEBAUTHN_RP_ORIGINS"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host" env:"SMTP_HOST"`
+	Port     string `yaml:"port" env:"SMTP_PORT"`
+	Username string `yaml:"username" env:"SMTP_USERNAME"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD" secret:"true"`
+	From     string `yaml:"from" env:"SMTP_FROM"`
+}
+
+type InvitationConfig struct {
+	ExpiryHours int    `yaml:"expiry_hours" env:"INVITATION_EXPIRY_HOURS"`
+	AcceptURL   string `yaml:"accept_url" env:"INVITATION_ACCEPT_URL"`
+}
+
+type LogConfig struct {
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+}
+
+// MetricsConfig controls the Prometheus endpoint. With Port set, /metrics is
+// served without authentication on that port only; otherwise it is served on
+// the API port and requires Token as a bearer token. With neither, it is off.
+type MetricsConfig struct {
+	Port  string `yaml:"port" env:"METRICS_PORT"`
+	Token string `yaml:"token" env:"METRICS_TOKEN" secret:"true"`
+}
+
+// TracingConfig selects the span exporter: none, otlp (configured through the
+// standard OTEL_EXPORTER_OTLP_* variables), stdout, or file.
+type TracingConfig struct {
+	Exporter    string `yaml:"exporter" env:"TRACING_EXPORTER"`
+	File        string `yaml:"file" env:"TRACING_FILE"`
+	ServiceName string `yaml:"service_name" env:"TRACING_SERVICE_NAME"`
+}
+
+// RateLimitConfig sets how many API requests each client may make per window.
+// Signed-in users are limited by user ID; other clients by API key, when they
+// send one, and always by IP. /api/v1/auth/* has its own stricter per-IP
+// limit. Store is memory (per instance) or postgres (shared by replicas).
+type RateLimitConfig struct {
+	Enabled        bool   `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	Store          string `yaml:"store" env:"RATE_LIMIT_STORE"`
+	WindowSeconds  int    `yaml:"window_seconds" env:"RATE_LIMIT_WINDOW_SECONDS"`
+	UserRequests   int    `yaml:"user_requests" env:"RATE_LIMIT_USER_REQUESTS"`
+	APIKeyRequests int    `yaml:"api_key_requests" env:"RATE_LIMIT_API_KEY_REQUESTS"`
+	IPRequests     int    `yaml:"ip_requests" env:"RATE_LIMIT_IP_REQUESTS"`
+	AuthRequests   int    `yaml:"auth_requests" env:"RATE_LIMIT_AUTH_REQUESTS"`
+}
+
+// IdempotencyConfig controls how long responses to requests carrying an
+// Idempotency-Key are kept for replay. Store is memory (per instance) or
+// postgres (shared by replicas).
+type IdempotencyConfig struct {
+	Store          string `yaml:"store" env:"IDEMPOTENCY_STORE"`
+	RetentionHours int    `yaml:"retention_hours" env:"IDEMPOTENCY_RETENTION_HOURS"`
+}
+
+func (c JWTConfig) AccessTokenExpiry() time.Duration {
+	return time.Duration(c.AccessTokenExpiryMinutes) * time.Minute
+}
+
+func (c JWTConfig) RefreshTokenExpiry() time.Duration {
+	return time.Duration(c.RefreshTokenExpiryDays) * 24 * time.Hour
+}
+
+func (c JWTConfig) ImpersonationTokenExpiry() time.Duration {
+	return time.Duration(c.ImpersonationTokenExpiryMinutes) * time.Minute
+}
+
+func (c ServerConfig) ShutdownDrain() time.Duration {
+	return time.Duration(c.ShutdownDrainSeconds) * time.Second
+}
+
+// TrustedProxyPrefixes parses TrustedProxies; a plain address is a range of
+// one.
+func (c ServerConfig) TrustedProxyPrefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(c.TrustedProxies))
+	for _, proxy := range c.TrustedProxies {
+		if !strings.Contains(proxy, "/") {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return nil, fmt.Errorf("SERVER_TRUSTED_PROXIES must list addresses or CIDR ranges, got %q", proxy)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("SERVER_TRUSTED_PROXIES must list addresses or CIDR ranges, got %q", proxy)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func (c RateLimitConfig) Window() time.Duration {
+	return time.Duration(c.WindowSeconds) * time.Second
+}
+
+func (c IdempotencyConfig) Retention() time.Duration {
+	return time.Duration(c.RetentionHours) * time.Hour
+}
+
+func (c InvitationConfig) Expiry() time.Duration {
+	return time.Duration(c.ExpiryHours) * time.Hour
+}
+
+// SlogLevel parses Level; Validate rejects values it cannot parse.
+func (c LogConfig) SlogLevel() slog.Level {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(c.Level))
+	return level
+}
+
+// ConnString returns the PostgreSQL connection URL.
+func (c DatabaseConfig) ConnString() string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
+		c.User, c.Password, c.Host, c.Port, c.Name, c.SSLMode)
+}
+
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:                 "8080",
+			ShutdownDrainSeconds: 5,
+		},
+		Database: DatabaseConfig{
+			Host:           "localhost",
+			Port:           "5432",
+			SSLMode:        "disable",
+			MaxConnections: 10,
+		},
+		JWT: JWTConfig{
+			AccessTokenExpiryMinutes:        15,
+			RefreshTokenExpiryDays:          7,
+			ImpersonationTokenExpiryMinutes: 15,
+		},
+		WebAuthn: WebAuthnConfig{
+			RPID:          "localhost",
+			RPDisplayName: "Customize CRM",
+			RPOrigins:     []string{"http://localhost:8080"},
+		},
+		SMTP: SMTPConfig{
+			Port: "587",
+			From: "no-reply@localhost",
+		},
+		Invitation: InvitationConfig{
+			ExpiryHours: 72,
+			AcceptURL:   "http://localhost:3000/accept-invitation",
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "customize-crm",
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:        true,
+			Store:          "memory",
+			WindowSeconds:  60,
+			UserRequests:   300,
+			APIKeyRequests: 600,
+			IPRequests:     120,
+			AuthRequests:   10,
+		},
+		Idempotency: IdempotencyConfig{
+			Store:          "postgres",
+			RetentionHours: 24,
+		},
+	}
+}
+
+// Load builds the configuration. A .env file in the working directory is
+// optional; the YAML file named by CONFIG_FILE is required if that variable
+// is set. The result is validated before it is returned.
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("load .env: %w", err)
+	}
+
+	cfg := Default()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+	}
+
+	envErr := applyEnv(reflect.ValueOf(cfg).Elem())
+	if err := errors.Join(envErr, cfg.Validate()); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// applyEnv overrides every field tagged `env` whose variable is set.
+func applyEnv(v reflect.Value) error {
+	var errs []error
+
+	for i := 0; i < v.NumField(); i++ {
+		field, spec := v.Field(i), v.Type().Field(i)
+
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		name := spec.Tag.Get("env")
+		raw, ok := os.LookupEnv(name)
+		if name == "" || !ok {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(raw)
+		case reflect.Int:
+			n, err := strconv.Atoi(strings.TrimSpace(raw))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be an integer, got %q", name, raw))
+				continue
+			}
+			field.SetInt(int64(n))
+		case reflect.Bool:
+			b, err := strconv.ParseBool(strings.TrimSpace(raw))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be true or false, got %q", name, raw))
+				continue
+			}
+			field.SetBool(b)
+		case reflect.Slice:
+			var values []string
+			for _, value := range strings.Split(raw, ",") {
+				if value = strings.TrimSpace(value); value != "" {
+					values = append(values, value)
+				}
+			}
+			field.Set(reflect.ValueOf(values))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.JWT.Secret == "" {
+		errs = append(errs, errors.New("JWT_SECRET must be set"))
+	}
+	if c.JWT.AccessTokenExpiryMinutes <= 0 || c.JWT.RefreshTokenExpiryDays <= 0 || c.JWT.ImpersonationTokenExpiryMinutes <= 0 {
+		errs = append(errs, errors.New("JWT token expiries must be positive"))
+	}
+	if c.Database.MaxConnections <= 0 {
+		errs = append(errs, fmt.Errorf("DB_MAX_CONNECTIONS must be positive, got %d", c.Database.MaxConnections))
+	}
+	if c.Database.Name == "" || c.Database.User == "" {
+		errs = append(errs, errors.New("DB_NAME and DB_USER must be set"))
+	}
+	if _, err := strconv.ParseUint(c.Server.Port, 10, 16); err != nil {
+		errs = append(errs, fmt.Errorf("SERVER_PORT must be a port number, got %q", c.Server.Port))
+	}
+	if c.Server.ShutdownDrainSeconds < 0 {
+		errs = append(errs, errors.New("SERVER_SHUTDOWN_DRAIN_SECONDS must not be negative"))
+	}
+	if _, err := c.Server.TrustedProxyPrefixes(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(c.WebAuthn.RPOrigins) == 0 {
+		errs = append(errs, errors.New("WEBAUTHN_RP_ORIGINS must list at least one origin"))
+	}
+	if c.Invitation.ExpiryHours <= 0 {
+		errs = append(errs, errors.New("INVITATION_EXPIRY_HOURS must be positive"))
+	}
+
+	if c.Metrics.Port != "" {
+		if _, err := strconv.ParseUint(c.Metrics.Port, 10, 16); err != nil {
+			errs = append(errs, fmt.Errorf("METRICS_PORT must be a port number, got %q", c.Metrics.Port))
+		} else if c.Metrics.Port == c.Server.Port {
+			errs = append(errs, errors.New("METRICS_PORT must differ from SERVER_PORT"))
+		}
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "otlp", "stdout":
+	case "file":
+		if c.Tracing.File == "" {
+			errs = append(errs, errors.New("TRACING_FILE must be set when TRACING_EXPORTER is file"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("TRACING_EXPORTER must be none, otlp, stdout or file, got %q", c.Tracing.Exporter))
+	}
+
+	if c.RateLimit.Enabled {
+		if c.RateLimit.Store != "memory" && c.RateLimit.Store != "postgres" {
+			errs = append(errs, fmt.Errorf("RATE_LIMIT_STORE must be memory or postgres, got %q", c.RateLimit.Store))
+		}
+		if c.RateLimit.WindowSeconds <= 0 || c.RateLimit.UserRequests <= 0 || c.RateLimit.APIKeyRequests <= 0 ||
+			c.RateLimit.IPRequests <= 0 || c.RateLimit.AuthRequests <= 0 {
+			errs = append(errs, errors.New("RATE_LIMIT window and request limits must be positive"))
+		}
+	}
+
+	if c.Idempotency.Store != "memory" && c.Idempotency.Store != "postgres" {
+		errs = append(errs, fmt.Errorf("IDEMPOTENCY_STORE must be memory or postgres, got %q", c.Idempotency.Store))
+	}
+	if c.Idempotency.RetentionHours <= 0 {
+		errs = append(errs, errors.New("IDEMPOTENCY_RETENTION_HOURS must be positive"))
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL must be debug, info, warn or error, got %q", c.Log.Level))
+	}
+
+	return errors.Join(errs...)
+}
+
+// String renders the configuration for logging with secrets masked.
+func (c Config) String() string {
+	var b strings.Builder
+	writeFields(&b, reflect.ValueOf(c), "")
+	return strings.TrimSuffix(b.String(), " ")
+}
+
+func writeFields(b *strings.Builder, v reflect.Value, prefix string) {
+	for i := 0; i < v.NumField(); i++ {
+		field, spec := v.Field(i), v.Type().Field(i)
+		name := prefix + spec.Tag.Get("yaml")
+
+		if field.Kind() == reflect.Struct {
+			writeFields(b, field, name+".")
+			continue
+		}
+
+		value := fmt.Sprint(field.Interface())
+		if spec.Tag.Get("secret") == "true" && value != "" {
+			value = "[REDACTED]"
+		}
+		fmt.Fprintf(b, "%s=%s ", name, value)
+	}
+}
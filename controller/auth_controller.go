@@ -1,21 +1,24 @@
 package controller
 
 import (
-	"encoding/json"
 	"net/http"
 
+	"customize_crm/metrics"
 	"customize_crm/model"
 	"customize_crm/service"
 	"customize_crm/utils"
+	"customize_crm/validation"
 )
 
 type AuthController struct {
 	authService *service.AuthService
+	metrics     *metrics.Metrics
 }
 
-func NewAuthController(authService *service.AuthService) *AuthController {
+func NewAuthController(authService *service.AuthService, metrics *metrics.Metrics) *AuthController {
 	return &AuthController{
 		authService: authService,
+		metrics:     metrics,
 	}
 }
 
@@ -27,24 +30,21 @@ func NewAuthController(authService *service.AuthService) *AuthController {
 // @Produce json
 // @Param request body model.LoginRequest true "Login credentials"
 // @Success 200 {object} model.LoginResponse
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 401 {object} utils.ErrorResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
 // @Router /api/v1/auth/login [post]
 func (c *AuthController) Login(w http.ResponseWriter, r *http.Request) {
 	var req model.LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
-		return
-	}
-
-	if req.Username == "" || req.Password == "" {
-		utils.RespondWithError(w, http.StatusBadRequest, "Username and password are required")
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
 		return
 	}
 
 	tokens, user, err := c.authService.Login(r.Context(), req.Username, req.Password)
+	c.metrics.ObserveLogin(metrics.LoginPassword, err)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid credentials")
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "Invalid credentials")
 		return
 	}
 
@@ -66,24 +66,21 @@ func (c *AuthController) Login(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param request body model.RefreshTokenRequest true "Refresh token"
 // @Success 200 {object} model.RefreshTokenResponse
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 401 {object} utils.ErrorResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
 // @Router /api/v1/auth/refresh-token [post]
 func (c *AuthController) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	var req model.RefreshTokenRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
-		return
-	}
-
-	if req.RefreshToken == "" {
-		utils.RespondWithError(w, http.StatusBadRequest, "Refresh token is required")
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
 		return
 	}
 
 	tokens, err := c.authService.RefreshToken(r.Context(), req.RefreshToken)
+	c.metrics.ObserveRefresh(err)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusUnauthorized, "Invalid refresh token")
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "Invalid refresh token")
 		return
 	}
 
@@ -119,7 +116,7 @@ func (c *AuthController) Logout(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param request body model.ForgotPasswordRequest true "User email"
 // @Success 200 {object} model.MessageResponse
-// @Failure 400 {object} utils.ErrorResponse
+// @Failure 400 {object} utils.Problem
 // @Router /api/v1/auth/forgot-password [post]
 func (c *AuthController) ForgotPassword(w http.ResponseWriter, r *http.Request) {
 	utils.RespondWithJSON(w, http.StatusOK, model.MessageResponse{
@@ -135,7 +132,7 @@ func (c *AuthController) ForgotPassword(w http.ResponseWriter, r *http.Request)
 // @Produce json
 // @Param request body model.ResetPasswordRequest true "Reset token and new password"
 // @Success 200 {object} model.MessageResponse
-// @Failure 400 {object} utils.ErrorResponse
+// @Failure 400 {object} utils.Problem
 // @Router /api/v1/auth/reset-password [post]
 func (c *AuthController) ResetPassword(w http.ResponseWriter, r *http.Request) {
 	utils.RespondWithJSON(w, http.StatusOK, model.MessageResponse{
@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"customize_crm/model"
+	"customize_crm/service"
+	"customize_crm/utils"
+	"customize_crm/validation"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type DelegationController struct {
+	delegationService *service.DelegationService
+}
+
+func NewDelegationController(delegationService *service.DelegationService) *DelegationController {
+	return &DelegationController{
+		delegationService: delegationService,
+	}
+}
+
+// GetDelegations godoc
+// @Summary List delegations
+// @Description List delegations the current user has granted or received
+// @Tags delegations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.Delegation
+// @Failure 401 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/users/me/delegations [get]
+func (c *DelegationController) GetDelegations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(uuid.UUID)
+	if !ok {
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	delegations, err := c.delegationService.ListForUser(r.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error fetching delegations")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, delegations)
+}
+
+// CreateDelegation godoc
+// @Summary Create delegation
+// @Description Let another user see and act on your records for a period, e.g. while out of office. Admins may delegate on behalf of any user via delegator_id.
+// @Tags delegations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.CreateDelegationRequest true "Delegation data"
+// @Param Idempotency-Key header string false "Client-chosen key; a retry with the same key and body replays the original response"
+// @Success 201 {object} model.Delegation
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 409 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/users/me/delegations [post]
+func (c *DelegationController) CreateDelegation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(uuid.UUID)
+	if !ok {
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	var req model.CreateDelegationRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	delegation := &model.Delegation{
+		DelegatorID: userID,
+		DelegateID:  req.DelegateID,
+		StartsAt:    time.Now(),
+		EndsAt:      req.EndsAt,
+		Reason:      req.Reason,
+		CreatedBy:   userID,
+	}
+
+	if req.DelegatorID != nil && *req.DelegatorID != userID {
+		if role, _ := r.Context().Value("role").(string); role != "Admin" {
+			utils.RespondWithError(w, r, http.StatusForbidden, "Only admins can delegate on behalf of another user")
+			return
+		}
+		delegation.DelegatorID = *req.DelegatorID
+	}
+
+	if req.StartsAt != nil {
+		delegation.StartsAt = *req.StartsAt
+	}
+
+	if err := c.delegationService.Create(r.Context(), delegation); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, delegation)
+}
+
+// GetDelegation godoc
+// @Summary Get delegation
+// @Description Get a delegation the current user gives or receives. Admins may get any delegation.
+// @Tags delegations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Delegation ID"
+// @Success 200 {object} model.Delegation
+// @Header 200 {string} ETag "Entity tag of the returned record"
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Router /api/v1/users/me/delegations/{id} [get]
+func (c *DelegationController) GetDelegation(w http.ResponseWriter, r *http.Request) {
+	delegation, ok := c.delegation(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("ETag", utils.ETag(delegation.UpdatedAt))
+	utils.RespondWithJSON(w, http.StatusOK, delegation)
+}
+
+// RevokeDelegation godoc
+// @Summary Revoke delegation
+// @Description End a delegation immediately. Either party or an admin may revoke it.
+// @Tags delegations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Delegation ID"
+// @Param If-Match header string true "ETag from a previous GET"
+// @Success 200 {object} model.MessageResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 412 {object} utils.Problem
+// @Failure 428 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/users/me/delegations/{id} [delete]
+func (c *DelegationController) RevokeDelegation(w http.ResponseWriter, r *http.Request) {
+	delegation, ok := c.delegation(w, r)
+	if !ok {
+		return
+	}
+
+	if err := utils.CheckIfMatch(r, utils.ETag(delegation.UpdatedAt)); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if err := c.delegationService.Revoke(r.Context(), delegation.ID); err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error revoking delegation")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, model.MessageResponse{
+		Message: "Delegation revoked successfully",
+	})
+}
+
+// delegation loads the delegation named in the path and writes an error
+// response unless the current user gives or receives it or is an admin.
+func (c *DelegationController) delegation(w http.ResponseWriter, r *http.Request) (*model.Delegation, bool) {
+	userID, ok := r.Context().Value("userID").(uuid.UUID)
+	if !ok {
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "User ID not found in context")
+		return nil, false
+	}
+
+	delegationID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid delegation ID format")
+		return nil, false
+	}
+
+	delegation, err := c.delegationService.GetByID(r.Context(), delegationID)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return nil, false
+	}
+
+	role, _ := r.Context().Value("role").(string)
+	if role != "Admin" && delegation.DelegatorID != userID && delegation.DelegateID != userID {
+		utils.RespondWithError(w, r, http.StatusNotFound, "Delegation not found")
+		return nil, false
+	}
+
+	return delegation, true
+}
@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"net/http"
+
+	"customize_crm/model"
+	"customize_crm/service"
+	"customize_crm/utils"
+)
+
+type HealthController struct {
+	healthService *service.HealthService
+}
+
+func NewHealthController(healthService *service.HealthService) *HealthController {
+	return &HealthController{healthService: healthService}
+}
+
+// Liveness godoc
+// @Summary Liveness probe
+// @Description Reports that the process is up. It does not touch the database.
+// @Tags health
+// @Produce json
+// @Success 200 {object} model.HealthResponse
+// @Router /healthz [get]
+func (c *HealthController) Liveness(w http.ResponseWriter, r *http.Request) {
+	utils.RespondWithJSON(w, http.StatusOK, model.HealthResponse{Status: "ok"})
+}
+
+// Readiness godoc
+// @Summary Readiness probe
+// @Description Checks database connectivity and the schema version. Returns 503 while any check fails or the server is shutting down.
+// @Tags health
+// @Produce json
+// @Success 200 {object} model.ReadinessResponse
+// @Failure 503 {object} model.ReadinessResponse
+// @Router /readyz [get]
+func (c *HealthController) Readiness(w http.ResponseWriter, r *http.Request) {
+	report, ready := c.healthService.Readiness(r.Context())
+	if !ready {
+		utils.RespondWithJSON(w, http.StatusServiceUnavailable, report)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"customize_crm/logging"
+	"customize_crm/model"
+	"customize_crm/service"
+	"customize_crm/utils"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+type ImpersonationController struct {
+	authService        *service.AuthService
+	activityLogService *service.ActivityLogService
+}
+
+func NewImpersonationController(authService *service.AuthService, activityLogService *service.ActivityLogService) *ImpersonationController {
+	return &ImpersonationController{
+		authService:        authService,
+		activityLogService: activityLogService,
+	}
+}
+
+// Impersonate godoc
+// @Summary Impersonate a user
+// @Description Issue a short-lived, non-refreshable access token acting as the given user. Every request made with it is audited. Requires Admin role and the users.impersonate permission.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID to impersonate"
+// @Success 200 {object} model.ImpersonationResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/users/{id}/impersonate [post]
+func (c *ImpersonationController) Impersonate(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := r.Context().Value("userID").(uuid.UUID)
+	if !ok {
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	if _, impersonating := r.Context().Value("actorID").(uuid.UUID); impersonating {
+		utils.RespondWithError(w, r, http.StatusForbidden, "Impersonation tokens cannot start another impersonation")
+		return
+	}
+
+	subjectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid user ID format")
+		return
+	}
+
+	tokens, subject, err := c.authService.Impersonate(r.Context(), actorID, subjectID)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"actor_id":   actorID,
+		"subject_id": subjectID,
+		"token_id":   tokens.AccessUUID,
+		"expires_at": time.Unix(tokens.AtExpires, 0).UTC(),
+		"request_id": chimiddleware.GetReqID(r.Context()),
+	})
+
+	entry := &model.ActivityLog{
+		UserID:       &subjectID,
+		ActorID:      &actorID,
+		ActivityType: model.ActivityImpersonationStarted,
+		EntityType:   "user",
+		EntityID:     subjectID,
+		Description:  "Impersonation of " + subject.Username + " started",
+		Metadata:     metadata,
+	}
+
+	// An impersonation that cannot be audited must not be granted.
+	if err := c.activityLogService.Create(r.Context(), entry); err != nil {
+		logging.FromContext(r.Context()).Error("recording impersonation failed", "error", err)
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error creating impersonation token")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, model.ImpersonationResponse{
+		AccessToken: tokens.AccessToken,
+		UserID:      subjectID.String(),
+		ActorID:     actorID.String(),
+		ExpiresIn:   tokens.AtExpires - time.Now().Unix(),
+	})
+}
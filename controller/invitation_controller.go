@@ -0,0 +1,251 @@
+package controller
+
+import (
+	"net/http"
+
+	"customize_crm/logging"
+	"customize_crm/model"
+	"customize_crm/service"
+	"customize_crm/utils"
+	"customize_crm/validation"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type InvitationController struct {
+	invitationService *service.InvitationService
+}
+
+type CreateInvitationRequest struct {
+	Username   string    `json:"username" validate:"required,username"`
+	Email      string    `json:"email" validate:"required,email,max=255"`
+	FirstName  string    `json:"first_name" validate:"required,max=100"`
+	LastName   string    `json:"last_name" validate:"required,max=100"`
+	RoleID     uuid.UUID `json:"role_id" validate:"required"`
+	Department *string   `json:"department,omitempty" validate:"max=100"`
+}
+
+func NewInvitationController(invitationService *service.InvitationService) *InvitationController {
+	return &InvitationController{
+		invitationService: invitationService,
+	}
+}
+
+// InviteUser godoc
+// @Summary Invite a user
+// @Description Create an inactive user and email them a single-use invitation link (Admin only). If the email could not be sent the invitation is still created and returned with email_failed set; resend it once mail is working.
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateInvitationRequest true "Invitee data"
+// @Param Idempotency-Key header string false "Client-chosen key; a retry with the same key and body replays the original response"
+// @Success 201 {object} model.Invitation
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 409 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/users/invitations [post]
+func (c *InvitationController) InviteUser(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value("userID").(uuid.UUID)
+	if !ok {
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	var req CreateInvitationRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	user := &model.User{
+		Username:   req.Username,
+		Email:      req.Email,
+		FirstName:  req.FirstName,
+		LastName:   req.LastName,
+		RoleID:     req.RoleID,
+		Department: req.Department,
+	}
+
+	invitation, err := c.invitationService.Invite(r.Context(), user, adminID)
+	if err != nil {
+		if invitation == nil {
+			utils.RespondWithAppError(w, r, err)
+			return
+		}
+		// The invitation is committed, so report it as created: a 5xx would
+		// not be stored for replay and a retry would fail on the username.
+		logging.FromContext(r.Context()).Error("sending invitation email failed", "error", err)
+		invitation.EmailFailed = true
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, invitation)
+}
+
+// GetInvitations godoc
+// @Summary List invitations
+// @Description List all user invitations with their status (Admin only)
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.Invitation
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/users/invitations [get]
+func (c *InvitationController) GetInvitations(w http.ResponseWriter, r *http.Request) {
+	invitations, err := c.invitationService.GetAll(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error fetching invitations")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, invitations)
+}
+
+// GetInvitation godoc
+// @Summary Get invitation
+// @Description Get a user invitation with its status (Admin only)
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} model.Invitation
+// @Header 200 {string} ETag "Entity tag of the returned record"
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Router /api/v1/users/invitations/{id} [get]
+func (c *InvitationController) GetInvitation(w http.ResponseWriter, r *http.Request) {
+	invitationID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid invitation ID format")
+		return
+	}
+
+	invitation, err := c.invitationService.GetByID(r.Context(), invitationID)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", utils.ETag(invitation.UpdatedAt))
+	utils.RespondWithJSON(w, http.StatusOK, invitation)
+}
+
+// ResendInvitation godoc
+// @Summary Resend invitation
+// @Description Issue a new invitation link with a fresh expiry; the previous link stops working (Admin only)
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} model.Invitation
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 409 {object} utils.Problem
+// @Failure 502 {object} utils.Problem
+// @Router /api/v1/users/invitations/{id}/resend [post]
+func (c *InvitationController) ResendInvitation(w http.ResponseWriter, r *http.Request) {
+	invitationID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid invitation ID format")
+		return
+	}
+
+	invitation, err := c.invitationService.Resend(r.Context(), invitationID)
+	if err != nil {
+		if invitation != nil {
+			utils.RespondWithError(w, r, http.StatusBadGateway, "Invitation renewed but the email could not be sent")
+			return
+		}
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, invitation)
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke invitation
+// @Description Revoke a pending invitation so its link can no longer be used (Admin only)
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invitation ID"
+// @Param If-Match header string true "ETag from a previous GET"
+// @Success 200 {object} model.MessageResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 409 {object} utils.Problem
+// @Failure 412 {object} utils.Problem
+// @Failure 428 {object} utils.Problem
+// @Router /api/v1/users/invitations/{id} [delete]
+func (c *InvitationController) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	invitationID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid invitation ID format")
+		return
+	}
+
+	invitation, err := c.invitationService.GetByID(r.Context(), invitationID)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if err := utils.CheckIfMatch(r, utils.ETag(invitation.UpdatedAt)); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if err := c.invitationService.Revoke(r.Context(), invitationID); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, model.MessageResponse{
+		Message: "Invitation revoked successfully",
+	})
+}
+
+// AcceptInvitation godoc
+// @Summary Accept invitation
+// @Description Set a password using an invitation token and activate the account
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.AcceptInvitationRequest true "Invitation token and new password"
+// @Success 200 {object} model.MessageResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Router /api/v1/auth/accept-invitation [post]
+func (c *InvitationController) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	var req model.AcceptInvitationRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if _, err := c.invitationService.Accept(r.Context(), req.Token, req.Password); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, model.MessageResponse{
+		Message: "Account activated successfully",
+	})
+}
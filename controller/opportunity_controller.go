@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"net/http"
+
+	"customize_crm/repository"
+	"customize_crm/service"
+	"customize_crm/utils"
+)
+
+type OpportunityController struct {
+	opportunityService *service.OpportunityService
+	userService        *service.UserService
+}
+
+func NewOpportunityController(opportunityService *service.OpportunityService, userService *service.UserService) *OpportunityController {
+	return &OpportunityController{
+		opportunityService: opportunityService,
+		userService:        userService,
+	}
+}
+
+// GetOpportunities godoc
+// @Summary List opportunities
+// @Description List the opportunities owned by the current user and, for managers, by their direct and indirect reports
+// @Tags opportunities
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size (1-100, default 25)"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param assigned_to query string false "Only records assigned to this user"
+// @Param filter query string false "Filters written as filter[field]=value or filter[field][op]=value, op being eq, ne, lt, lte, gt, gte, in, contains or null"
+// @Param sort query string false "Sort field, prefix with - for descending (name, stage, status, created_at, updated_at)"
+// @Param fields query string false "Comma-separated fields to return"
+// @Param include query string false "Comma-separated relations to embed (customer)"
+// @Success 200 {object} model.OpportunityListResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/opportunities [get]
+func (c *OpportunityController) GetOpportunities(w http.ResponseWriter, r *http.Request) {
+	params, err := parsePageParams(r.URL.Query(), repository.OpportunityListSchema)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	scope, err := visibilityScope(r, c.userService)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error resolving record visibility")
+		return
+	}
+
+	page, err := c.opportunityService.List(r.Context(), scope, params)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	respondWithPage(w, r, page.Data, page.Pagination, params.Query)
+}
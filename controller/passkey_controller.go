@@ -0,0 +1,342 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"customize_crm/metrics"
+	"customize_crm/model"
+	"customize_crm/service"
+	"customize_crm/utils"
+	"customize_crm/validation"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type PasskeyController struct {
+	passkeyService *service.PasskeyService
+	authService    *service.AuthService
+	metrics        *metrics.Metrics
+}
+
+func NewPasskeyController(passkeyService *service.PasskeyService, authService *service.AuthService, metrics *metrics.Metrics) *PasskeyController {
+	return &PasskeyController{
+		passkeyService: passkeyService,
+		authService:    authService,
+		metrics:        metrics,
+	}
+}
+
+// BeginRegistration godoc
+// @Summary Begin passkey registration
+// @Description Create a WebAuthn registration challenge for the current user
+// @Tags passkeys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.PasskeyBeginResponse
+// @Failure 401 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/users/me/passkeys/register/begin [post]
+func (c *PasskeyController) BeginRegistration(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(uuid.UUID)
+	if !ok {
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	options, sessionID, err := c.passkeyService.BeginRegistration(r.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error starting passkey registration")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, model.PasskeyBeginResponse{
+		SessionID: sessionID,
+		Options:   options,
+	})
+}
+
+// FinishRegistration godoc
+// @Summary Finish passkey registration
+// @Description Verify the authenticator attestation and store the new passkey
+// @Tags passkeys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.PasskeyRegisterFinishRequest true "Attestation response"
+// @Success 201 {object} model.Passkey
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 409 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Router /api/v1/users/me/passkeys/register/finish [post]
+func (c *PasskeyController) FinishRegistration(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(uuid.UUID)
+	if !ok {
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	var req model.PasskeyRegisterFinishRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	passkey, err := c.passkeyService.FinishRegistration(r.Context(), userID, req.SessionID, req.Name, req.Credential)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, passkey)
+}
+
+// ListPasskeys godoc
+// @Summary List passkeys
+// @Description List the passkeys registered by the current user
+// @Tags passkeys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.Passkey
+// @Failure 401 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/users/me/passkeys [get]
+func (c *PasskeyController) ListPasskeys(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(uuid.UUID)
+	if !ok {
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	passkeys, err := c.passkeyService.ListByUser(r.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error fetching passkeys")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, passkeys)
+}
+
+// GetPasskey godoc
+// @Summary Get passkey
+// @Description Get one of the current user's passkeys
+// @Tags passkeys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Passkey ID"
+// @Success 200 {object} model.Passkey
+// @Header 200 {string} ETag "Entity tag of the returned record"
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Router /api/v1/users/me/passkeys/{id} [get]
+func (c *PasskeyController) GetPasskey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(uuid.UUID)
+	if !ok {
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	passkeyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid passkey ID format")
+		return
+	}
+
+	passkey, err := c.passkeyService.GetByID(r.Context(), userID, passkeyID)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", utils.ETag(passkey.UpdatedAt))
+	utils.RespondWithJSON(w, http.StatusOK, passkey)
+}
+
+// RenamePasskey godoc
+// @Summary Rename passkey
+// @Description Change the display name of one of the current user's passkeys
+// @Tags passkeys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Passkey ID"
+// @Param request body model.RenamePasskeyRequest true "New name"
+// @Param If-Match header string true "ETag from a previous GET"
+// @Success 200 {object} model.MessageResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 412 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Failure 428 {object} utils.Problem
+// @Router /api/v1/users/me/passkeys/{id} [patch]
+func (c *PasskeyController) RenamePasskey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(uuid.UUID)
+	if !ok {
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	passkeyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid passkey ID format")
+		return
+	}
+
+	var req model.RenamePasskeyRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	passkey, err := c.passkeyService.GetByID(r.Context(), userID, passkeyID)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if err := utils.CheckIfMatch(r, utils.ETag(passkey.UpdatedAt)); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if err := c.passkeyService.Rename(r.Context(), userID, passkeyID, req.Name); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, model.MessageResponse{
+		Message: "Passkey renamed successfully",
+	})
+}
+
+// DeletePasskey godoc
+// @Summary Delete passkey
+// @Description Remove one of the current user's passkeys
+// @Tags passkeys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Passkey ID"
+// @Param If-Match header string true "ETag from a previous GET"
+// @Success 200 {object} model.MessageResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 412 {object} utils.Problem
+// @Failure 428 {object} utils.Problem
+// @Router /api/v1/users/me/passkeys/{id} [delete]
+func (c *PasskeyController) DeletePasskey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(uuid.UUID)
+	if !ok {
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	passkeyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid passkey ID format")
+		return
+	}
+
+	passkey, err := c.passkeyService.GetByID(r.Context(), userID, passkeyID)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if err := utils.CheckIfMatch(r, utils.ETag(passkey.UpdatedAt)); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if err := c.passkeyService.Delete(r.Context(), userID, passkeyID); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, model.MessageResponse{
+		Message: "Passkey deleted successfully",
+	})
+}
+
+// BeginLogin godoc
+// @Summary Begin passkey login
+// @Description Create a WebAuthn assertion challenge. Omit the username for a discoverable login.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.PasskeyLoginBeginRequest false "Optional username"
+// @Success 200 {object} model.PasskeyBeginResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Router /api/v1/auth/passkey/begin [post]
+func (c *PasskeyController) BeginLogin(w http.ResponseWriter, r *http.Request) {
+	var req model.PasskeyLoginBeginRequest
+	if r.ContentLength != 0 {
+		if err := validation.DecodeJSON(r, &req); err != nil {
+			utils.RespondWithAppError(w, r, err)
+			return
+		}
+	}
+
+	options, sessionID, err := c.passkeyService.BeginLogin(r.Context(), req.Username)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "Invalid credentials")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, model.PasskeyBeginResponse{
+		SessionID: sessionID,
+		Options:   options,
+	})
+}
+
+// FinishLogin godoc
+// @Summary Finish passkey login
+// @Description Verify a WebAuthn assertion and return access token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.PasskeyLoginFinishRequest true "Assertion response"
+// @Success 200 {object} model.LoginResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Router /api/v1/auth/passkey/finish [post]
+func (c *PasskeyController) FinishLogin(w http.ResponseWriter, r *http.Request) {
+	var req model.PasskeyLoginFinishRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	user, err := c.passkeyService.FinishLogin(r.Context(), req.SessionID, req.Credential)
+	c.metrics.ObserveLogin(metrics.LoginPasskey, err)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "Invalid credentials")
+		return
+	}
+
+	tokens, err := c.authService.CreateTokens(user.ID.String())
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error creating tokens")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, model.LoginResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		UserID:       user.ID.String(),
+		Username:     user.Username,
+		Email:        user.Email,
+		ExpiresIn:    tokens.AtExpires - time.Now().Unix(),
+	})
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"net/http"
+
+	"customize_crm/repository"
+	"customize_crm/service"
+	"customize_crm/utils"
+)
+
+type ProductController struct {
+	productService *service.ProductService
+}
+
+func NewProductController(productService *service.ProductService) *ProductController {
+	return &ProductController{
+		productService: productService,
+	}
+}
+
+// GetProducts godoc
+// @Summary List products
+// @Description List the product catalog
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size (1-100, default 25)"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param filter query string false "Filters written as filter[field]=value or filter[field][op]=value, op being eq, ne, lt, lte, gt, gte, in, contains or null"
+// @Param sort query string false "Sort field, prefix with - for descending (name, unit_price, created_at, updated_at)"
+// @Param fields query string false "Comma-separated fields to return"
+// @Success 200 {object} model.ProductListResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/products [get]
+func (c *ProductController) GetProducts(w http.ResponseWriter, r *http.Request) {
+	params, err := parsePageParams(r.URL.Query(), repository.ProductListSchema)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	page, err := c.productService.List(r.Context(), params)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	respondWithPage(w, r, page.Data, page.Pagination, params.Query)
+}
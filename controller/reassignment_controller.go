@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"net/http"
+
+	"customize_crm/model"
+	"customize_crm/service"
+	"customize_crm/utils"
+	"customize_crm/validation"
+
+	"github.com/google/uuid"
+)
+
+type ReassignmentController struct {
+	reassignmentService *service.ReassignmentService
+}
+
+func NewReassignmentController(reassignmentService *service.ReassignmentService) *ReassignmentController {
+	return &ReassignmentController{
+		reassignmentService: reassignmentService,
+	}
+}
+
+// ReassignRecords godoc
+// @Summary Bulk reassign records
+// @Description Move every customer, opportunity and task matching the filter from one user to another in a single transaction. Set dry_run to preview the affected records without changing anything (Admin only)
+// @Tags reassignments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.ReassignmentRequest true "Filter and target user"
+// @Param Idempotency-Key header string false "Client-chosen key; a retry with the same key and body replays the original response"
+// @Success 200 {object} model.Reassignment "Dry run"
+// @Success 201 {object} model.Reassignment
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 409 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/reassignments [post]
+func (c *ReassignmentController) ReassignRecords(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value("userID").(uuid.UUID)
+	if !ok {
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	var req model.ReassignmentRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	reassignment, err := c.reassignmentService.Reassign(r.Context(), req, adminID)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if req.DryRun {
+		status = http.StatusOK
+	}
+
+	utils.RespondWithJSON(w, status, reassignment)
+}
+
+// GetReassignments godoc
+// @Summary List reassignments
+// @Description List the audit trail of bulk reassignments, newest first (Admin only)
+// @Tags reassignments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.Reassignment
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/reassignments [get]
+func (c *ReassignmentController) GetReassignments(w http.ResponseWriter, r *http.Request) {
+	reassignments, err := c.reassignmentService.GetAll(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error fetching reassignments")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, reassignments)
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"net/http"
+
+	"customize_crm/service"
+	"customize_crm/utils"
+)
+
+type ReportController struct {
+	reportService *service.ReportService
+	userService   *service.UserService
+}
+
+func NewReportController(reportService *service.ReportService, userService *service.UserService) *ReportController {
+	return &ReportController{
+		reportService: reportService,
+		userService:   userService,
+	}
+}
+
+// GetPipelineReport godoc
+// @Summary Pipeline report
+// @Description Open opportunities by stage for the current user and their direct and indirect reports
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.PipelineStageSummary
+// @Failure 401 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/reports/pipeline [get]
+func (c *ReportController) GetPipelineReport(w http.ResponseWriter, r *http.Request) {
+	scope, err := visibilityScope(r, c.userService)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error resolving record visibility")
+		return
+	}
+
+	report, err := c.reportService.Pipeline(r.Context(), scope)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error building pipeline report")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, report)
+}
+
+// GetTaskReport godoc
+// @Summary Task report
+// @Description Tasks by status, with overdue counts, for the current user and their direct and indirect reports
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.TaskStatusSummary
+// @Failure 401 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/reports/tasks [get]
+func (c *ReportController) GetTaskReport(w http.ResponseWriter, r *http.Request) {
+	scope, err := visibilityScope(r, c.userService)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error resolving record visibility")
+		return
+	}
+
+	report, err := c.reportService.Tasks(r.Context(), scope)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error building task report")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"customize_crm/apperror"
+	"customize_crm/listquery"
+	"customize_crm/model"
+	"customize_crm/repository"
+	"customize_crm/service"
+	"customize_crm/utils"
+
+	"github.com/google/uuid"
+)
+
+// visibilityScope resolves whose records the authenticated user may see.
+func visibilityScope(r *http.Request, userService *service.UserService) (*model.VisibilityScope, error) {
+	userID, ok := r.Context().Value("userID").(uuid.UUID)
+	if !ok {
+		return nil, errors.New("user ID not found in context")
+	}
+
+	role, _ := r.Context().Value("role").(string)
+	return userService.VisibilityScope(r.Context(), userID, role)
+}
+
+// parsePageParams reads the paging parameters and the list query, checked
+// against schema.
+func parsePageParams(query url.Values, schema *listquery.Schema) (repository.PageParams, error) {
+	params := repository.PageParams{
+		Limit:  25,
+		Cursor: query.Get("cursor"),
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 100 {
+			return params, badQuery("limit must be between 1 and 100")
+		}
+		params.Limit = limit
+	}
+
+	if v := query.Get("assigned_to"); v != "" {
+		assignedTo, err := uuid.Parse(v)
+		if err != nil {
+			return params, badQuery("assigned_to must be a user ID")
+		}
+		params.AssignedTo = &assignedTo
+	}
+
+	q, err := listquery.Parse(query, schema)
+	if err != nil {
+		return params, err
+	}
+	params.Query = q
+
+	return params, nil
+}
+
+func badQuery(message string) error {
+	return apperror.New(http.StatusBadRequest, apperror.CodeForStatus(http.StatusBadRequest), message)
+}
+
+type listPage struct {
+	Data       any              `json:"data"`
+	Pagination model.Pagination `json:"pagination"`
+}
+
+// respondWithPage writes one page of a list, trimmed to the fields the list
+// query selects.
+func respondWithPage[T any](w http.ResponseWriter, r *http.Request, data []T, pagination model.Pagination, q listquery.Query) {
+	projected, err := listquery.Project(data, q)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, listPage{Data: projected, Pagination: pagination})
+}
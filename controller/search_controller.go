@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"customize_crm/model"
+	"customize_crm/service"
+	"customize_crm/utils"
+)
+
+const (
+	minSearchQueryLen = 2
+	maxSearchQueryLen = 200
+)
+
+type SearchController struct {
+	searchService *service.SearchService
+	userService   *service.UserService
+}
+
+func NewSearchController(searchService *service.SearchService, userService *service.UserService) *SearchController {
+	return &SearchController{
+		searchService: searchService,
+		userService:   userService,
+	}
+}
+
+// Search godoc
+// @Summary Search
+// @Description Search customers, contacts, opportunities, products and tasks visible to the current user. Misspelled and partial words still match; results are ranked best first, and matching terms are wrapped in <mark> tags in the otherwise HTML-escaped highlight
+// @Tags search
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search text (2-200 characters)"
+// @Param types query string false "Comma-separated kinds of record to search (customer, contact, opportunity, product, task)"
+// @Param limit query int false "Maximum number of results (1-50, default 20)"
+// @Success 200 {object} model.SearchResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/search [get]
+func (c *SearchController) Search(w http.ResponseWriter, r *http.Request) {
+	params, err := parseSearchParams(r)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	scope, err := visibilityScope(r, c.userService)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error resolving record visibility")
+		return
+	}
+
+	results, err := c.searchService.Search(r.Context(), scope, params)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error searching records")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, results)
+}
+
+func parseSearchParams(r *http.Request) (model.SearchParams, error) {
+	query := r.URL.Query()
+	params := model.SearchParams{
+		Query: strings.TrimSpace(query.Get("q")),
+		Limit: 20,
+	}
+
+	if n := utf8.RuneCountInString(params.Query); n < minSearchQueryLen || n > maxSearchQueryLen {
+		return params, badQuery("q must be between " + strconv.Itoa(minSearchQueryLen) + " and " + strconv.Itoa(maxSearchQueryLen) + " characters")
+	}
+
+	if v := query.Get("types"); v != "" {
+		for _, kind := range strings.Split(v, ",") {
+			kind = strings.TrimSpace(kind)
+			if !isSearchType(kind) {
+				return params, badQuery("types must be a comma-separated list of " + strings.Join(model.SearchTypes, ", "))
+			}
+			params.Types = append(params.Types, kind)
+		}
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 50 {
+			return params, badQuery("limit must be between 1 and 50")
+		}
+		params.Limit = limit
+	}
+
+	return params, nil
+}
+
+func isSearchType(kind string) bool {
+	for _, t := range model.SearchTypes {
+		if t == kind {
+			return true
+		}
+	}
+	return false
+}
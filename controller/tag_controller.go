@@ -0,0 +1,335 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"customize_crm/model"
+	"customize_crm/service"
+	"customize_crm/utils"
+	"customize_crm/validation"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const defaultTagColor = "#9E9E9E"
+
+type TagController struct {
+	tagService  *service.TagService
+	userService *service.UserService
+}
+
+type TagRequest struct {
+	Name  string `json:"name" validate:"required,max=100"`
+	Color string `json:"color,omitempty" validate:"hexcolor"`
+}
+
+// UpdateTagRequest only changes the fields present in the body.
+type UpdateTagRequest struct {
+	Name  model.Optional[string] `json:"name" swaggertype:"string" validate:"nonempty,max=100"`
+	Color model.Optional[string] `json:"color" swaggertype:"string" validate:"nonempty,hexcolor"`
+}
+
+type MergeTagRequest struct {
+	IntoID uuid.UUID `json:"into_id" validate:"required"`
+}
+
+func NewTagController(tagService *service.TagService, userService *service.UserService) *TagController {
+	return &TagController{
+		tagService:  tagService,
+		userService: userService,
+	}
+}
+
+// GetTags godoc
+// @Summary List tags
+// @Description List the customer tag vocabulary with the number of customers using each tag
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.Tag
+// @Failure 401 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/tags [get]
+func (c *TagController) GetTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := c.tagService.GetAll(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error fetching tags")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, tags)
+}
+
+// CreateTag godoc
+// @Summary Create tag
+// @Description Add a tag to the customer tag vocabulary (Admin only)
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body TagRequest true "Tag name and #RRGGBB color"
+// @Param Idempotency-Key header string false "Client-chosen key; a retry with the same key and body replays the original response"
+// @Success 201 {object} model.Tag
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 409 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/tags [post]
+func (c *TagController) CreateTag(w http.ResponseWriter, r *http.Request) {
+	var req TagRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	tag := &model.Tag{
+		Name:  strings.TrimSpace(req.Name),
+		Color: strings.ToUpper(req.Color),
+	}
+	if tag.Color == "" {
+		tag.Color = defaultTagColor
+	}
+
+	if err := c.tagService.Create(r.Context(), tag); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, tag)
+}
+
+// GetTagByID godoc
+// @Summary Get tag
+// @Description Get a tag with the number of customers using it
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tag ID"
+// @Success 200 {object} model.Tag
+// @Header 200 {string} ETag "Entity tag of the returned record"
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Router /api/v1/tags/{id} [get]
+func (c *TagController) GetTagByID(w http.ResponseWriter, r *http.Request) {
+	tagID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid tag ID format")
+		return
+	}
+
+	tag, err := c.tagService.GetByID(r.Context(), tagID)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", utils.ETag(tag.UpdatedAt))
+	utils.RespondWithJSON(w, http.StatusOK, tag)
+}
+
+// UpdateTag godoc
+// @Summary Update tag
+// @Description Rename or recolor a tag; a new name replaces the old one on every customer. Omitted fields are left unchanged (Admin only)
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tag ID"
+// @Param request body UpdateTagRequest true "Tag fields to change"
+// @Param If-Match header string true "ETag from a previous GET"
+// @Success 200 {object} model.Tag
+// @Header 200 {string} ETag "Entity tag of the returned record"
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 409 {object} utils.Problem
+// @Failure 412 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Failure 428 {object} utils.Problem
+// @Router /api/v1/tags/{id} [patch]
+func (c *TagController) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	tagID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid tag ID format")
+		return
+	}
+
+	var req UpdateTagRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	tag, err := c.tagService.GetByID(r.Context(), tagID)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if err := utils.CheckIfMatch(r, utils.ETag(tag.UpdatedAt)); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	readAt := tag.UpdatedAt
+	req.Name.Apply(&tag.Name)
+	req.Color.Apply(&tag.Color)
+	tag.Name = strings.TrimSpace(tag.Name)
+	tag.Color = strings.ToUpper(tag.Color)
+
+	if err := c.tagService.Update(r.Context(), tag, readAt); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", utils.ETag(tag.UpdatedAt))
+	utils.RespondWithJSON(w, http.StatusOK, tag)
+}
+
+// DeleteTag godoc
+// @Summary Delete tag
+// @Description Delete a tag and remove it from every customer (Admin only)
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tag ID"
+// @Param If-Match header string true "ETag from a previous GET"
+// @Success 200 {object} model.MessageResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 412 {object} utils.Problem
+// @Failure 428 {object} utils.Problem
+// @Router /api/v1/tags/{id} [delete]
+func (c *TagController) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	tagID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid tag ID format")
+		return
+	}
+
+	tag, err := c.tagService.GetByID(r.Context(), tagID)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if err := utils.CheckIfMatch(r, utils.ETag(tag.UpdatedAt)); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if err := c.tagService.Delete(r.Context(), tagID, tag.UpdatedAt); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, model.MessageResponse{
+		Message: "Tag deleted successfully",
+	})
+}
+
+// MergeTag godoc
+// @Summary Merge tag
+// @Description Replace a tag with another on every customer and delete it (Admin only)
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID of the tag to merge away"
+// @Param request body MergeTagRequest true "ID of the tag to keep"
+// @Param If-Match header string true "ETag of the tag to merge away, from a previous GET"
+// @Success 200 {object} model.Tag
+// @Header 200 {string} ETag "Entity tag of the returned record"
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 412 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Failure 428 {object} utils.Problem
+// @Router /api/v1/tags/{id}/merge [post]
+func (c *TagController) MergeTag(w http.ResponseWriter, r *http.Request) {
+	tagID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid tag ID format")
+		return
+	}
+
+	var req MergeTagRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	source, err := c.tagService.GetByID(r.Context(), tagID)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if err := utils.CheckIfMatch(r, utils.ETag(source.UpdatedAt)); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	tag, err := c.tagService.Merge(r.Context(), tagID, req.IntoID, source.UpdatedAt)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", utils.ETag(tag.UpdatedAt))
+	utils.RespondWithJSON(w, http.StatusOK, tag)
+}
+
+// UpdateCustomerTags godoc
+// @Summary Bulk tag customers
+// @Description Add and remove tags on many customers at once. Added tags must exist in the vocabulary; removals win over additions. Customers the current user cannot see are skipped.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.CustomerTagsRequest true "Customer IDs and the tags to add and remove"
+// @Success 200 {object} model.CustomerTagsResult
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/customers/tags [post]
+func (c *TagController) UpdateCustomerTags(w http.ResponseWriter, r *http.Request) {
+	var req model.CustomerTagsRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if len(req.Add) == 0 && len(req.Remove) == 0 {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "No tags to add or remove")
+		return
+	}
+
+	scope, err := visibilityScope(r, c.userService)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error resolving record visibility")
+		return
+	}
+
+	result, err := c.tagService.UpdateCustomerTags(r.Context(), scope, &req)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"net/http"
+
+	"customize_crm/repository"
+	"customize_crm/service"
+	"customize_crm/utils"
+)
+
+type TaskController struct {
+	taskService *service.TaskService
+	userService *service.UserService
+}
+
+func NewTaskController(taskService *service.TaskService, userService *service.UserService) *TaskController {
+	return &TaskController{
+		taskService: taskService,
+		userService: userService,
+	}
+}
+
+// GetTasks godoc
+// @Summary List tasks
+// @Description List the tasks owned by the current user and, for managers, by their direct and indirect reports
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size (1-100, default 25)"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param assigned_to query string false "Only records assigned to this user"
+// @Param filter query string false "Filters written as filter[field]=value or filter[field][op]=value, op being eq, ne, lt, lte, gt, gte, in, contains or null"
+// @Param sort query string false "Sort field, prefix with - for descending (title, due_date, priority, status, created_at, updated_at)"
+// @Param fields query string false "Comma-separated fields to return"
+// @Param include query string false "Comma-separated relations to embed (customer, opportunity)"
+// @Success 200 {object} model.TaskListResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/tasks [get]
+func (c *TaskController) GetTasks(w http.ResponseWriter, r *http.Request) {
+	params, err := parsePageParams(r.URL.Query(), repository.TaskListSchema)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	scope, err := visibilityScope(r, c.userService)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error resolving record visibility")
+		return
+	}
+
+	page, err := c.taskService.List(r.Context(), scope, params)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	respondWithPage(w, r, page.Data, page.Pagination, params.Query)
+}
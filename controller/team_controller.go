@@ -0,0 +1,637 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+	"customize_crm/service"
+	"customize_crm/utils"
+	"customize_crm/validation"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type TeamController struct {
+	teamService *service.TeamService
+	userService *service.UserService
+}
+
+type TeamRequest struct {
+	Name        string  `json:"name" validate:"required,max=100"`
+	Description *string `json:"description,omitempty"`
+}
+
+// UpdateTeamRequest only changes the fields present in the body; a null
+// description clears it.
+type UpdateTeamRequest struct {
+	Name        model.Optional[string] `json:"name" swaggertype:"string" validate:"nonempty,max=100"`
+	Description model.Optional[string] `json:"description" swaggertype:"string" validate:"nullable"`
+}
+
+type SetTeamMemberRequest struct {
+	Role string `json:"role" validate:"oneof=lead member"`
+}
+
+func NewTeamController(teamService *service.TeamService, userService *service.UserService) *TeamController {
+	return &TeamController{
+		teamService: teamService,
+		userService: userService,
+	}
+}
+
+// GetTeams godoc
+// @Summary List teams
+// @Description List all sales teams
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.Team
+// @Failure 401 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/teams [get]
+func (c *TeamController) GetTeams(w http.ResponseWriter, r *http.Request) {
+	teams, err := c.teamService.GetAll(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error fetching teams")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, teams)
+}
+
+// CreateTeam godoc
+// @Summary Create team
+// @Description Create a sales team (Admin only)
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body TeamRequest true "Team data"
+// @Param Idempotency-Key header string false "Client-chosen key; a retry with the same key and body replays the original response"
+// @Success 201 {object} model.Team
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 409 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/teams [post]
+func (c *TeamController) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	var req TeamRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	team := &model.Team{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+
+	if err := c.teamService.Create(r.Context(), team); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, team)
+}
+
+// GetTeamByID godoc
+// @Summary Get team
+// @Description Get a team with its members
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Team ID"
+// @Success 200 {object} model.Team
+// @Header 200 {string} ETag "Entity tag of the returned record"
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Router /api/v1/teams/{id} [get]
+func (c *TeamController) GetTeamByID(w http.ResponseWriter, r *http.Request) {
+	teamID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid team ID format")
+		return
+	}
+
+	team, err := c.teamService.GetByID(r.Context(), teamID)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", utils.ETag(team.UpdatedAt))
+	utils.RespondWithJSON(w, http.StatusOK, team)
+}
+
+// UpdateTeam godoc
+// @Summary Update team
+// @Description Update a team's name and description; omitted fields are left unchanged (Admin only)
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Team ID"
+// @Param request body UpdateTeamRequest true "Team fields to change"
+// @Param If-Match header string true "ETag from a previous GET"
+// @Success 200 {object} model.Team
+// @Header 200 {string} ETag "Entity tag of the returned record"
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 412 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Failure 428 {object} utils.Problem
+// @Router /api/v1/teams/{id} [patch]
+func (c *TeamController) UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	teamID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid team ID format")
+		return
+	}
+
+	var req UpdateTeamRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	team, err := c.teamService.GetByID(r.Context(), teamID)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if err := utils.CheckIfMatch(r, utils.ETag(team.UpdatedAt)); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	req.Name.Apply(&team.Name)
+	req.Description.ApplyPtr(&team.Description)
+
+	if err := c.teamService.Update(r.Context(), team); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", utils.ETag(team.UpdatedAt))
+	utils.RespondWithJSON(w, http.StatusOK, team)
+}
+
+// DeleteTeam godoc
+// @Summary Delete team
+// @Description Delete a team; its customers and opportunities become unassigned from any team (Admin only)
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Team ID"
+// @Param If-Match header string true "ETag from a previous GET"
+// @Success 200 {object} model.MessageResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 412 {object} utils.Problem
+// @Failure 428 {object} utils.Problem
+// @Router /api/v1/teams/{id} [delete]
+func (c *TeamController) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	teamID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid team ID format")
+		return
+	}
+
+	team, err := c.teamService.GetByID(r.Context(), teamID)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if err := utils.CheckIfMatch(r, utils.ETag(team.UpdatedAt)); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if err := c.teamService.Delete(r.Context(), teamID, team.UpdatedAt); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, model.MessageResponse{
+		Message: "Team deleted successfully",
+	})
+}
+
+// SetTeamMember godoc
+// @Summary Add or update team member
+// @Description Add a user to a team or change their role. Team leads may only add users reporting to them, directly or indirectly, as members; adding anyone else or appointing leads needs an admin.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Team ID"
+// @Param userID path string true "User ID"
+// @Param request body SetTeamMemberRequest true "Membership role (lead or member)"
+// @Success 200 {array} model.TeamMember
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Router /api/v1/teams/{id}/members/{userID} [put]
+func (c *TeamController) SetTeamMember(w http.ResponseWriter, r *http.Request) {
+	teamID, userID, ok := parseTeamMemberPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req SetTeamMemberRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if req.Role == "" {
+		req.Role = model.TeamRoleMember
+	}
+
+	if !c.authorizeTeamLead(w, r, teamID) {
+		return
+	}
+
+	if role, _ := r.Context().Value("role").(string); role != "Admin" {
+		current, err := c.teamService.GetMemberRole(r.Context(), teamID, userID)
+		if err != nil && !errors.Is(err, service.ErrTeamMemberNotFound) {
+			utils.RespondWithError(w, r, http.StatusInternalServerError, "Error checking team membership")
+			return
+		}
+		if req.Role == model.TeamRoleLead || current == model.TeamRoleLead {
+			utils.RespondWithError(w, r, http.StatusForbidden, "Only admins can appoint or change team leads")
+			return
+		}
+		if current == "" && !c.leadMayAdd(w, r, userID) {
+			return
+		}
+	}
+
+	if err := c.teamService.SetMember(r.Context(), teamID, userID, req.Role); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	members, err := c.teamService.GetMembers(r.Context(), teamID)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error fetching team members")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, members)
+}
+
+// RemoveTeamMember godoc
+// @Summary Remove team member
+// @Description Remove a user from a team (Admin or team lead)
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Team ID"
+// @Param userID path string true "User ID"
+// @Param If-Match header string true "ETag of the team from a previous GET"
+// @Success 200 {object} model.MessageResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 412 {object} utils.Problem
+// @Failure 428 {object} utils.Problem
+// @Router /api/v1/teams/{id}/members/{userID} [delete]
+func (c *TeamController) RemoveTeamMember(w http.ResponseWriter, r *http.Request) {
+	teamID, userID, ok := parseTeamMemberPath(w, r)
+	if !ok {
+		return
+	}
+
+	if !c.authorizeTeamLead(w, r, teamID) {
+		return
+	}
+
+	if role, _ := r.Context().Value("role").(string); role != "Admin" {
+		memberRole, err := c.teamService.GetMemberRole(r.Context(), teamID, userID)
+		if err == nil && memberRole == model.TeamRoleLead {
+			utils.RespondWithError(w, r, http.StatusForbidden, "Only admins can remove team leads")
+			return
+		}
+	}
+
+	team, err := c.teamService.GetByID(r.Context(), teamID)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if err := utils.CheckIfMatch(r, utils.ETag(team.UpdatedAt)); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if err := c.teamService.RemoveMember(r.Context(), teamID, userID, team.UpdatedAt); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, model.MessageResponse{
+		Message: "Team member removed successfully",
+	})
+}
+
+// AssignTeamRecords godoc
+// @Summary Assign records to team
+// @Description Assign customers and opportunities to a team. Team leads can only assign records already held by the team's members.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Team ID"
+// @Param request body model.TeamRecordsRequest true "Customer and opportunity IDs"
+// @Success 200 {object} model.MessageResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Router /api/v1/teams/{id}/records [post]
+func (c *TeamController) AssignTeamRecords(w http.ResponseWriter, r *http.Request) {
+	c.updateTeamRecords(w, r, true)
+}
+
+// UnassignTeamRecords godoc
+// @Summary Unassign records from team
+// @Description Remove customers and opportunities from a team (Admin or team lead)
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Team ID"
+// @Param If-Match header string true "ETag of the team from a previous GET"
+// @Param request body model.TeamRecordsRequest true "Customer and opportunity IDs"
+// @Success 200 {object} model.MessageResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 412 {object} utils.Problem
+// @Failure 428 {object} utils.Problem
+// @Router /api/v1/teams/{id}/records [delete]
+func (c *TeamController) UnassignTeamRecords(w http.ResponseWriter, r *http.Request) {
+	c.updateTeamRecords(w, r, false)
+}
+
+func (c *TeamController) updateTeamRecords(w http.ResponseWriter, r *http.Request, assign bool) {
+	teamID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid team ID format")
+		return
+	}
+
+	var req model.TeamRecordsRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if len(req.CustomerIDs) == 0 && len(req.OpportunityIDs) == 0 {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "No customer or opportunity IDs provided")
+		return
+	}
+
+	if !c.authorizeTeamLead(w, r, teamID) {
+		return
+	}
+
+	// Unassigning is a DELETE, so like other deletes it needs a current ETag.
+	var updatedAt *time.Time
+	if !assign {
+		team, err := c.teamService.GetByID(r.Context(), teamID)
+		if err != nil {
+			utils.RespondWithAppError(w, r, err)
+			return
+		}
+
+		if err := utils.CheckIfMatch(r, utils.ETag(team.UpdatedAt)); err != nil {
+			utils.RespondWithAppError(w, r, err)
+			return
+		}
+		updatedAt = &team.UpdatedAt
+	}
+
+	role, _ := r.Context().Value("role").(string)
+
+	if err := c.teamService.AssignRecords(r.Context(), teamID, req, assign, role != "Admin", updatedAt); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, model.MessageResponse{
+		Message: "Team records updated successfully",
+	})
+}
+
+// GetTeamPipeline godoc
+// @Summary Team pipeline
+// @Description Open opportunities by stage for a team's records and members (Admin or team lead)
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Team ID"
+// @Success 200 {array} model.PipelineStageSummary
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/teams/{id}/pipeline [get]
+func (c *TeamController) GetTeamPipeline(w http.ResponseWriter, r *http.Request) {
+	teamID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid team ID format")
+		return
+	}
+
+	if !c.authorizeTeamLead(w, r, teamID) {
+		return
+	}
+
+	pipeline, err := c.teamService.Pipeline(r.Context(), teamID)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error building team pipeline")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, pipeline)
+}
+
+// GetTeamOpportunities godoc
+// @Summary Team opportunities
+// @Description List opportunities assigned to a team or its members (Admin or team lead)
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Team ID"
+// @Param limit query int false "Page size (1-100, default 25)"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param assigned_to query string false "Only opportunities assigned to this member"
+// @Param filter query string false "Filters written as filter[field]=value or filter[field][op]=value, op being eq, ne, lt, lte, gt, gte, in, contains or null"
+// @Param sort query string false "Sort field, prefix with - for descending (name, stage, status, created_at, updated_at)"
+// @Param fields query string false "Comma-separated fields to return"
+// @Success 200 {object} model.OpportunityListResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/teams/{id}/opportunities [get]
+func (c *TeamController) GetTeamOpportunities(w http.ResponseWriter, r *http.Request) {
+	teamID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid team ID format")
+		return
+	}
+
+	params, err := parsePageParams(r.URL.Query(), repository.OpportunityListSchema.WithoutIncludes())
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if !c.authorizeTeamLead(w, r, teamID) {
+		return
+	}
+
+	page, err := c.teamService.Opportunities(r.Context(), teamID, params)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	respondWithPage(w, r, page.Data, page.Pagination, params.Query)
+}
+
+// GetTeamTasks godoc
+// @Summary Team tasks
+// @Description List tasks assigned to team members or linked to the team's customers and opportunities (Admin or team lead)
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Team ID"
+// @Param limit query int false "Page size (1-100, default 25)"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param assigned_to query string false "Only tasks assigned to this member"
+// @Param filter query string false "Filters written as filter[field]=value or filter[field][op]=value, op being eq, ne, lt, lte, gt, gte, in, contains or null"
+// @Param sort query string false "Sort field, prefix with - for descending (title, due_date, priority, status, created_at, updated_at)"
+// @Param fields query string false "Comma-separated fields to return"
+// @Success 200 {object} model.TaskListResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/teams/{id}/tasks [get]
+func (c *TeamController) GetTeamTasks(w http.ResponseWriter, r *http.Request) {
+	teamID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid team ID format")
+		return
+	}
+
+	params, err := parsePageParams(r.URL.Query(), repository.TaskListSchema.WithoutIncludes())
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if !c.authorizeTeamLead(w, r, teamID) {
+		return
+	}
+
+	page, err := c.teamService.Tasks(r.Context(), teamID, params)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	respondWithPage(w, r, page.Data, page.Pagination, params.Query)
+}
+
+// authorizeTeamLead allows admins and leads of the team, and writes a 403
+// response otherwise.
+func (c *TeamController) authorizeTeamLead(w http.ResponseWriter, r *http.Request, teamID uuid.UUID) bool {
+	if role, _ := r.Context().Value("role").(string); role == "Admin" {
+		return true
+	}
+
+	userID, ok := r.Context().Value("userID").(uuid.UUID)
+	if !ok {
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "User ID not found in context")
+		return false
+	}
+
+	memberRole, err := c.teamService.GetMemberRole(r.Context(), teamID, userID)
+	if err != nil && !errors.Is(err, service.ErrTeamMemberNotFound) {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error checking team membership")
+		return false
+	}
+
+	if memberRole != model.TeamRoleLead {
+		utils.RespondWithError(w, r, http.StatusForbidden, "Team lead permission required")
+		return false
+	}
+
+	return true
+}
+
+// leadMayAdd keeps team leads from pulling in, and so seeing the tasks,
+// opportunities and pipeline of, users outside their visibility scope: a lead
+// may only add users in their own reporting line.
+func (c *TeamController) leadMayAdd(w http.ResponseWriter, r *http.Request, userID uuid.UUID) bool {
+	leadID, _ := r.Context().Value("userID").(uuid.UUID)
+	reports, err := c.userService.GetReportIDs(r.Context(), leadID)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error checking reporting line")
+		return false
+	}
+	for _, id := range reports {
+		if id == userID {
+			return true
+		}
+	}
+
+	utils.RespondWithError(w, r, http.StatusForbidden, "Team leads can only add users reporting to them; ask an admin to add anyone else")
+	return false
+}
+
+func parseTeamMemberPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	teamID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid team ID format")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid user ID format")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return teamID, userID, true
+}
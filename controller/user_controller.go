@@ -1,13 +1,18 @@
 package controller
 
 import (
-	"encoding/json"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
+	"customize_crm/listquery"
 	"customize_crm/model"
+	"customize_crm/repository"
 	"customize_crm/service"
 	"customize_crm/utils"
+	"customize_crm/validation"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -18,26 +23,42 @@ type UserController struct {
 }
 
 type CreateUserRequest struct {
-	Username   string    `json:"username"`
-	Email      string    `json:"email"`
-	Password   string    `json:"password"`
-	FirstName  string    `json:"first_name"`
-	LastName   string    `json:"last_name"`
-	RoleID     uuid.UUID `json:"role_id"`
-	Department *string   `json:"department,omitempty"`
+	Username   string    `json:"username" validate:"required,username"`
+	Email      string    `json:"email" validate:"required,email,max=255"`
+	Password   string    `json:"password" validate:"required,min=8,max=72"`
+	FirstName  string    `json:"first_name" validate:"required,max=100"`
+	LastName   string    `json:"last_name" validate:"required,max=100"`
+	RoleID     uuid.UUID `json:"role_id" validate:"required"`
+	Department *string   `json:"department,omitempty" validate:"max=100"`
 	IsActive   bool      `json:"is_active"`
 }
 
+// UpdateUserRequest only changes the fields present in the body; a null
+// department clears it.
 type UpdateUserRequest struct {
-	FirstName  string    `json:"first_name"`
-	LastName   string    `json:"last_name"`
-	Department *string   `json:"department,omitempty"`
-	RoleID     uuid.UUID `json:"role_id"`
-	IsActive   bool      `json:"is_active"`
+	FirstName  model.Optional[string]    `json:"first_name" swaggertype:"string" validate:"nonempty,max=100"`
+	LastName   model.Optional[string]    `json:"last_name" swaggertype:"string" validate:"nonempty,max=100"`
+	Department model.Optional[string]    `json:"department" swaggertype:"string" validate:"nullable,max=100"`
+	RoleID     model.Optional[uuid.UUID] `json:"role_id" swaggertype:"string" format:"uuid" validate:"nonempty"`
+	IsActive   model.Optional[bool]      `json:"is_active" swaggertype:"boolean"`
+}
+
+// UpdateCurrentUserRequest is the subset of profile fields users may change
+// on their own account, with the same PATCH semantics as UpdateUserRequest.
+type UpdateCurrentUserRequest struct {
+	FirstName  model.Optional[string] `json:"first_name" swaggertype:"string" validate:"nonempty,max=100"`
+	LastName   model.Optional[string] `json:"last_name" swaggertype:"string" validate:"nonempty,max=100"`
+	Department model.Optional[string] `json:"department" swaggertype:"string" validate:"nullable,max=100"`
+}
+
+type SetManagerRequest struct {
+	ManagerID *uuid.UUID `json:"manager_id"`
 }
 
 type DeleteUsersRequest struct {
-	IDs []uuid.UUID `json:"ids"`
+	IDs        []uuid.UUID `json:"ids" validate:"required,dive,required"`
+	ReassignTo *uuid.UUID  `json:"reassign_to,omitempty"`
+	Unassign   bool        `json:"unassign"`
 }
 
 func NewUserController(userService *service.UserService) *UserController {
@@ -54,90 +75,195 @@ func NewUserController(userService *service.UserService) *UserController {
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} model.User
-// @Failure 401 {object} utils.ErrorResponse
-// @Failure 404 {object} utils.ErrorResponse
+// @Header 200 {string} ETag "Entity tag of the returned record"
+// @Failure 401 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
 // @Router /api/v1/users/me [get]
 func (c *UserController) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value("userID").(uuid.UUID)
 	if !ok {
-		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in context")
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "User ID not found in context")
 		return
 	}
 
 	user, err := c.userService.GetByID(r.Context(), userID)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusNotFound, "User not found")
+		utils.RespondWithError(w, r, http.StatusNotFound, "User not found")
 		return
 	}
 
+	w.Header().Set("ETag", utils.ETag(user.UpdatedAt))
 	utils.RespondWithJSON(w, http.StatusOK, user)
 }
 
 // UpdateCurrentUser godoc
 // @Summary Update current user
-// @Description Update the current authenticated user's profile
+// @Description Update the current authenticated user's profile; omitted fields are left unchanged
 // @Tags users
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param request body UpdateUserRequest true "User update data"
+// @Param request body UpdateCurrentUserRequest true "Profile fields to change"
+// @Param If-Match header string true "ETag from a previous GET"
 // @Success 200 {object} model.User
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 401 {object} utils.ErrorResponse
-// @Failure 404 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
+// @Header 200 {string} ETag "Entity tag of the returned record"
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 412 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Failure 428 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
 // @Router /api/v1/users/me [patch]
 func (c *UserController) UpdateCurrentUser(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value("userID").(uuid.UUID)
 	if !ok {
-		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in context")
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "User ID not found in context")
 		return
 	}
 
-	var req UpdateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+	var req UpdateCurrentUserRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
 		return
 	}
 
 	user, err := c.userService.GetByID(r.Context(), userID)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusNotFound, "User not found")
+		utils.RespondWithError(w, r, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if err := utils.CheckIfMatch(r, utils.ETag(user.UpdatedAt)); err != nil {
+		utils.RespondWithAppError(w, r, err)
 		return
 	}
 
-	user.FirstName = req.FirstName
-	user.LastName = req.LastName
-	user.Department = req.Department
+	req.FirstName.Apply(&user.FirstName)
+	req.LastName.Apply(&user.LastName)
+	req.Department.ApplyPtr(&user.Department)
 
 	if err := c.userService.Update(r.Context(), user); err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Error updating user")
+		utils.RespondWithAppError(w, r, err)
 		return
 	}
 
+	w.Header().Set("ETag", utils.ETag(user.UpdatedAt))
 	utils.RespondWithJSON(w, http.StatusOK, user)
 }
 
 // GetAllUsers godoc
 // @Summary Get all users
-// @Description Get a list of all users (Admin only)
+// @Description Get a paginated, filterable and sortable list of users (Admin only)
 // @Tags users
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} model.User
-// @Failure 401 {object} utils.ErrorResponse
-// @Failure 403 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
+// @Param limit query int false "Page size (1-100, default 25)"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param role query string false "Role ID or role name"
+// @Param department query string false "Department"
+// @Param is_active query bool false "Active flag"
+// @Param created_from query string false "Created at or after (RFC3339 or YYYY-MM-DD)"
+// @Param created_to query string false "Created before (RFC3339 or YYYY-MM-DD)"
+// @Param q query string false "Search in name, username and email"
+// @Param deleted query bool false "List soft-deleted users instead of current ones"
+// @Param filter query string false "Filters written as filter[field]=value or filter[field][op]=value, op being eq, ne, lt, lte, gt, gte, in, contains or null"
+// @Param sort query string false "Sort field, prefix with - for descending (username, email, first_name, last_name, created_at, updated_at)"
+// @Param fields query string false "Comma-separated fields to return"
+// @Param include query string false "Comma-separated relations to embed (role, manager)"
+// @Success 200 {object} model.UserListResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
 // @Router /api/v1/users [get]
 func (c *UserController) GetAllUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := c.userService.GetAll(r.Context())
+	params, err := parseUserListParams(r.URL.Query())
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching users")
+		utils.RespondWithAppError(w, r, err)
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, users)
+	page, err := c.userService.List(r.Context(), params)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	respondWithPage(w, r, page.Data, page.Pagination, params.Query)
+}
+
+func parseUserListParams(query url.Values) (repository.UserListParams, error) {
+	params := repository.UserListParams{
+		Limit:      25,
+		Cursor:     query.Get("cursor"),
+		Department: query.Get("department"),
+		Search:     strings.TrimSpace(query.Get("q")),
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 100 {
+			return params, badQuery("limit must be between 1 and 100")
+		}
+		params.Limit = limit
+	}
+
+	if v := query.Get("role"); v != "" {
+		if roleID, err := uuid.Parse(v); err == nil {
+			params.RoleID = &roleID
+		} else {
+			params.RoleName = v
+		}
+	}
+
+	if v := query.Get("is_active"); v != "" {
+		isActive, err := strconv.ParseBool(v)
+		if err != nil {
+			return params, badQuery("is_active must be true or false")
+		}
+		params.IsActive = &isActive
+	}
+
+	if v := query.Get("deleted"); v != "" {
+		deleted, err := strconv.ParseBool(v)
+		if err != nil {
+			return params, badQuery("deleted must be true or false")
+		}
+		params.Deleted = deleted
+	}
+
+	if v := query.Get("created_from"); v != "" {
+		t, err := parseDateParam(v)
+		if err != nil {
+			return params, badQuery("created_from must be RFC3339 or YYYY-MM-DD")
+		}
+		params.CreatedFrom = &t
+	}
+
+	if v := query.Get("created_to"); v != "" {
+		t, err := parseDateParam(v)
+		if err != nil {
+			return params, badQuery("created_to must be RFC3339 or YYYY-MM-DD")
+		}
+		params.CreatedTo = &t
+	}
+
+	q, err := listquery.Parse(query, repository.UserListSchema)
+	if err != nil {
+		return params, err
+	}
+	params.Query = q
+
+	return params, nil
+}
+
+func parseDateParam(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", v)
 }
 
 // CreateUser godoc
@@ -148,21 +274,19 @@ func (c *UserController) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Security BearerAuth
 // @Param request body CreateUserRequest true "New user data"
+// @Param Idempotency-Key header string false "Client-chosen key; a retry with the same key and body replays the original response"
 // @Success 201 {object} model.User
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 401 {object} utils.ErrorResponse
-// @Failure 403 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 409 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
 // @Router /api/v1/users [post]
 func (c *UserController) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var req CreateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
-		return
-	}
-
-	if req.Username == "" || req.Email == "" || req.Password == "" || req.FirstName == "" || req.LastName == "" {
-		utils.RespondWithError(w, http.StatusBadRequest, "Required fields are missing")
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
 		return
 	}
 
@@ -177,17 +301,7 @@ func (c *UserController) CreateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := c.userService.Create(r.Context(), user, req.Password); err != nil {
-		if strings.Contains(err.Error(), "duplicate key") {
-			if strings.Contains(err.Error(), "username") {
-				utils.RespondWithError(w, http.StatusBadRequest, "Username already exists")
-			} else if strings.Contains(err.Error(), "email") {
-				utils.RespondWithError(w, http.StatusBadRequest, "Email already exists")
-			} else {
-				utils.RespondWithError(w, http.StatusBadRequest, "User already exists")
-			}
-			return
-		}
-		utils.RespondWithError(w, http.StatusInternalServerError, "Error creating user")
+		utils.RespondWithAppError(w, r, err)
 		return
 	}
 
@@ -202,119 +316,305 @@ func (c *UserController) CreateUser(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "User ID"
+// @Param deleted query bool false "Get a soft-deleted user instead of a current one"
 // @Success 200 {object} model.User
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 401 {object} utils.ErrorResponse
-// @Failure 403 {object} utils.ErrorResponse
-// @Failure 404 {object} utils.ErrorResponse
+// @Header 200 {string} ETag "Entity tag of the returned record"
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
 // @Router /api/v1/users/{id} [get]
 func (c *UserController) GetUserByID(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	if id == "" {
-		utils.RespondWithError(w, http.StatusBadRequest, "User ID is required")
+		utils.RespondWithError(w, r, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
 	userID, err := uuid.Parse(id)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid user ID format")
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid user ID format")
 		return
 	}
 
-	user, err := c.userService.GetByID(r.Context(), userID)
+	getUser := c.userService.GetByID
+	if v := r.URL.Query().Get("deleted"); v != "" {
+		deleted, err := strconv.ParseBool(v)
+		if err != nil {
+			utils.RespondWithAppError(w, r, badQuery("deleted must be true or false"))
+			return
+		}
+		if deleted {
+			getUser = c.userService.GetDeletedByID
+		}
+	}
+
+	user, err := getUser(r.Context(), userID)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusNotFound, "User not found")
+		utils.RespondWithError(w, r, http.StatusNotFound, "User not found")
 		return
 	}
 
+	w.Header().Set("ETag", utils.ETag(user.UpdatedAt))
 	utils.RespondWithJSON(w, http.StatusOK, user)
 }
 
 // UpdateUser godoc
 // @Summary Update user
-// @Description Update a user by ID (Admin only)
+// @Description Update a user by ID ; omitted fields are left unchanged (Admin only)
 // @Tags users
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "User ID"
-// @Param request body UpdateUserRequest true "User update data"
+// @Param request body UpdateUserRequest true "User fields to change"
+// @Param If-Match header string true "ETag from a previous GET"
 // @Success 200 {object} model.User
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 401 {object} utils.ErrorResponse
-// @Failure 403 {object} utils.ErrorResponse
-// @Failure 404 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
+// @Header 200 {string} ETag "Entity tag of the returned record"
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 412 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Failure 428 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
 // @Router /api/v1/users/{id} [patch]
 func (c *UserController) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	if id == "" {
-		utils.RespondWithError(w, http.StatusBadRequest, "User ID is required")
+		utils.RespondWithError(w, r, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
 	userID, err := uuid.Parse(id)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid user ID format")
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid user ID format")
 		return
 	}
 
 	var req UpdateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
 		return
 	}
 
 	user, err := c.userService.GetByID(r.Context(), userID)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusNotFound, "User not found")
+		utils.RespondWithError(w, r, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if err := utils.CheckIfMatch(r, utils.ETag(user.UpdatedAt)); err != nil {
+		utils.RespondWithAppError(w, r, err)
 		return
 	}
 
-	user.FirstName = req.FirstName
-	user.LastName = req.LastName
-	user.Department = req.Department
-	user.RoleID = req.RoleID
-	user.IsActive = req.IsActive
+	req.FirstName.Apply(&user.FirstName)
+	req.LastName.Apply(&user.LastName)
+	req.Department.ApplyPtr(&user.Department)
+	req.RoleID.Apply(&user.RoleID)
+	req.IsActive.Apply(&user.IsActive)
 
 	if err := c.userService.Update(r.Context(), user); err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Error updating user")
+		utils.RespondWithAppError(w, r, err)
 		return
 	}
 
+	w.Header().Set("ETag", utils.ETag(user.UpdatedAt))
 	utils.RespondWithJSON(w, http.StatusOK, user)
 }
 
 // DeleteUsers godoc
 // @Summary Delete multiple users
-// @Description Delete multiple users by IDs (Admin only)
+// @Description Soft-delete multiple users by IDs (Admin only). Open customers, opportunities and tasks are handed over to reassign_to, or unassigned when unassign is true; exactly one of the two is required.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param request body DeleteUsersRequest true "User IDs to delete"
+// @Param request body DeleteUsersRequest true "User IDs to delete and ownership handling"
+// @Param If-Match header string true "Comma-separated ETags of every user to delete, from previous GETs"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 401 {object} utils.ErrorResponse
-// @Failure 403 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 412 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Failure 428 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
 // @Router /api/v1/users [delete]
 func (c *UserController) DeleteUsers(w http.ResponseWriter, r *http.Request) {
 	var req DeleteUsersRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
 		return
 	}
 
-	if len(req.IDs) == 0 {
-		utils.RespondWithError(w, http.StatusBadRequest, "No user IDs provided")
+	if (req.ReassignTo == nil) == !req.Unassign {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Either reassign_to or unassign must be provided")
 		return
 	}
 
-	if err := c.userService.Delete(r.Context(), req.IDs); err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Error deleting users")
+	for _, id := range req.IDs {
+		user, err := c.userService.GetByID(r.Context(), id)
+		if err != nil {
+			utils.RespondWithError(w, r, http.StatusNotFound, "User not found")
+			return
+		}
+
+		if err := utils.CheckIfMatch(r, utils.ETag(user.UpdatedAt)); err != nil {
+			utils.RespondWithAppError(w, r, err)
+			return
+		}
+	}
+
+	if err := c.userService.Delete(r.Context(), req.IDs, req.ReassignTo); err != nil {
+		utils.RespondWithAppError(w, r, err)
 		return
 	}
 
 	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Users deleted successfully"})
 }
+
+// RestoreUser godoc
+// @Summary Restore a deleted user
+// @Description Restore a soft-deleted user by ID (Admin only)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param If-Match header string true "ETag from a previous GET with deleted=true"
+// @Success 200 {object} model.User
+// @Header 200 {string} ETag "Entity tag of the returned record"
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 412 {object} utils.Problem
+// @Failure 428 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/users/{id}/restore [post]
+func (c *UserController) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid user ID format")
+		return
+	}
+
+	deleted, err := c.userService.GetDeletedByID(r.Context(), userID)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if err := utils.CheckIfMatch(r, utils.ETag(deleted.UpdatedAt)); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	user, err := c.userService.Restore(r.Context(), userID)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", utils.ETag(user.UpdatedAt))
+	utils.RespondWithJSON(w, http.StatusOK, user)
+}
+
+// SetManager godoc
+// @Summary Set user manager
+// @Description Set or clear (null) the manager a user reports to (Admin only). Assignments that would create a reporting cycle are rejected.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param request body SetManagerRequest true "Manager ID or null"
+// @Param If-Match header string true "ETag from a previous GET"
+// @Success 200 {object} model.User
+// @Header 200 {string} ETag "Entity tag of the returned record"
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 403 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 409 {object} utils.Problem
+// @Failure 412 {object} utils.Problem
+// @Failure 422 {object} utils.Problem
+// @Failure 428 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/users/{id}/manager [put]
+func (c *UserController) SetManager(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid user ID format")
+		return
+	}
+
+	var req SetManagerRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	user, err := c.userService.GetByID(r.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if err := utils.CheckIfMatch(r, utils.ETag(user.UpdatedAt)); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	if err := c.userService.SetManager(r.Context(), userID, req.ManagerID); err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	user, err = c.userService.GetByID(r.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(w, r, http.StatusNotFound, "User not found")
+		return
+	}
+
+	w.Header().Set("ETag", utils.ETag(user.UpdatedAt))
+	utils.RespondWithJSON(w, http.StatusOK, user)
+}
+
+// GetOrgChart godoc
+// @Summary Get org chart
+// @Description Get the reporting hierarchy, either the whole organization or the subtree below a given user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param root query string false "User ID to use as the root of the chart"
+// @Success 200 {array} model.OrgChartNode
+// @Failure 400 {object} utils.Problem
+// @Failure 401 {object} utils.Problem
+// @Failure 404 {object} utils.Problem
+// @Failure 500 {object} utils.Problem
+// @Router /api/v1/users/org-chart [get]
+func (c *UserController) GetOrgChart(w http.ResponseWriter, r *http.Request) {
+	var root *uuid.UUID
+	if v := r.URL.Query().Get("root"); v != "" {
+		rootID, err := uuid.Parse(v)
+		if err != nil {
+			utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid root user ID format")
+			return
+		}
+		root = &rootID
+	}
+
+	chart, err := c.userService.GetOrgChart(r.Context(), root)
+	if err != nil {
+		utils.RespondWithAppError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, chart)
+}
@@ -0,0 +1,40 @@
+// Package idempotency stores the responses to requests sent with an
+// Idempotency-Key header so that retries are answered from the store instead
+// of repeating the operation.
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// LockTimeout is how long a key stays reserved by a request that has not
+// completed. After it, the request is presumed lost with its server and a
+// retry may run the operation again.
+const LockTimeout = 2 * time.Minute
+
+// Response is the stored reply to a completed request.
+type Response struct {
+	Status int               `json:"status"`
+	Header map[string]string `json:"header"`
+	Body   []byte            `json:"body"`
+}
+
+// Record is what a key holds. Response is nil while the first request with
+// the key is still running.
+type Record struct {
+	RequestHash string
+	Response    *Response
+}
+
+// Store keeps records per scope, normally the authenticated user, so keys
+// chosen by different clients cannot collide.
+type Store interface {
+	// Reserve claims key for a request with the given hash and returns nil,
+	// or returns the live record already stored under key.
+	Reserve(ctx context.Context, scope, key, hash string, ttl time.Duration) (*Record, error)
+	// Complete stores the response for a reserved key.
+	Complete(ctx context.Context, scope, key string, resp *Response) error
+	// Release frees a reserved key so the request can be retried.
+	Release(ctx context.Context, scope, key string) error
+}
@@ -0,0 +1,82 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	record    Record
+	createdAt time.Time
+	expiresAt time.Time
+}
+
+// MemoryStore keeps keys in process memory. Retries reaching another
+// instance are not recognized.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+
+	// Now returns the current time; tests may replace it.
+	Now func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: map[string]*memoryEntry{},
+		Now:     time.Now,
+	}
+}
+
+// Reserve
+func (s *MemoryStore) Reserve(ctx context.Context, scope, key, hash string, ttl time.Duration) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.Now()
+	for k, entry := range s.entries {
+		if expired(entry, now) {
+			delete(s.entries, k)
+		}
+	}
+
+	if entry, ok := s.entries[scope+"\x00"+key]; ok {
+		record := entry.record
+		return &record, nil
+	}
+
+	s.entries[scope+"\x00"+key] = &memoryEntry{
+		record:    Record{RequestHash: hash},
+		createdAt: now,
+		expiresAt: now.Add(ttl),
+	}
+	return nil, nil
+}
+
+// Complete
+func (s *MemoryStore) Complete(ctx context.Context, scope, key string, resp *Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[scope+"\x00"+key]; ok {
+		entry.record.Response = resp
+	}
+	return nil
+}
+
+// Release
+func (s *MemoryStore) Release(ctx context.Context, scope, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, scope+"\x00"+key)
+	return nil
+}
+
+// expired reports entries past their retention, and reservations whose
+// request has been running for longer than LockTimeout.
+func expired(entry *memoryEntry, now time.Time) bool {
+	return !now.Before(entry.expiresAt) ||
+		(entry.record.Response == nil && now.Sub(entry.createdAt) >= LockTimeout)
+}
@@ -0,0 +1,122 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"customize_crm/logging"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// pruneEvery is how many Reserve calls pass between deletions of expired keys.
+const pruneEvery = 1000
+
+// PostgresStore keeps keys in the idempotency_keys table, shared by every
+// replica. The primary key on (scope, key) makes Reserve atomic.
+type PostgresStore struct {
+	db    *pgxpool.Pool
+	calls atomic.Uint64
+}
+
+func NewPostgresStore(db *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Reserve
+func (s *PostgresStore) Reserve(ctx context.Context, scope, key, hash string, ttl time.Duration) (*Record, error) {
+	if s.calls.Add(1)%pruneEvery == 0 {
+		if err := s.prune(ctx); err != nil {
+			logging.FromContext(ctx).Error("idempotency key prune failed", "error", err)
+		}
+	}
+
+	// An expired key, or a reservation abandoned past LockTimeout, is taken
+	// over as if it were new.
+	insertQuery := `
+		INSERT INTO idempotency_keys AS k (scope, key, request_hash, expires_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP + make_interval(secs => $4))
+		ON CONFLICT (scope, key) DO UPDATE SET
+			request_hash = EXCLUDED.request_hash,
+			status = NULL,
+			header = NULL,
+			body = NULL,
+			created_at = CURRENT_TIMESTAMP,
+			expires_at = EXCLUDED.expires_at
+		WHERE k.expires_at <= CURRENT_TIMESTAMP
+			OR (k.status IS NULL AND k.created_at <= CURRENT_TIMESTAMP - make_interval(secs => $5))
+	`
+	tag, err := s.db.Exec(ctx, insertQuery, scope, key, hash, ttl.Seconds(), LockTimeout.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 1 {
+		return nil, nil
+	}
+
+	selectQuery := `
+		SELECT request_hash, status, header, body
+		FROM idempotency_keys
+		WHERE scope = $1 AND key = $2
+	`
+
+	var record Record
+	var status *int
+	var header []byte
+	var body []byte
+	err = s.db.QueryRow(ctx, selectQuery, scope, key).Scan(&record.RequestHash, &status, &header, &body)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Released or purged since the insert; let the caller go ahead.
+		return s.Reserve(ctx, scope, key, hash, ttl)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if status != nil {
+		record.Response = &Response{Status: *status, Body: body}
+		if err := json.Unmarshal(header, &record.Response.Header); err != nil {
+			return nil, err
+		}
+	}
+
+	return &record, nil
+}
+
+// Complete
+func (s *PostgresStore) Complete(ctx context.Context, scope, key string, resp *Response) error {
+	header, err := json.Marshal(resp.Header)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE idempotency_keys
+		SET status = $3, header = $4, body = $5
+		WHERE scope = $1 AND key = $2
+	`
+
+	_, err = s.db.Exec(ctx, query, scope, key, resp.Status, header, resp.Body)
+	return err
+}
+
+// Release
+func (s *PostgresStore) Release(ctx context.Context, scope, key string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2`, scope, key)
+	return err
+}
+
+// prune deletes expired keys and abandoned reservations.
+func (s *PostgresStore) prune(ctx context.Context) error {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE expires_at <= CURRENT_TIMESTAMP
+			OR (status IS NULL AND created_at <= CURRENT_TIMESTAMP - make_interval(secs => $1))
+	`
+	_, err := s.db.Exec(ctx, query, LockTimeout.Seconds())
+	return err
+}
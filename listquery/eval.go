@@ -0,0 +1,260 @@
+package listquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"customize_crm/utils"
+
+	"github.com/google/uuid"
+)
+
+// The helpers below read fields by their JSON name. They let the in-memory
+// repositories apply a Query the way the SQL does, and build keyset cursors
+// for any sort.
+
+var jsonFields sync.Map // reflect.Type -> map[string]int
+
+// Value returns the field of item, a pointer to a struct, named by its JSON
+// name. Pointers are dereferenced, and nil is returned for nil ones.
+func Value(item any, name string) any {
+	v := reflect.Indirect(reflect.ValueOf(item))
+	i, ok := fieldIndex(v.Type())[name]
+	if !ok {
+		return nil
+	}
+
+	f := v.Field(i)
+	if f.Kind() == reflect.Pointer {
+		if f.IsNil() {
+			return nil
+		}
+		f = f.Elem()
+	}
+
+	switch x := f.Interface().(type) {
+	case int:
+		return float64(x)
+	case int64:
+		return float64(x)
+	default:
+		return x
+	}
+}
+
+func fieldIndex(t reflect.Type) map[string]int {
+	if cached, ok := jsonFields.Load(t); ok {
+		return cached.(map[string]int)
+	}
+
+	index := map[string]int{}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			index[name] = i
+		}
+	}
+
+	jsonFields.Store(t, index)
+	return index
+}
+
+// Match reports whether item passes every filter, with SQL semantics: a null
+// field only matches null and ne filters.
+func Match(item any, filters []Filter) bool {
+	for _, f := range filters {
+		if !matches(Value(item, f.Field), f) {
+			return false
+		}
+	}
+	return true
+}
+
+func matches(v any, f Filter) bool {
+	if f.Op == Null {
+		return (v == nil) == f.Value.(bool)
+	}
+	if v == nil {
+		return f.Op == Ne
+	}
+
+	switch f.Op {
+	case Eq:
+		return Compare(v, f.Value) == 0
+	case Ne:
+		return Compare(v, f.Value) != 0
+	case Lt:
+		return Compare(v, f.Value) < 0
+	case Lte:
+		return Compare(v, f.Value) <= 0
+	case Gt:
+		return Compare(v, f.Value) > 0
+	case Gte:
+		return Compare(v, f.Value) >= 0
+	case In:
+		list := reflect.ValueOf(f.Value)
+		for i := 0; i < list.Len(); i++ {
+			if Compare(v, list.Index(i).Interface()) == 0 {
+				return true
+			}
+		}
+		return false
+	case Any, All:
+		tags := v.([]string)
+		for _, want := range f.Value.([]string) {
+			found := false
+			for _, tag := range tags {
+				if tag == want {
+					found = true
+					break
+				}
+			}
+			if found == (f.Op == Any) {
+				return found
+			}
+		}
+		return f.Op == All
+	case Contains:
+		if tags, ok := v.([]string); ok {
+			for _, tag := range tags {
+				if tag == f.Value {
+					return true
+				}
+			}
+			return false
+		}
+		return strings.Contains(strings.ToLower(v.(string)), strings.ToLower(f.Value.(string)))
+	}
+
+	return false
+}
+
+// Compare orders two field values of the same type.
+func Compare(a, b any) int {
+	switch x := a.(type) {
+	case string:
+		return strings.Compare(x, b.(string))
+	case float64:
+		y := b.(float64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case bool:
+		y := b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		}
+		return 1
+	case time.Time:
+		return x.Compare(b.(time.Time))
+	case uuid.UUID:
+		y := b.(uuid.UUID)
+		return bytes.Compare(x[:], y[:])
+	}
+	return 0
+}
+
+// Less orders items by s, then by ID in the same direction, matching the
+// ORDER BY of the SQL implementations.
+func Less(a, b any, s Sort) bool {
+	c := Compare(Value(a, s.Field), Value(b, s.Field))
+	if c == 0 {
+		c = Compare(Value(a, "id"), Value(b, "id"))
+	}
+	if s.Desc {
+		return c > 0
+	}
+	return c < 0
+}
+
+// After reports whether item comes after the keyset position made of a
+// sort value, as returned by Sort.ParseCursor, and an ID.
+func After(item any, s Sort, value any, id uuid.UUID) bool {
+	c := Compare(Value(item, s.Field), value)
+	if c == 0 {
+		c = Compare(Value(item, "id"), id)
+	}
+	if s.Desc {
+		return c < 0
+	}
+	return c > 0
+}
+
+// Cursor returns the keyset position of item in order s.
+func Cursor(item any, s Sort) utils.Cursor {
+	var value string
+	switch v := Value(item, s.Field).(type) {
+	case time.Time:
+		value = v.Format(time.RFC3339Nano)
+	case float64:
+		value = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		value = strconv.FormatBool(v)
+	case uuid.UUID:
+		value = v.String()
+	case string:
+		value = v
+	}
+
+	return utils.Cursor{Value: value, ID: Value(item, "id").(uuid.UUID)}
+}
+
+// ParseCursor reads a cursor value written by Cursor.
+func (s Sort) ParseCursor(value string) (any, error) {
+	v, err := parseScalar(s.Type, value)
+	if err != nil {
+		return nil, utils.ErrInvalidCursor
+	}
+	return v, nil
+}
+
+// Project trims each item to the requested fields. The id and any included
+// relations are always kept. Without requested fields, items are returned
+// as they are.
+func Project[T any](items []T, q Query) (any, error) {
+	if len(q.Fields) == 0 {
+		return items, nil
+	}
+
+	keep := map[string]bool{"id": true}
+	for _, name := range q.Fields {
+		keep[name] = true
+	}
+	for _, name := range q.Include {
+		keep[name] = true
+	}
+
+	projected := make([]map[string]json.RawMessage, len(items))
+	for i, item := range items {
+		raw, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil, err
+		}
+
+		for name := range fields {
+			if !keep[name] {
+				delete(fields, name)
+			}
+		}
+		projected[i] = fields
+	}
+
+	return projected, nil
+}
@@ -0,0 +1,350 @@
+// Package listquery parses the query parameters shared by list endpoints:
+//
+//	filter[field][op]=value   filter[status]=open, filter[amount][gte]=1000
+//	                          filter[tags][any]=vip,partner, filter[tags][all]=vip,partner
+//	sort=-created_at          one field, - for descending
+//	fields=id,name            only these fields in each item
+//	include=contacts          related records embedded in each item
+//
+// Field names are checked against a per-entity Schema, and only the columns
+// named there ever reach SQL; values are always passed as arguments.
+package listquery
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"customize_crm/apperror"
+
+	"github.com/google/uuid"
+)
+
+type Type int
+
+const (
+	String Type = iota
+	Number
+	Bool
+	Time
+	UUID
+	// Strings is a text array, such as customer tags.
+	Strings
+)
+
+type Op string
+
+const (
+	Eq       Op = "eq"
+	Ne       Op = "ne"
+	Lt       Op = "lt"
+	Lte      Op = "lte"
+	Gt       Op = "gt"
+	Gte      Op = "gte"
+	In       Op = "in"
+	Contains Op = "contains"
+	Null     Op = "null"
+	// Any and All match array fields holding at least one, or every one, of
+	// the listed values.
+	Any Op = "any"
+	All Op = "all"
+)
+
+// typeOps lists the operators each field type accepts.
+var typeOps = map[Type][]Op{
+	String:  {Eq, Ne, Lt, Lte, Gt, Gte, In, Contains, Null},
+	Number:  {Eq, Ne, Lt, Lte, Gt, Gte, In, Null},
+	Bool:    {Eq, Ne, Null},
+	Time:    {Eq, Ne, Lt, Lte, Gt, Gte, Null},
+	UUID:    {Eq, Ne, In, Null},
+	Strings: {Contains, Any, All},
+}
+
+// Field is a public field of an entity, named as in its JSON, and the column
+// that stores it. Only fields whose column is never NULL may be Sortable, so
+// keyset pagination can compare them.
+type Field struct {
+	Column   string
+	Type     Type
+	Sortable bool
+}
+
+// Schema is the allow-list of an entity: the fields that may be filtered,
+// sorted and selected, and the relations that may be included.
+type Schema struct {
+	Fields   map[string]Field
+	Includes []string
+}
+
+// WithoutIncludes returns a copy of the schema that allows no relations, for
+// lists that cannot embed them.
+func (s *Schema) WithoutIncludes() *Schema {
+	c := *s
+	c.Includes = nil
+	return &c
+}
+
+// Filter is one parsed filter. Value holds the parsed value: a string,
+// float64, bool, time.Time or uuid.UUID, a slice of them for In, Any and All,
+// and a bool for Null telling whether the field must be null.
+type Filter struct {
+	Field  string
+	Column string
+	Type   Type
+	Op     Op
+	Value  any
+}
+
+type Sort struct {
+	Field  string
+	Column string
+	Type   Type
+	Desc   bool
+}
+
+// DefaultSort is newest first, which every entity supports.
+var DefaultSort = Sort{Field: "created_at", Column: "created_at", Type: Time, Desc: true}
+
+type Query struct {
+	Filters []Filter
+	Sort    Sort
+	Fields  []string
+	Include []string
+}
+
+// Order returns the requested sort, or DefaultSort.
+func (q Query) Order() Sort {
+	if q.Sort.Field == "" {
+		return DefaultSort
+	}
+	return q.Sort
+}
+
+// Includes reports whether the relation was requested.
+func (q Query) Includes(name string) bool {
+	for _, include := range q.Include {
+		if include == name {
+			return true
+		}
+	}
+	return false
+}
+
+const CodeInvalidQuery = "invalid_query"
+
+var filterParam = regexp.MustCompile(`^filter\[([^\[\]]+)\](?:\[([^\[\]]+)\])?$`)
+
+// Parse reads the list query parameters of values against schema. Unknown
+// fields, operators and relations are rejected with a 400 listing every bad
+// parameter. Parameters it does not own, such as limit and cursor, are left
+// to the caller.
+func Parse(values url.Values, schema *Schema) (Query, error) {
+	var q Query
+	var fields []apperror.FieldError
+
+	fail := func(param, code, message string) {
+		fields = append(fields, apperror.FieldError{Field: param, Code: code, Message: param + " " + message})
+	}
+
+	params := make([]string, 0, len(values))
+	for param := range values {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+
+	for _, param := range params {
+		m := filterParam.FindStringSubmatch(param)
+		if m == nil {
+			if strings.HasPrefix(param, "filter") {
+				fail(param, "invalid_filter", "must be written as filter[field] or filter[field][op]")
+			}
+			continue
+		}
+
+		field, ok := schema.Fields[m[1]]
+		if !ok {
+			fail(param, "unknown_field", "is not a filterable field")
+			continue
+		}
+
+		op := Op(m[2])
+		if op == "" {
+			op = Eq
+		}
+		if !field.accepts(op) {
+			fail(param, "invalid_operator", "does not support the "+string(op)+" operator")
+			continue
+		}
+
+		for _, raw := range values[param] {
+			value, err := parseValue(field.Type, op, raw)
+			if err != nil {
+				fail(param, apperror.CodeInvalidValue, err.Error())
+				continue
+			}
+			q.Filters = append(q.Filters, Filter{Field: m[1], Column: field.Column, Type: field.Type, Op: op, Value: value})
+		}
+	}
+
+	if v := values.Get("sort"); v != "" {
+		name := strings.TrimPrefix(v, "-")
+		field, ok := schema.Fields[name]
+		switch {
+		case strings.Contains(v, ","):
+			fail("sort", "invalid_sort", "accepts a single field")
+		case !ok || !field.Sortable:
+			fail("sort", "unknown_field", "is not a sortable field: "+name)
+		default:
+			q.Sort = Sort{Field: name, Column: field.Column, Type: field.Type, Desc: strings.HasPrefix(v, "-")}
+		}
+	}
+
+	if v := values.Get("fields"); v != "" {
+		for _, name := range splitList(v) {
+			if _, ok := schema.Fields[name]; !ok {
+				fail("fields", "unknown_field", "contains an unknown field: "+name)
+				continue
+			}
+			q.Fields = append(q.Fields, name)
+		}
+	}
+
+	if v := values.Get("include"); v != "" {
+		for _, name := range splitList(v) {
+			if !contains(schema.Includes, name) {
+				fail("include", "unknown_relation", "contains an unknown relation: "+name)
+				continue
+			}
+			q.Include = append(q.Include, name)
+		}
+	}
+
+	if len(fields) > 0 {
+		return q, &apperror.Error{
+			Status:  http.StatusBadRequest,
+			Code:    CodeInvalidQuery,
+			Message: "invalid list query",
+			Fields:  fields,
+		}
+	}
+
+	return q, nil
+}
+
+func (f Field) accepts(op Op) bool {
+	for _, allowed := range typeOps[f.Type] {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+func parseValue(t Type, op Op, raw string) (any, error) {
+	switch op {
+	case Null:
+		null, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.New("must be true or false")
+		}
+		return null, nil
+	case In, Any, All:
+		return parseList(t, splitList(raw))
+	}
+
+	if t == Strings {
+		return raw, nil
+	}
+	return parseScalar(t, raw)
+}
+
+func parseList(t Type, raws []string) (any, error) {
+	if len(raws) == 0 {
+		return nil, errors.New("must list at least one value")
+	}
+
+	values := make([]any, len(raws))
+	for i, raw := range raws {
+		v, err := parseScalar(t, raw)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	// Typed slices encode as Postgres arrays.
+	switch t {
+	case Number:
+		return collect[float64](values), nil
+	case UUID:
+		return collect[uuid.UUID](values), nil
+	default:
+		return collect[string](values), nil
+	}
+}
+
+func parseScalar(t Type, raw string) (any, error) {
+	switch t {
+	case Number:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, errors.New("must be a number")
+		}
+		return n, nil
+	case Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.New("must be true or false")
+		}
+		return b, nil
+	case Time:
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			return t, nil
+		}
+		t, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return nil, errors.New("must be RFC3339 or YYYY-MM-DD")
+		}
+		return t, nil
+	case UUID:
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, errors.New("must be an ID")
+		}
+		return id, nil
+	default:
+		return raw, nil
+	}
+}
+
+func collect[T any](values []any) []T {
+	out := make([]T, len(values))
+	for i, v := range values {
+		out[i] = v.(T)
+	}
+	return out
+}
+
+func splitList(v string) []string {
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func contains(list []string, name string) bool {
+	for _, item := range list {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
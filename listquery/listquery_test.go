@@ -0,0 +1,157 @@
+package listquery
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"customize_crm/apperror"
+
+	"github.com/google/uuid"
+)
+
+var testSchema = &Schema{
+	Fields: map[string]Field{
+		"id":         {Column: "id", Type: UUID, Sortable: true},
+		"name":       {Column: "name", Type: String, Sortable: true},
+		"amount":     {Column: "amount", Type: Number, Sortable: true},
+		"active":     {Column: "is_active", Type: Bool},
+		"owner_id":   {Column: "assigned_to", Type: UUID},
+		"tags":       {Column: "tags", Type: Strings},
+		"created_at": {Column: "created_at", Type: Time, Sortable: true},
+	},
+	Includes: []string{"contacts"},
+}
+
+func TestParse(t *testing.T) {
+	owner := uuid.MustParse("6f1c6c1e-8d4a-4a43-9a4b-3f0b1c2d3e4f")
+
+	values := url.Values{
+		"filter[name]":           {"Acme"},
+		"filter[amount][gte]":    {"1000"},
+		"filter[active]":         {"true"},
+		"filter[owner_id][in]":   {owner.String() + ", "},
+		"filter[tags][all]":      {"vip,partner"},
+		"filter[created_at][lt]": {"2024-02-01"},
+		"filter[owner_id][null]": {"false"},
+		"sort":                   {"-amount"},
+		"fields":                 {"name, amount"},
+		"include":                {"contacts"},
+		"limit":                  {"10"},
+		"cursor":                 {"abc"},
+	}
+
+	q, err := Parse(values, testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Parameters are read in sorted order.
+	want := []Filter{
+		{Field: "active", Column: "is_active", Type: Bool, Op: Eq, Value: true},
+		{Field: "amount", Column: "amount", Type: Number, Op: Gte, Value: 1000.0},
+		{Field: "created_at", Column: "created_at", Type: Time, Op: Lt, Value: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{Field: "name", Column: "name", Type: String, Op: Eq, Value: "Acme"},
+		{Field: "owner_id", Column: "assigned_to", Type: UUID, Op: In, Value: []uuid.UUID{owner}},
+		{Field: "owner_id", Column: "assigned_to", Type: UUID, Op: Null, Value: false},
+		{Field: "tags", Column: "tags", Type: Strings, Op: All, Value: []string{"vip", "partner"}},
+	}
+	if !reflect.DeepEqual(q.Filters, want) {
+		t.Errorf("filters =\n%+v\nwant\n%+v", q.Filters, want)
+	}
+
+	if q.Order() != (Sort{Field: "amount", Column: "amount", Type: Number, Desc: true}) {
+		t.Errorf("sort = %+v", q.Order())
+	}
+	if !reflect.DeepEqual(q.Fields, []string{"name", "amount"}) {
+		t.Errorf("fields = %v", q.Fields)
+	}
+	if !q.Includes("contacts") {
+		t.Errorf("include = %v", q.Include)
+	}
+
+	empty, err := Parse(url.Values{}, testSchema)
+	if err != nil || empty.Order() != DefaultSort {
+		t.Errorf("empty query: sort %+v, error %v", empty.Order(), err)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		values url.Values
+		want   []string
+	}{
+		{"unknown field", url.Values{"filter[password_hash]": {"x"}}, []string{"filter[password_hash]:unknown_field"}},
+		{"malformed filter", url.Values{"filter[name][eq][x]": {"x"}, "filter": {"x"}}, []string{"filter:invalid_filter", "filter[name][eq][x]:invalid_filter"}},
+		{"operator for another type", url.Values{"filter[active][gt]": {"true"}}, []string{"filter[active][gt]:invalid_operator"}},
+		{"unknown operator", url.Values{"filter[name][like]": {"a"}}, []string{"filter[name][like]:invalid_operator"}},
+		{"scalar operator on an array", url.Values{"filter[tags]": {"vip"}}, []string{"filter[tags]:invalid_operator"}},
+		{"bad number", url.Values{"filter[amount]": {"lots"}}, []string{"filter[amount]:invalid_value"}},
+		{"bad time", url.Values{"filter[created_at][gt]": {"yesterday"}}, []string{"filter[created_at][gt]:invalid_value"}},
+		{"empty list", url.Values{"filter[owner_id][in]": {" , "}}, []string{"filter[owner_id][in]:invalid_value"}},
+		{"bad null flag", url.Values{"filter[name][null]": {"maybe"}}, []string{"filter[name][null]:invalid_value"}},
+		{"unsortable field", url.Values{"sort": {"tags"}}, []string{"sort:unknown_field"}},
+		{"several sort fields", url.Values{"sort": {"name,-amount"}}, []string{"sort:invalid_sort"}},
+		{"unknown projected field", url.Values{"fields": {"name,secret"}}, []string{"fields:unknown_field"}},
+		{"unknown relation", url.Values{"include": {"contacts,owner"}}, []string{"include:unknown_relation"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.values, testSchema)
+
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) || appErr.Status != http.StatusBadRequest || appErr.Code != CodeInvalidQuery {
+				t.Fatalf("error = %v, want 400 %s", err, CodeInvalidQuery)
+			}
+			var got []string
+			for _, f := range appErr.Fields {
+				got = append(got, f.Field+":"+f.Code)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("failures = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Parse(url.Values{"include": {"contacts"}}, testSchema.WithoutIncludes()); err == nil {
+		t.Error("WithoutIncludes still allows contacts")
+	}
+}
+
+type item struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	Amount   float64   `json:"amount"`
+	Contacts []string  `json:"contacts,omitempty"`
+}
+
+func TestProject(t *testing.T) {
+	items := []item{{ID: uuid.New(), Name: "Acme", Amount: 5, Contacts: []string{"Ann"}}}
+
+	same, err := Project(items, Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(same, items) {
+		t.Errorf("without fields: %v, want the items unchanged", same)
+	}
+
+	projected, err := Project(items, Query{Fields: []string{"name"}, Include: []string{"contacts"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(projected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"contacts":["Ann"],"id":"` + items[0].ID.String() + `","name":"Acme"}]`
+	if string(got) != want {
+		t.Errorf("projected = %s, want %s", got, want)
+	}
+}
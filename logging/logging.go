@@ -0,0 +1,117 @@
+// Package logging builds the application's structured JSON logger and
+// carries request-scoped loggers through contexts.
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+type contextKey struct{}
+
+// New returns a JSON logger writing records at or above level to w. Sensitive
+// attributes are redacted; see IsSensitive.
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	}))
+}
+
+// WithLogger returns a context carrying logger.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the request-scoped logger, or the default logger when
+// the context has none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// With adds attributes to the request-scoped logger in ctx.
+func With(ctx context.Context, args ...any) context.Context {
+	return WithLogger(ctx, FromContext(ctx).With(args...))
+}
+
+// IsSensitive reports whether an attribute, header or JSON field with the
+// given name holds a credential.
+func IsSensitive(name string) bool {
+	name = strings.ToLower(name)
+
+	switch name {
+	case "authorization", "proxy-authorization", "cookie", "set-cookie":
+		return true
+	}
+
+	return strings.Contains(name, "password") ||
+		strings.Contains(name, "secret") ||
+		strings.HasSuffix(name, "token")
+}
+
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	if IsSensitive(attr.Key) {
+		return slog.String(attr.Key, redacted)
+	}
+
+	if attr.Value.Kind() == slog.KindAny {
+		if value, ok := redactValue(attr.Value.Any()); ok {
+			return slog.Any(attr.Key, value)
+		}
+	}
+
+	return attr
+}
+
+// redactValue masks sensitive fields of structs and maps by their JSON
+// names, so logging a request payload never leaks its password.
+func redactValue(v any) (any, bool) {
+	if _, ok := v.(error); ok {
+		return nil, false
+	}
+
+	kind := reflect.Indirect(reflect.ValueOf(v)).Kind()
+	if kind != reflect.Struct && kind != reflect.Map {
+		return nil, false
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, false
+	}
+
+	return redactJSON(decoded), true
+}
+
+func redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if IsSensitive(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactJSON(value)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = redactJSON(value)
+		}
+	}
+
+	return v
+}
@@ -2,25 +2,32 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"net/netip"
 	"os"
 	"os/signal"
-	"strconv"
 	"syscall"
 	"time"
 
+	"customize_crm/cli"
+	"customize_crm/config"
 	"customize_crm/controller"
 	_ "customize_crm/docs"
+	"customize_crm/idempotency"
+	"customize_crm/logging"
+	"customize_crm/metrics"
 	"customize_crm/middleware"
+	"customize_crm/model"
+	"customize_crm/ratelimit"
+	"customize_crm/repository/postgres"
 	"customize_crm/service"
+	"customize_crm/tracing"
 
 	"github.com/go-chi/chi/v5"
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/joho/godotenv"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
@@ -45,78 +52,179 @@ import (
 // @description Type "Bearer" followed by a space and the JWT token.
 
 func main() {
-	// 1. Load environment variables
-	loadEnvFile()
+	slog.SetDefault(logging.New(os.Stderr, slog.LevelInfo))
 
-	// 2. Connect to database
-	dbPool := connectToDatabase()
+	// 1. Run a CLI subcommand that needs no configuration or database, such
+	// as rotate-jwt-key, before either is checked
+	if len(os.Args) > 1 && cli.IsOffline(os.Args[1]) {
+		if err := cli.Run(context.Background(), nil, nil, os.Args[1:]); err != nil {
+			fatal("command failed", "command", os.Args[1], "error", err)
+		}
+		return
+	}
+
+	// 2. Load configuration
+	cfg := loadConfig()
+	logger := logging.New(os.Stderr, cfg.Log.SlogLevel())
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal("unable to set up tracing", "error", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("error flushing traces", "error", err)
+		}
+	}()
+
+	// 3. Connect to database
+	dbPool := connectToDatabase(cfg.Database)
 	defer dbPool.Close()
 
-	// 3. Configure and start server
-	startServer(dbPool)
-}
+	// 4. Run a CLI subcommand instead of the server, if one was given
+	if len(os.Args) > 1 {
+		if err := cli.Run(context.Background(), cfg, dbPool, os.Args[1:]); err != nil {
+			fatal("command failed", "command", os.Args[1], "error", err)
+		}
+		return
+	}
 
-// loadEnvFile loads variables from .env file
-func loadEnvFile() {
-	if err := godotenv.Load(); err != nil {
-		log.Printf("Warning: .env file not found or could not be loaded: %v\n", err)
+	// 5. Apply pending migrations when enabled
+	if cfg.Database.AutoMigrate {
+		if err := cli.MigrateUp(context.Background(), dbPool); err != nil {
+			fatal("unable to migrate database", "error", err)
+		}
 	}
+
+	// 6. Configure and start server
+	startServer(cfg, dbPool, logger)
 }
 
-func connectToDatabase() *pgxpool.Pool {
-	connString := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_NAME"),
-		os.Getenv("DB_SSL_MODE"),
-	)
+// fatal logs at error level and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
 
-	config, err := pgxpool.ParseConfig(connString)
+// loadConfig loads and validates the configuration, exiting on any error
+func loadConfig() *config.Config {
+	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Unable to parse connection string: %v", err)
+		fatal("invalid configuration", "error", err)
 	}
 
-	maxConns, err := strconv.Atoi(os.Getenv("DB_MAX_CONNECTIONS"))
+	slog.Info("loaded configuration", "config", cfg.String())
+	return cfg
+}
+
+func connectToDatabase(cfg config.DatabaseConfig) *pgxpool.Pool {
+	poolConfig, err := pgxpool.ParseConfig(cfg.ConnString())
 	if err != nil {
-		log.Fatalf("Invalid DB_MAX_CONNECTIONS value: %v", err)
+		fatal("unable to parse connection string", "error", err)
 	}
 
-	config.MaxConns = int32(maxConns)
+	poolConfig.MaxConns = int32(cfg.MaxConnections)
+	poolConfig.ConnConfig.Tracer = tracing.QueryTracer{}
 
-	dbPool, err := pgxpool.NewWithConfig(context.Background(), config)
+	dbPool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
-		log.Fatalf("Unable to connect to database: %v", err)
+		fatal("unable to connect to database", "error", err)
 	}
 
 	if err := dbPool.Ping(context.Background()); err != nil {
-		log.Fatalf("Unable to ping database: %v", err)
+		fatal("unable to ping database", "error", err)
 	}
 
-	log.Println("Successfully connected to database")
+	slog.Info("connected to database", "host", cfg.Host, "name", cfg.Name)
 	return dbPool
 }
 
-func startServer(dbPool *pgxpool.Pool) {
+func startServer(cfg *config.Config, dbPool *pgxpool.Pool, logger *slog.Logger) {
+	// repositories
+	userRepository := postgres.NewUserRepository(dbPool)
+	roleRepository := postgres.NewRoleRepository(dbPool)
+	customerRepository := postgres.NewCustomerRepository(dbPool)
+	opportunityRepository := postgres.NewOpportunityRepository(dbPool)
+	taskRepository := postgres.NewTaskRepository(dbPool)
+	contactRepository := postgres.NewContactRepository(dbPool)
+	productRepository := postgres.NewProductRepository(dbPool)
+	searchRepository := postgres.NewSearchRepository(dbPool)
+	tagRepository := postgres.NewTagRepository(dbPool)
+	teamRepository := postgres.NewTeamRepository(dbPool)
+	passkeyRepository := postgres.NewPasskeyRepository(dbPool)
+	invitationRepository := postgres.NewInvitationRepository(dbPool)
+	delegationRepository := postgres.NewDelegationRepository(dbPool)
+	reassignmentRepository := postgres.NewReassignmentRepository(dbPool)
+	activityLogRepository := postgres.NewActivityLogRepository(dbPool)
+	transactor := postgres.NewTransactor(dbPool)
+
 	//  services
-	userService := service.NewUserService(dbPool)
-	authService := service.NewAuthService(userService)
+	userService := service.NewUserService(userRepository, roleRepository)
+	authService := service.NewAuthService(userService, cfg.JWT)
+	passkeyService, err := service.NewPasskeyService(passkeyRepository, userService, cfg.WebAuthn)
+	if err != nil {
+		fatal("invalid WebAuthn configuration", "error", err)
+	}
+	activityLogService := service.NewActivityLogService(activityLogRepository)
+	customerService := service.NewCustomerService(customerRepository, contactRepository, opportunityRepository)
+	opportunityService := service.NewOpportunityService(opportunityRepository, customerRepository)
+	taskService := service.NewTaskService(taskRepository, customerRepository, opportunityRepository)
+	productService := service.NewProductService(productRepository)
+	tagService := service.NewTagService(tagRepository)
+	searchService := service.NewSearchService(searchRepository)
+	reportService := service.NewReportService(opportunityRepository, taskRepository)
+	teamService := service.NewTeamService(teamRepository, opportunityRepository, taskRepository)
+	mailService := service.NewMailService(cfg.SMTP)
+	invitationService := service.NewInvitationService(transactor, invitationRepository, userRepository, roleRepository, mailService, cfg.Invitation)
+	delegationService := service.NewDelegationService(delegationRepository)
+	reassignmentService := service.NewReassignmentService(transactor, reassignmentRepository, activityLogRepository)
+	healthService, err := service.NewHealthService(dbPool)
+	if err != nil {
+		fatal("unable to load migrations", "error", err)
+	}
+
+	appMetrics := metrics.New(dbPool)
 
 	// controllers
-	authController := controller.NewAuthController(authService)
+	authController := controller.NewAuthController(authService, appMetrics)
 	userController := controller.NewUserController(userService)
-
-	router := setupRouter()
+	passkeyController := controller.NewPasskeyController(passkeyService, authService, appMetrics)
+	invitationController := controller.NewInvitationController(invitationService)
+	impersonationController := controller.NewImpersonationController(authService, activityLogService)
+	customerController := controller.NewCustomerController(customerService, userService)
+	opportunityController := controller.NewOpportunityController(opportunityService, userService)
+	taskController := controller.NewTaskController(taskService, userService)
+	productController := controller.NewProductController(productService)
+	tagController := controller.NewTagController(tagService, userService)
+	searchController := controller.NewSearchController(searchService, userService)
+	reportController := controller.NewReportController(reportService, userService)
+	teamController := controller.NewTeamController(teamService, userService)
+	delegationController := controller.NewDelegationController(delegationService)
+	reassignmentController := controller.NewReassignmentController(reassignmentService)
+	healthController := controller.NewHealthController(healthService)
+
+	// Validated when the configuration was loaded.
+	trustedProxies, _ := cfg.Server.TrustedProxyPrefixes()
+	router := setupRouter(logger, appMetrics, trustedProxies, setupRateLimiter(cfg, dbPool))
 
 	router.Get("/swagger/*", httpSwagger.Handler(
 		httpSwagger.URL("/swagger/doc.json"),
 	))
 
-	setupAuthRoutes(router, authController, userService)
-	setupUserRoutes(router, userController, userService)
+	authMiddleware := middleware.NewAuthMiddleware(userService, activityLogService, cfg.JWT.Secret)
+	idempotencyMiddleware := setupIdempotency(cfg, dbPool)
 
-	port := getEnv("SERVER_PORT", "8080")
+	setupHealthRoutes(router, healthController)
+	setupAuthRoutes(router, authController, passkeyController, invitationController, authMiddleware)
+	setupUserRoutes(router, userController, passkeyController, invitationController, impersonationController, delegationController, authMiddleware, idempotencyMiddleware)
+	setupCRMRoutes(router, customerController, opportunityController, taskController, productController, searchController, reportController, authMiddleware)
+	setupTagRoutes(router, tagController, authMiddleware, idempotencyMiddleware)
+	setupTeamRoutes(router, teamController, authMiddleware, idempotencyMiddleware)
+	setupReassignmentRoutes(router, reassignmentController, authMiddleware, idempotencyMiddleware)
+	adminServer := setupMetricsRoutes(router, cfg.Metrics, appMetrics)
+
+	port := cfg.Server.Port
 	server := &http.Server{
 		Addr:         ":" + port,
 		Handler:      router,
@@ -126,97 +234,262 @@ func startServer(dbPool *pgxpool.Pool) {
 	}
 
 	go func() {
-		log.Printf("Starting server on port %s", port)
-		log.Printf("Swagger documentation available at http://localhost:%s/swagger/index.html", port)
+		slog.Info("starting server", "port", port,
+			"swagger", "http://localhost:"+port+"/swagger/index.html")
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed to start: %v", err)
+			fatal("server failed to start", "error", err)
 		}
 	}()
 
-	waitForShutdownSignal(server)
+	if adminServer != nil {
+		go func() {
+			slog.Info("starting metrics server", "port", cfg.Metrics.Port)
+			if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fatal("metrics server failed to start", "error", err)
+			}
+		}()
+	}
+
+	waitForShutdownSignal(server, adminServer, healthService, cfg.Server.ShutdownDrain())
 }
 
-func setupRouter() *chi.Mux {
+func setupRouter(logger *slog.Logger, appMetrics *metrics.Metrics, trustedProxies []netip.Prefix, rateLimiter *middleware.RateLimiter) *chi.Mux {
 	router := chi.NewRouter()
 
 	// Global middleware
-	router.Use(chimiddleware.Logger)
-	router.Use(chimiddleware.Recoverer)
 	router.Use(chimiddleware.RequestID)
-	router.Use(chimiddleware.RealIP)
+	router.Use(middleware.RealIP(trustedProxies))
+	router.Use(tracing.Middleware)
+	router.Use(middleware.RequestLogger(logger))
+	router.Use(appMetrics.Middleware)
 	router.Use(chimiddleware.Timeout(60 * time.Second))
 	router.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "If-Match", "Idempotency-Key"},
+		ExposedHeaders:   []string{"Link", "ETag", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After", "Idempotent-Replayed"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
 
+	// After CORS so rejected requests still carry the CORS headers
+	if rateLimiter != nil {
+		router.Use(rateLimiter.Limit)
+	}
+
 	return router
 }
 
-func setupAuthRoutes(router *chi.Mux, controller *controller.AuthController, userService *service.UserService) {
+// setupRateLimiter returns nil when rate limiting is disabled.
+func setupRateLimiter(cfg *config.Config, dbPool *pgxpool.Pool) *middleware.RateLimiter {
+	if !cfg.RateLimit.Enabled {
+		slog.Info("rate limiting disabled")
+		return nil
+	}
+
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimit.Store == "postgres" {
+		store = ratelimit.NewPostgresStore(dbPool)
+	}
+
+	return middleware.NewRateLimiter(store, cfg.RateLimit, cfg.JWT.Secret)
+}
+
+func setupIdempotency(cfg *config.Config, dbPool *pgxpool.Pool) *middleware.Idempotency {
+	var store idempotency.Store = idempotency.NewPostgresStore(dbPool)
+	if cfg.Idempotency.Store == "memory" {
+		store = idempotency.NewMemoryStore()
+	}
+
+	return middleware.NewIdempotency(store, cfg.Idempotency.Retention())
+}
+
+func setupHealthRoutes(router *chi.Mux, controller *controller.HealthController) {
+	router.Get("/healthz", controller.Liveness)
+	router.Get("/readyz", controller.Readiness)
+}
+
+func setupAuthRoutes(router *chi.Mux, controller *controller.AuthController, passkeyController *controller.PasskeyController, invitationController *controller.InvitationController, authMiddleware *middleware.AuthMiddleware) {
 	// Public auth
 	router.Route("/api/v1/auth", func(r chi.Router) {
 		r.Post("/login", controller.Login)
 		r.Post("/refresh-token", controller.RefreshToken)
 		r.Post("/forgot-password", controller.ForgotPassword)
 		r.Post("/reset-password", controller.ResetPassword)
+		r.Post("/passkey/begin", passkeyController.BeginLogin)
+		r.Post("/passkey/finish", passkeyController.FinishLogin)
+		r.Post("/accept-invitation", invitationController.AcceptInvitation)
 
 		// Protected auth
 		r.Group(func(r chi.Router) {
-			authMiddleware := middleware.NewAuthMiddleware(userService)
 			r.Use(authMiddleware.Authenticate)
 			r.Post("/logout", controller.Logout)
 		})
 	})
 }
 
-func setupUserRoutes(router *chi.Mux, controller *controller.UserController, userService *service.UserService) {
+func setupUserRoutes(router *chi.Mux, controller *controller.UserController, passkeyController *controller.PasskeyController, invitationController *controller.InvitationController, impersonationController *controller.ImpersonationController, delegationController *controller.DelegationController, authMiddleware *middleware.AuthMiddleware, idempotencyMiddleware *middleware.Idempotency) {
 	router.Route("/api/v1/users", func(r chi.Router) {
-		authMiddleware := middleware.NewAuthMiddleware(userService)
 		r.Use(authMiddleware.Authenticate)
 
 		r.Get("/me", controller.GetCurrentUser)
 		r.Patch("/me", controller.UpdateCurrentUser)
+		r.Get("/org-chart", controller.GetOrgChart)
+
+		r.Route("/me/passkeys", func(r chi.Router) {
+			r.Use(authMiddleware.RejectImpersonation)
+			r.Get("/", passkeyController.ListPasskeys)
+			r.Post("/register/begin", passkeyController.BeginRegistration)
+			r.Post("/register/finish", passkeyController.FinishRegistration)
+			r.Get("/{id}", passkeyController.GetPasskey)
+			r.Patch("/{id}", passkeyController.RenamePasskey)
+			r.Delete("/{id}", passkeyController.DeletePasskey)
+		})
+
+		r.Get("/me/delegations", delegationController.GetDelegations)
+		r.With(idempotencyMiddleware.Handle).Post("/me/delegations", delegationController.CreateDelegation)
+		r.Get("/me/delegations/{id}", delegationController.GetDelegation)
+		r.Delete("/me/delegations/{id}", delegationController.RevokeDelegation)
 
 		r.Group(func(r chi.Router) {
 			r.Use(authMiddleware.RequireAdmin)
 			r.Get("/", controller.GetAllUsers)
-			r.Post("/", controller.CreateUser)
+			r.With(idempotencyMiddleware.Handle).Post("/", controller.CreateUser)
 			r.Get("/{id}", controller.GetUserByID)
 			r.Patch("/{id}", controller.UpdateUser)
 			r.Delete("/", controller.DeleteUsers)
+			r.Post("/{id}/restore", controller.RestoreUser)
+			r.Put("/{id}/manager", controller.SetManager)
+			r.With(authMiddleware.RequirePermission(model.PermissionImpersonateUsers)).
+				Post("/{id}/impersonate", impersonationController.Impersonate)
+
+			r.Get("/invitations", invitationController.GetInvitations)
+			r.With(idempotencyMiddleware.Handle).Post("/invitations", invitationController.InviteUser)
+			r.Get("/invitations/{id}", invitationController.GetInvitation)
+			r.Post("/invitations/{id}/resend", invitationController.ResendInvitation)
+			r.Delete("/invitations/{id}", invitationController.RevokeInvitation)
 		})
 	})
 }
 
-func waitForShutdownSignal(server *http.Server) {
+func setupCRMRoutes(router *chi.Mux, customerController *controller.CustomerController, opportunityController *controller.OpportunityController, taskController *controller.TaskController, productController *controller.ProductController, searchController *controller.SearchController, reportController *controller.ReportController, authMiddleware *middleware.AuthMiddleware) {
+	router.Group(func(r chi.Router) {
+		r.Use(authMiddleware.Authenticate)
+
+		r.Get("/api/v1/customers", customerController.GetCustomers)
+		r.Get("/api/v1/opportunities", opportunityController.GetOpportunities)
+		r.Get("/api/v1/tasks", taskController.GetTasks)
+		r.Get("/api/v1/products", productController.GetProducts)
+		r.Get("/api/v1/search", searchController.Search)
+		r.Get("/api/v1/reports/pipeline", reportController.GetPipelineReport)
+		r.Get("/api/v1/reports/tasks", reportController.GetTaskReport)
+	})
+}
+
+func setupTagRoutes(router *chi.Mux, controller *controller.TagController, authMiddleware *middleware.AuthMiddleware, idempotencyMiddleware *middleware.Idempotency) {
+	router.With(authMiddleware.Authenticate).Post("/api/v1/customers/tags", controller.UpdateCustomerTags)
+
+	router.Route("/api/v1/tags", func(r chi.Router) {
+		r.Use(authMiddleware.Authenticate)
+
+		r.Get("/", controller.GetTags)
+		r.Get("/{id}", controller.GetTagByID)
+
+		r.Group(func(r chi.Router) {
+			r.Use(authMiddleware.RequireAdmin)
+			r.With(idempotencyMiddleware.Handle).Post("/", controller.CreateTag)
+			r.Patch("/{id}", controller.UpdateTag)
+			r.Delete("/{id}", controller.DeleteTag)
+			r.Post("/{id}/merge", controller.MergeTag)
+		})
+	})
+}
+
+func setupTeamRoutes(router *chi.Mux, controller *controller.TeamController, authMiddleware *middleware.AuthMiddleware, idempotencyMiddleware *middleware.Idempotency) {
+	router.Route("/api/v1/teams", func(r chi.Router) {
+		r.Use(authMiddleware.Authenticate)
+
+		r.Get("/", controller.GetTeams)
+		r.Get("/{id}", controller.GetTeamByID)
+
+		// Team leads or admins
+		r.Put("/{id}/members/{userID}", controller.SetTeamMember)
+		r.Delete("/{id}/members/{userID}", controller.RemoveTeamMember)
+		r.Post("/{id}/records", controller.AssignTeamRecords)
+		r.Delete("/{id}/records", controller.UnassignTeamRecords)
+		r.Get("/{id}/pipeline", controller.GetTeamPipeline)
+		r.Get("/{id}/opportunities", controller.GetTeamOpportunities)
+		r.Get("/{id}/tasks", controller.GetTeamTasks)
+
+		r.Group(func(r chi.Router) {
+			r.Use(authMiddleware.RequireAdmin)
+			r.With(idempotencyMiddleware.Handle).Post("/", controller.CreateTeam)
+			r.Patch("/{id}", controller.UpdateTeam)
+			r.Delete("/{id}", controller.DeleteTeam)
+		})
+	})
+}
+
+func setupReassignmentRoutes(router *chi.Mux, controller *controller.ReassignmentController, authMiddleware *middleware.AuthMiddleware, idempotencyMiddleware *middleware.Idempotency) {
+	router.Route("/api/v1/reassignments", func(r chi.Router) {
+		r.Use(authMiddleware.Authenticate)
+		r.Use(authMiddleware.RequireAdmin)
+
+		r.Get("/", controller.GetReassignments)
+		r.With(idempotencyMiddleware.Handle).Post("/", controller.ReassignRecords)
+	})
+}
+
+// setupMetricsRoutes exposes /metrics either on a separate admin server, which
+// is returned for the caller to start, or on the API router behind a token.
+func setupMetricsRoutes(router *chi.Mux, cfg config.MetricsConfig, appMetrics *metrics.Metrics) *http.Server {
+	switch {
+	case cfg.Port != "":
+		adminRouter := chi.NewRouter()
+		adminRouter.Handle("/metrics", appMetrics.Handler())
+
+		return &http.Server{
+			Addr:              ":" + cfg.Port,
+			Handler:           adminRouter,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+	case cfg.Token != "":
+		router.With(metrics.RequireToken(cfg.Token)).Handle("/metrics", appMetrics.Handler())
+	default:
+		slog.Info("metrics endpoint disabled; set METRICS_PORT or METRICS_TOKEN to enable it")
+	}
+
+	return nil
+}
+
+// waitForShutdownSignal flips /readyz to not ready and waits for the drain
+// period, so load balancers stop sending traffic, before shutting down.
+func waitForShutdownSignal(server, adminServer *http.Server, healthService *service.HealthService, drain time.Duration) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
 	<-quit
-	log.Println("Shutting down server...")
+	healthService.Drain()
+	slog.Info("draining before shutdown", "drain", drain.String())
+	time.Sleep(drain)
+
+	slog.Info("shutting down server")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
-		log.Printf("Error during server shutdown: %v\n", err)
+		slog.Error("error during server shutdown", "error", err)
 		if err := server.Close(); err != nil {
-			log.Printf("Could not close server: %v\n", err)
+			slog.Error("could not close server", "error", err)
 		}
 	}
 
-	log.Println("Server stopped successfully")
-}
-
-func getEnv(key, defaultValue string) string {
-	value, exists := os.LookupEnv(key)
-	if exists {
-		return value
+	if adminServer != nil {
+		if err := adminServer.Shutdown(ctx); err != nil {
+			slog.Error("error during metrics server shutdown", "error", err)
+		}
 	}
-	return defaultValue
+
+	slog.Info("server stopped")
 }
@@ -0,0 +1,147 @@
+// Package metrics exposes Prometheus metrics for HTTP traffic, the database
+// pool and authentication events.
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "crm"
+
+// Login methods and results used as label values.
+const (
+	LoginPassword = "password"
+	LoginPasskey  = "passkey"
+
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	logins          *prometheus.CounterVec
+	refreshes       *prometheus.CounterVec
+}
+
+// New registers the application metrics, the Go runtime and process
+// collectors and, when pool is not nil, the connection pool statistics.
+func New(pool *pgxpool.Pool) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, chi route pattern and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method and chi route pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_logins_total",
+			Help:      "Login attempts by method and result.",
+		}, []string{"method", "result"}),
+		refreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_token_refreshes_total",
+			Help:      "Token refresh attempts by result.",
+		}, []string{"result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.logins,
+		m.refreshes,
+	)
+
+	if pool != nil {
+		m.registry.MustRegister(newPoolCollector(pool))
+	}
+
+	return m
+}
+
+// Middleware records the count and latency of every request, labelled with
+// the matched route pattern rather than the raw path to bound cardinality.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		defer func() {
+			route := "unmatched"
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			m.requests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+			m.requestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+		}()
+
+		next.ServeHTTP(ww, r)
+	})
+}
+
+// ObserveLogin counts a login attempt.
+func (m *Metrics) ObserveLogin(method string, err error) {
+	m.logins.WithLabelValues(method, result(err)).Inc()
+}
+
+// ObserveRefresh counts a token refresh attempt.
+func (m *Metrics) ObserveRefresh(err error) {
+	m.refreshes.WithLabelValues(result(err)).Inc()
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// RequireToken protects the metrics endpoint with a static bearer token for
+// deployments that expose it on the public port.
+func RequireToken(token string) func(http.Handler) http.Handler {
+	expected := []byte("Bearer " + token)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func result(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+	return ResultSuccess
+}
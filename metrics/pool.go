@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector reads pgxpool statistics at scrape time.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns     *prometheus.Desc
+	idleConns         *prometheus.Desc
+	totalConns        *prometheus.Desc
+	constructingConns *prometheus.Desc
+	maxConns          *prometheus.Desc
+	acquires          *prometheus.Desc
+	acquireDuration   *prometheus.Desc
+	emptyAcquires     *prometheus.Desc
+	canceledAcquires  *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+
+	return &poolCollector{
+		pool:              pool,
+		acquiredConns:     desc("acquired_connections", "Connections currently checked out of the pool."),
+		idleConns:         desc("idle_connections", "Idle connections in the pool."),
+		totalConns:        desc("total_connections", "Connections in the pool, including those being established."),
+		constructingConns: desc("constructing_connections", "Connections being established."),
+		maxConns:          desc("max_connections", "Maximum size of the pool."),
+		acquires:          desc("acquires_total", "Successful connection acquisitions."),
+		acquireDuration:   desc("acquire_wait_seconds_total", "Total time spent waiting to acquire a connection."),
+		emptyAcquires:     desc("empty_acquires_total", "Acquisitions that had to wait because the pool was empty."),
+		canceledAcquires:  desc("canceled_acquires_total", "Acquisitions canceled by their context."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.totalConns
+	ch <- c.constructingConns
+	ch <- c.maxConns
+	ch <- c.acquires
+	ch <- c.acquireDuration
+	ch <- c.emptyAcquires
+	ch <- c.canceledAcquires
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructingConns, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquires, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+}
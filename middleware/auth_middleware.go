@@ -2,81 +2,211 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"strings"
 
+	"customize_crm/logging"
+	"customize_crm/model"
 	"customize_crm/service"
+	"customize_crm/tracing"
 	"customize_crm/utils"
 
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 type AuthMiddleware struct {
-	userService *service.UserService
+	userService        *service.UserService
+	activityLogService *service.ActivityLogService
+	jwtSecret          string
 }
 
-func NewAuthMiddleware(userService *service.UserService) *AuthMiddleware {
+func NewAuthMiddleware(userService *service.UserService, activityLogService *service.ActivityLogService, jwtSecret string) *AuthMiddleware {
 	return &AuthMiddleware{
-		userService: userService,
+		userService:        userService,
+		activityLogService: activityLogService,
+		jwtSecret:          jwtSecret,
 	}
 }
 
 func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			utils.RespondWithError(w, http.StatusUnauthorized, "Authorization header is required")
+		ctx, failure := m.authenticate(r)
+		if failure != nil {
+			utils.RespondWithError(w, r, failure.status, failure.message)
 			return
 		}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			utils.RespondWithError(w, http.StatusUnauthorized, "Authorization header format must be Bearer {token}")
+		actorID, ok := ctx.Value("actorID").(uuid.UUID)
+		if !ok {
+			next.ServeHTTP(w, r.WithContext(ctx))
 			return
 		}
 
-		tokenString := parts[1]
+		userID := ctx.Value("userID").(uuid.UUID)
 
-		claims, err := utils.ValidateToken(tokenString)
-		if err != nil {
-			utils.RespondWithError(w, http.StatusUnauthorized, "Invalid or expired token")
-			return
-		}
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		r = r.WithContext(ctx)
+		next.ServeHTTP(ww, r)
 
-		userID, err := uuid.Parse(claims.Subject)
-		if err != nil {
-			utils.RespondWithError(w, http.StatusUnauthorized, "Invalid user ID in token")
-			return
-		}
+		m.auditImpersonatedRequest(r, ww.Status(), actorID, userID)
+	})
+}
 
-		user, err := m.userService.GetByID(r.Context(), userID)
-		if err != nil {
-			utils.RespondWithError(w, http.StatusUnauthorized, "User not found")
-			return
-		}
+// authFailure is the response to send when authentication fails.
+type authFailure struct {
+	status  int
+	message string
+}
 
-		role, err := m.userService.GetRoleByID(r.Context(), user.RoleID)
-		if err != nil {
-			utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching user role")
-			return
+// authenticate validates the bearer token and returns the request context
+// carrying the user, role and permissions, plus the actor when impersonating.
+// The lookups run inside their own span so traces show the cost of auth.
+func (m *AuthMiddleware) authenticate(r *http.Request) (ctx context.Context, failure *authFailure) {
+	spanCtx, span := tracing.Tracer().Start(r.Context(), "AuthMiddleware.Authenticate")
+	defer func() {
+		if failure != nil {
+			span.SetStatus(codes.Error, failure.message)
 		}
+		span.End()
+	}()
 
-		ctx := context.WithValue(r.Context(), "userID", userID)
-		ctx = context.WithValue(ctx, "user", user)
-		ctx = context.WithValue(ctx, "role", role.Name)
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return nil, &authFailure{http.StatusUnauthorized, "Authorization header is required"}
+	}
+
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil, &authFailure{http.StatusUnauthorized, "Authorization header format must be Bearer {token}"}
+	}
 
-		next.ServeHTTP(w, r.WithContext(ctx))
+	tokenString := parts[1]
+
+	claims, err := utils.ValidateToken(tokenString, m.jwtSecret)
+	if err != nil {
+		return nil, &authFailure{http.StatusUnauthorized, "Invalid or expired token"}
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, &authFailure{http.StatusUnauthorized, "Invalid user ID in token"}
+	}
+	span.SetAttributes(semconv.EnduserID(userID.String()))
+
+	user, err := m.userService.GetByID(spanCtx, userID)
+	if err != nil {
+		return nil, &authFailure{http.StatusUnauthorized, "User not found"}
+	}
+	if !user.IsActive {
+		return nil, &authFailure{http.StatusUnauthorized, "User account is disabled"}
+	}
+
+	role, err := m.userService.GetRoleByID(spanCtx, user.RoleID)
+	if err != nil {
+		return nil, &authFailure{http.StatusInternalServerError, "Error fetching user role"}
+	}
+	span.SetAttributes(semconv.EnduserRole(role.Name))
+
+	ctx = context.WithValue(r.Context(), "userID", userID)
+	ctx = context.WithValue(ctx, "user", user)
+	ctx = context.WithValue(ctx, "role", role.Name)
+	ctx = context.WithValue(ctx, "permissions", role.PermissionList())
+
+	if claims.Actor == nil {
+		return setRequestUser(ctx, userID, nil), nil
+	}
+
+	actorID, err := uuid.Parse(claims.Actor.Subject)
+	if err != nil {
+		return nil, &authFailure{http.StatusUnauthorized, "Invalid actor ID in token"}
+	}
+
+	actor, err := m.userService.GetByID(spanCtx, actorID)
+	if err != nil || !actor.IsActive {
+		return nil, &authFailure{http.StatusUnauthorized, "Impersonating user is no longer valid"}
+	}
+	span.SetAttributes(attribute.String("enduser.actor_id", actorID.String()))
+
+	ctx = context.WithValue(ctx, "actorID", actorID)
+	return setRequestUser(ctx, userID, &actorID), nil
+}
+
+// auditImpersonatedRequest records a request made with an impersonation
+// token, naming both the real actor and the impersonated user.
+func (m *AuthMiddleware) auditImpersonatedRequest(r *http.Request, status int, actorID, userID uuid.UUID) {
+	route := r.URL.Path
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		route = rctx.RoutePattern()
+	}
+
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"actor_id":   actorID,
+		"subject_id": userID,
+		"method":     r.Method,
+		"path":       r.URL.Path,
+		"route":      route,
+		"status":     status,
+		"request_id": chimiddleware.GetReqID(r.Context()),
 	})
+
+	entry := &model.ActivityLog{
+		UserID:       &userID,
+		ActorID:      &actorID,
+		ActivityType: model.ActivityImpersonatedRequest,
+		EntityType:   "user",
+		EntityID:     userID,
+		Description:  r.Method + " " + route,
+		Metadata:     metadata,
+	}
+
+	if err := m.activityLogService.Create(context.WithoutCancel(r.Context()), entry); err != nil {
+		logging.FromContext(r.Context()).Error("recording impersonated request failed", "error", err)
+	}
 }
 
 func (m *AuthMiddleware) RequireAdmin(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		role, ok := r.Context().Value("role").(string)
 		if !ok || role != "Admin" {
-			utils.RespondWithError(w, http.StatusForbidden, "Admin permission required")
+			utils.RespondWithError(w, r, http.StatusForbidden, "Admin permission required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RejectImpersonation blocks credential management and similar sensitive
+// operations for requests made with an impersonation token.
+func (m *AuthMiddleware) RejectImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Value("actorID").(uuid.UUID); ok {
+			utils.RespondWithError(w, r, http.StatusForbidden, "Not allowed while impersonating")
 			return
 		}
 
 		next.ServeHTTP(w, r)
 	})
 }
+
+func (m *AuthMiddleware) RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			permissions, _ := r.Context().Value("permissions").([]string)
+			for _, p := range permissions {
+				if p == permission {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			utils.RespondWithError(w, r, http.StatusForbidden, "Permission "+permission+" required")
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"customize_crm/apperror"
+	"customize_crm/idempotency"
+	"customize_crm/logging"
+	"customize_crm/utils"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	maxIdempotencyKeyLen = 255
+	maxIdempotentBody    = 1 << 20
+)
+
+var (
+	ErrIdempotencyKeyReused = apperror.New(http.StatusUnprocessableEntity, "idempotency_key_reused", "idempotency key was already used with a different request")
+	ErrIdempotencyKeyInUse  = apperror.New(http.StatusConflict, "idempotency_key_in_use", "a request with this idempotency key is still being processed")
+)
+
+// replayedHeaders are the response headers kept with a stored response.
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+type Idempotency struct {
+	store     idempotency.Store
+	retention time.Duration
+}
+
+func NewIdempotency(store idempotency.Store, retention time.Duration) *Idempotency {
+	return &Idempotency{store: store, retention: retention}
+}
+
+// Handle makes a create endpoint safe to retry. The first request with a
+// given Idempotency-Key runs and its response is stored; retries with the same
+// method, path and body get that response back with Idempotent-Replayed set.
+// Reusing a key for a different request is rejected with 422. Keys are scoped
+// to the authenticated user, so Handle must run after Authenticate. Requests
+// without the header are passed through, as are requests when the store
+// fails. Server errors are not stored, so the client can retry them.
+func (m *Idempotency) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			utils.RespondWithAppError(w, r, apperror.Validation(apperror.FieldError{
+				Field:   idempotencyKeyHeader,
+				Code:    "max",
+				Message: idempotencyKeyHeader + " must be at most " + strconv.Itoa(maxIdempotencyKeyLen) + " characters",
+			}))
+			return
+		}
+
+		userID, ok := r.Context().Value("userID").(uuid.UUID)
+		if !ok {
+			utils.RespondWithError(w, r, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+		scope := userID.String()
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBody))
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				utils.RespondWithError(w, r, http.StatusRequestEntityTooLarge, "Request body is too large")
+				return
+			}
+			utils.RespondWithError(w, r, http.StatusBadRequest, "Could not read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := requestHash(r, body)
+		ctx := context.WithoutCancel(r.Context())
+		logger := logging.FromContext(r.Context())
+
+		record, err := m.store.Reserve(ctx, scope, key, hash, m.retention)
+		if err != nil {
+			logger.Error("idempotency key lookup failed", "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if record != nil {
+			switch {
+			case record.RequestHash != hash:
+				utils.RespondWithAppError(w, r, ErrIdempotencyKeyReused)
+			case record.Response == nil:
+				w.Header().Set("Retry-After", "1")
+				utils.RespondWithAppError(w, r, ErrIdempotencyKeyInUse)
+			default:
+				replay(w, record.Response)
+			}
+			return
+		}
+
+		completed := false
+		defer func() {
+			if !completed {
+				if err := m.store.Release(ctx, scope, key); err != nil {
+					logger.Error("idempotency key release failed", "error", err)
+				}
+			}
+		}()
+
+		var captured bytes.Buffer
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		ww.Tee(&captured)
+
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		resp := &idempotency.Response{Status: status, Header: map[string]string{}, Body: captured.Bytes()}
+		for _, name := range replayedHeaders {
+			if value := ww.Header().Get(name); value != "" {
+				resp.Header[name] = value
+			}
+		}
+
+		if err := m.store.Complete(ctx, scope, key, resp); err != nil {
+			logger.Error("idempotency response store failed", "error", err)
+			return
+		}
+		completed = true
+	})
+}
+
+// requestHash fingerprints what a retry must repeat exactly.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.Path+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func replay(w http.ResponseWriter, resp *idempotency.Response) {
+	for name, value := range resp.Header {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(resp.Status)
+	w.Write(resp.Body)
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"customize_crm/idempotency"
+
+	"github.com/google/uuid"
+)
+
+func TestIdempotencyReplay(t *testing.T) {
+	store := idempotency.NewMemoryStore()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.Now = func() time.Time { return now }
+
+	calls := 0
+	status := http.StatusCreated
+	handler := NewIdempotency(store, time.Hour).Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Location", "/customers/1")
+		w.Header().Set("X-Request-Id", "not replayed")
+		w.WriteHeader(status)
+		w.Write(body)
+	}))
+
+	userID := uuid.New()
+	send := func(user uuid.UUID, key, path, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		r = r.WithContext(context.WithValue(r.Context(), "userID", user))
+		if key != "" {
+			r.Header.Set("Idempotency-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		return rec
+	}
+
+	first := send(userID, "k1", "/customers", `{"name":"Acme"}`)
+	if first.Code != http.StatusCreated || calls != 1 {
+		t.Fatalf("first request: status %d after %d calls", first.Code, calls)
+	}
+
+	retry := send(userID, "k1", "/customers", `{"name":"Acme"}`)
+	if calls != 1 {
+		t.Error("retry ran the handler again")
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != `{"name":"Acme"}` {
+		t.Errorf("retry: %d %s, want the original response", retry.Code, retry.Body)
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" || retry.Header().Get("Location") != "/customers/1" {
+		t.Errorf("retry headers = %v", retry.Header())
+	}
+	if retry.Header().Get("X-Request-Id") != "" {
+		t.Error("replayed a header outside the kept set")
+	}
+
+	for name, rec := range map[string]*httptest.ResponseRecorder{
+		"different body": send(userID, "k1", "/customers", `{"name":"Other"}`),
+		"different path": send(userID, "k1", "/tasks", `{"name":"Acme"}`),
+	} {
+		if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "idempotency_key_reused") {
+			t.Errorf("%s: %d %s, want 422 idempotency_key_reused", name, rec.Code, rec.Body)
+		}
+	}
+
+	// Keys belong to the user who sent them.
+	if send(uuid.New(), "k1", "/customers", `{"name":"Acme"}`); calls != 2 {
+		t.Error("another user's request was replayed")
+	}
+
+	// Requests without a key always run.
+	send(userID, "", "/customers", `{"name":"Acme"}`)
+	send(userID, "", "/customers", `{"name":"Acme"}`)
+	if calls != 4 {
+		t.Errorf("handler ran %d times, want 4", calls)
+	}
+
+	// Server errors are not stored, so the retry runs again.
+	status = http.StatusInternalServerError
+	send(userID, "k2", "/customers", `{}`)
+	status = http.StatusCreated
+	if rec := send(userID, "k2", "/customers", `{}`); rec.Code != http.StatusCreated || calls != 6 {
+		t.Errorf("retry after a server error: status %d after %d calls", rec.Code, calls)
+	}
+
+	// Once retention passes the key can be used for a new request.
+	now = now.Add(time.Hour)
+	if rec := send(userID, "k1", "/customers", `{"name":"Other"}`); rec.Code != http.StatusCreated || rec.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("expired key: %d, replayed %q", rec.Code, rec.Header().Get("Idempotent-Replayed"))
+	}
+}
+
+func TestIdempotencyKeyInUse(t *testing.T) {
+	store := idempotency.NewMemoryStore()
+	userID := uuid.New()
+
+	var inner *httptest.ResponseRecorder
+	var handler http.Handler
+	send := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/customers", strings.NewReader(`{}`))
+		r = r.WithContext(context.WithValue(r.Context(), "userID", userID))
+		r.Header.Set("Idempotency-Key", "k1")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		return rec
+	}
+	// The retry arrives while the first request is still running.
+	handler = NewIdempotency(store, time.Hour).Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if inner == nil {
+			inner = send()
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	if rec := send(); rec.Code != http.StatusCreated {
+		t.Fatalf("first request: status %d", rec.Code)
+	}
+	if inner.Code != http.StatusConflict || inner.Header().Get("Retry-After") != "1" {
+		t.Errorf("concurrent retry: %d, Retry-After %q, want 409 with Retry-After 1", inner.Code, inner.Header().Get("Retry-After"))
+	}
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"customize_crm/config"
+	"customize_crm/logging"
+	"customize_crm/ratelimit"
+	"customize_crm/utils"
+)
+
+const authPathPrefix = "/api/v1/auth/"
+
+type RateLimiter struct {
+	store     ratelimit.Store
+	jwtSecret string
+
+	user   ratelimit.Policy
+	apiKey ratelimit.Policy
+	ip     ratelimit.Policy
+	auth   ratelimit.Policy
+}
+
+func NewRateLimiter(store ratelimit.Store, cfg config.RateLimitConfig, jwtSecret string) *RateLimiter {
+	window := cfg.Window()
+	return &RateLimiter{
+		store:     store,
+		jwtSecret: jwtSecret,
+		user:      ratelimit.Policy{Name: "user", Limit: cfg.UserRequests, Window: window},
+		apiKey:    ratelimit.Policy{Name: "api_key", Limit: cfg.APIKeyRequests, Window: window},
+		ip:        ratelimit.Policy{Name: "ip", Limit: cfg.IPRequests, Window: window},
+		auth:      ratelimit.Policy{Name: "auth", Limit: cfg.AuthRequests, Window: window},
+	}
+}
+
+// bucketRef names a bucket and the policy that applies to it.
+type bucketRef struct {
+	key    string
+	policy ratelimit.Policy
+}
+
+// Limit throttles /api/ requests. It runs before authentication, so it reads
+// the user from the bearer token itself; the signature check needs no
+// database lookup. Clients that are not signed in are always counted by IP as
+// well, so sending made-up API keys cannot get around the IP limit. When the
+// store fails, requests are let through rather than failing the API.
+func (l *RateLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var reported *ratelimit.Result
+		var reportedPolicy ratelimit.Policy
+		for _, ref := range l.buckets(r) {
+			res, err := l.store.Take(r.Context(), ref.key, ref.policy)
+			if err != nil {
+				logging.FromContext(r.Context()).Error("rate limit check failed", "policy", ref.policy.Name, "error", err)
+				continue
+			}
+
+			if reported == nil || !res.Allowed || (reported.Allowed && res.Remaining < reported.Remaining) {
+				reported, reportedPolicy = &res, ref.policy
+			}
+			if !res.Allowed {
+				break
+			}
+		}
+
+		if reported == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(reported.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(reported.Remaining))
+		header.Set("RateLimit-Reset", ceilSeconds(reported.Reset))
+		header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s", reportedPolicy.Limit, ceilSeconds(reportedPolicy.Window)))
+
+		if !reported.Allowed {
+			header.Set("Retry-After", ceilSeconds(reported.RetryAfter))
+			utils.RespondWithError(w, r, http.StatusTooManyRequests, "Rate limit exceeded")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// buckets returns the buckets a request is counted against.
+func (l *RateLimiter) buckets(r *http.Request) []bucketRef {
+	ip := clientIP(r)
+
+	if strings.HasPrefix(r.URL.Path, authPathPrefix) {
+		return []bucketRef{{"auth:ip:" + ip, l.auth}}
+	}
+
+	if subject := l.tokenSubject(r); subject != "" {
+		return []bucketRef{{"user:" + subject, l.user}}
+	}
+
+	refs := []bucketRef{}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		sum := sha256.Sum256([]byte(key))
+		refs = append(refs, bucketRef{"api_key:" + hex.EncodeToString(sum[:]), l.apiKey})
+	}
+
+	return append(refs, bucketRef{"ip:" + ip, l.ip})
+}
+
+// tokenSubject returns the user a valid bearer token was issued to, or the
+// impersonating actor for impersonation tokens, so impersonated requests use
+// the actor's own allowance.
+func (l *RateLimiter) tokenSubject(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+
+	claims, err := utils.ValidateToken(token, l.jwtSecret)
+	if err != nil {
+		return ""
+	}
+
+	if claims.Actor != nil {
+		return claims.Actor.Subject
+	}
+	return claims.Subject
+}
+
+// clientIP returns the address set by the RealIP middleware, without the
+// port when there is one. RealIP only takes it from forwarding headers sent by
+// trusted proxies, so clients cannot pick a new bucket per request.
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"customize_crm/config"
+	"customize_crm/ratelimit"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "test-secret"
+
+func newTestLimiter(t *testing.T) *RateLimiter {
+	t.Helper()
+
+	store := ratelimit.NewMemoryStore()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.Now = func() time.Time { return now }
+
+	return NewRateLimiter(store, config.RateLimitConfig{
+		WindowSeconds:  60,
+		UserRequests:   5,
+		APIKeyRequests: 4,
+		IPRequests:     3,
+		AuthRequests:   2,
+	}, testSecret)
+}
+
+func signedToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	claims["exp"] = time.Now().Add(time.Hour).Unix()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestRateLimiterBuckets(t *testing.T) {
+	l := newTestLimiter(t)
+
+	userToken := signedToken(t, jwt.MapClaims{"sub": "user-1"})
+	impersonationToken := signedToken(t, jwt.MapClaims{"sub": "user-1", "act": map[string]string{"sub": "admin-1"}})
+
+	tests := []struct {
+		name   string
+		path   string
+		header map[string]string
+		want   []string
+	}{
+		{"anonymous", "/api/v1/customers", nil, []string{"ip:192.0.2.1"}},
+		{"auth endpoints", "/api/v1/auth/login", map[string]string{"Authorization": "Bearer " + userToken}, []string{"auth:ip:192.0.2.1"}},
+		{"signed in", "/api/v1/customers", map[string]string{"Authorization": "Bearer " + userToken}, []string{"user:user-1"}},
+		{"impersonating", "/api/v1/customers", map[string]string{"Authorization": "Bearer " + impersonationToken}, []string{"user:admin-1"}},
+		{"invalid token", "/api/v1/customers", map[string]string{"Authorization": "Bearer garbage"}, []string{"ip:192.0.2.1"}},
+		{
+			"api key", "/api/v1/customers", map[string]string{"X-API-Key": "secret"},
+			[]string{"api_key:2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b", "ip:192.0.2.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			r.RemoteAddr = "192.0.2.1:1234"
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+
+			refs := l.buckets(r)
+			if len(refs) != len(tt.want) {
+				t.Fatalf("buckets = %+v, want %v", refs, tt.want)
+			}
+			for i, ref := range refs {
+				if ref.key != tt.want[i] {
+					t.Errorf("bucket %d = %q, want %q", i, ref.key, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterHeaders(t *testing.T) {
+	l := newTestLimiter(t)
+	handler := l.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	send := func(path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		return rec
+	}
+
+	// The auth limit is counted separately from the general IP limit.
+	for remaining := 1; remaining >= 0; remaining-- {
+		rec := send("/api/v1/auth/login")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		if got := rec.Header().Get("RateLimit-Remaining"); got != strconv.Itoa(remaining) {
+			t.Errorf("RateLimit-Remaining = %s, want %d", got, remaining)
+		}
+		if got := rec.Header().Get("RateLimit-Policy"); got != "2;w=60" {
+			t.Errorf("RateLimit-Policy = %s, want 2;w=60", got)
+		}
+	}
+
+	rec := send("/api/v1/auth/login")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %s, want 30", got)
+	}
+
+	rec = send("/api/v1/customers")
+	if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "3" {
+		t.Errorf("general API after auth limit: status %d, limit %s", rec.Code, rec.Header().Get("RateLimit-Limit"))
+	}
+
+	// Paths outside the API are not limited.
+	rec = send("/healthz")
+	if rec.Header().Get("RateLimit-Limit") != "" {
+		t.Error("non-API request carries rate limit headers")
+	}
+}
+
+func TestRateLimiterIgnoresSpoofedForwardedFor(t *testing.T) {
+	l := newTestLimiter(t)
+	proxy := netip.MustParsePrefix("10.0.0.0/8")
+	handler := RealIP([]netip.Prefix{proxy})(l.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	send := func(peer, forwardedFor string) int {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", nil)
+		r.RemoteAddr = peer
+		r.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		return rec.Code
+	}
+
+	// A client connecting directly cannot get a new bucket per request.
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		if got := send("192.0.2.1:1234", "198.51.100."+strconv.Itoa(i)); got != want {
+			t.Errorf("direct request %d: status = %d, want %d", i, got, want)
+		}
+	}
+
+	// Behind the trusted proxy, neither can it by prepending addresses.
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		if got := send("10.0.0.1:1234", "198.51.100."+strconv.Itoa(i)+", 203.0.113.7"); got != want {
+			t.Errorf("proxied request %d: status = %d, want %d", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// RealIP replaces r.RemoteAddr with the client address reported by
+// X-Forwarded-For or X-Real-IP, but only when the connection comes from one of
+// the trusted proxies. Anyone else could put any address in those headers, so
+// their requests keep the socket peer address. With no trusted proxies the
+// headers are ignored altogether.
+func RealIP(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if peer, ok := parseAddr(r.RemoteAddr); ok && isTrusted(trusted, peer) {
+				if ip, ok := forwardedFor(r.Header, trusted); ok {
+					r.RemoteAddr = ip.String()
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedFor returns the client address from the forwarding headers. Proxies
+// append to X-Forwarded-For, so the list is read from the right and the first
+// address that is not a trusted proxy is the one our own proxies saw connect.
+// Addresses further left were supplied by the client and are ignored.
+func forwardedFor(header http.Header, trusted []netip.Prefix) (netip.Addr, bool) {
+	if values := header.Values("X-Forwarded-For"); len(values) > 0 {
+		hops := strings.Split(strings.Join(values, ","), ",")
+
+		var client netip.Addr
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip, ok := parseAddr(strings.TrimSpace(hops[i]))
+			if !ok {
+				break
+			}
+			client = ip
+			if !isTrusted(trusted, ip) {
+				break
+			}
+		}
+		return client, client.IsValid()
+	}
+
+	return parseAddr(strings.TrimSpace(header.Get("X-Real-IP")))
+}
+
+// parseAddr accepts an address with or without a port.
+func parseAddr(s string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+
+	ip, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ip.Unmap(), true
+}
+
+func isTrusted(trusted []netip.Prefix, ip netip.Addr) bool {
+	for _, prefix := range trusted {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
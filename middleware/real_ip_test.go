@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	trusted := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}
+
+	tests := []struct {
+		name   string
+		peer   string
+		header map[string]string
+		want   string
+	}{
+		{"direct", "192.0.2.1:1234", nil, "192.0.2.1:1234"},
+		{"direct with forwarded for", "192.0.2.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "192.0.2.1:1234"},
+		{"direct with real ip", "192.0.2.1:1234", map[string]string{"X-Real-IP": "198.51.100.1"}, "192.0.2.1:1234"},
+		{"proxied", "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "198.51.100.1"},
+		{"proxy chain", "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.1, 10.0.0.2"}, "198.51.100.1"},
+		{"client prepends", "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "203.0.113.9, 198.51.100.1"}, "198.51.100.1"},
+		{"only proxies", "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.2"}, "10.0.0.3"},
+		{"proxied real ip", "10.0.0.1:1234", map[string]string{"X-Real-IP": "198.51.100.1"}, "198.51.100.1"},
+		{"proxied garbage", "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "not-an-ip"}, "10.0.0.1:1234"},
+		{"proxied without headers", "10.0.0.1:1234", nil, "10.0.0.1:1234"},
+		{"ipv6 proxy", "[2001:db8::1]:1234", map[string]string{"X-Forwarded-For": "2001:db9::5"}, "2001:db9::5"},
+		{"ipv4-mapped proxy", "[::ffff:10.0.0.1]:1234", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "198.51.100.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := RealIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.peer
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.want {
+				t.Errorf("RemoteAddr = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRealIPWithoutTrustedProxies(t *testing.T) {
+	var got string
+	handler := RealIP(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.RemoteAddr
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "127.0.0.1:1234"
+	r.Header.Set("X-Forwarded-For", "198.51.100.1")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	if got != "127.0.0.1:1234" {
+		t.Errorf("RemoteAddr = %q, want the socket peer", got)
+	}
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"customize_crm/logging"
+	"customize_crm/utils"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type requestLogKey struct{}
+
+// requestLog collects fields that are only known deeper in the handler chain,
+// such as the authenticated user, for the access log line.
+type requestLog struct {
+	userID  *uuid.UUID
+	actorID *uuid.UUID
+}
+
+// RequestLogger writes one structured line per request with the request ID,
+// route pattern, status, size, latency and, once authenticated, the user.
+// Handlers get a logger carrying the request ID via logging.FromContext.
+// Panics are logged with their stack and answered with 500.
+func RequestLogger(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			requestID := chimiddleware.GetReqID(r.Context())
+
+			// Correlate log lines with the trace started by tracing.Middleware.
+			requestLogger := logger
+			if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+				requestLogger = logger.With("trace_id", sc.TraceID().String())
+			}
+
+			entry := &requestLog{}
+			ctx := context.WithValue(r.Context(), requestLogKey{}, entry)
+			ctx = logging.WithLogger(ctx, requestLogger.With("request_id", requestID))
+			r = r.WithContext(ctx)
+
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			defer func() {
+				if rec := recover(); rec != nil {
+					if rec == http.ErrAbortHandler {
+						panic(rec)
+					}
+
+					logging.FromContext(ctx).Error("panic while serving request",
+						"panic", rec,
+						"stack", string(debug.Stack()),
+					)
+					if ww.Status() == 0 {
+						utils.RespondWithError(ww, r, http.StatusInternalServerError, "Internal server error")
+					}
+				}
+
+				logRequest(ctx, requestLogger, r, ww, entry, requestID, time.Since(start))
+			}()
+
+			next.ServeHTTP(ww, r)
+		})
+	}
+}
+
+func logRequest(ctx context.Context, logger *slog.Logger, r *http.Request, ww chimiddleware.WrapResponseWriter, entry *requestLog, requestID string, latency time.Duration) {
+	status := ww.Status()
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	route := ""
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		route = rctx.RoutePattern()
+	}
+
+	attrs := []slog.Attr{
+		slog.String("request_id", requestID),
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.String("route", route),
+		slog.Int("status", status),
+		slog.Int("bytes", ww.BytesWritten()),
+		slog.Float64("latency_ms", float64(latency.Microseconds())/1000),
+		slog.String("remote_ip", r.RemoteAddr),
+		slog.String("user_agent", r.UserAgent()),
+	}
+	if entry.userID != nil {
+		attrs = append(attrs, slog.String("user_id", entry.userID.String()))
+	}
+	if entry.actorID != nil {
+		attrs = append(attrs, slog.String("actor_id", entry.actorID.String()))
+	}
+	if logger.Enabled(ctx, slog.LevelDebug) {
+		attrs = append(attrs, slog.Any("headers", r.Header))
+	}
+
+	level := slog.LevelInfo
+	if status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+
+	logger.LogAttrs(ctx, level, "request completed", attrs...)
+}
+
+// setRequestUser records the authenticated user, and the impersonating actor
+// if any, for the access log and adds them to the request logger.
+func setRequestUser(ctx context.Context, userID uuid.UUID, actorID *uuid.UUID) context.Context {
+	if entry, ok := ctx.Value(requestLogKey{}).(*requestLog); ok {
+		entry.userID = &userID
+		entry.actorID = actorID
+	}
+
+	if actorID != nil {
+		return logging.With(ctx, "user_id", userID, "actor_id", *actorID)
+	}
+	return logging.With(ctx, "user_id", userID)
+}
@@ -0,0 +1,51 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadOrdersEmbeddedMigrations(t *testing.T) {
+	migrations, err := load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+
+	// Versions start at 1 and leave no gaps, so a missing file is noticed
+	// here rather than when a database skips it.
+	for i, m := range migrations {
+		if m.Version != int64(i+1) {
+			t.Fatalf("migration %d has version %d, want %d", i, m.Version, i+1)
+		}
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			t.Errorf("migration %d_%s has an empty up or down file", m.Version, m.Name)
+		}
+	}
+
+	if first := migrations[0]; first.Name != "initial_schema" {
+		t.Errorf("first migration = %s, want initial_schema", first.Name)
+	}
+
+	m := &Migrator{migrations: migrations}
+	if m.Latest() != migrations[len(migrations)-1].Version {
+		t.Errorf("Latest = %d, want %d", m.Latest(), migrations[len(migrations)-1].Version)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	for name, ok := range map[string]bool{
+		"0001_initial_schema.up.sql": true,
+		"0012_search.down.sql":       true,
+		"0012_search.sql":            false,
+		"search.up.sql":              false,
+		"0012_search.up.sql.orig":    false,
+		"0012_customer-tags.up.sql":  false,
+	} {
+		if got := fileName.MatchString(name); got != ok {
+			t.Errorf("fileName matches %q = %v, want %v", name, got, ok)
+		}
+	}
+}
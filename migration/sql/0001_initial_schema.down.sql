@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS activity_logs;
+DROP TABLE IF EXISTS tasks;
+DROP TABLE IF EXISTS interactions;
+DROP TABLE IF EXISTS opportunity_products;
+DROP TABLE IF EXISTS products;
+DROP TABLE IF EXISTS opportunities;
+DROP TABLE IF EXISTS contacts;
+DROP TABLE IF EXISTS customers;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS roles;
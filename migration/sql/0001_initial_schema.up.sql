@@ -0,0 +1,168 @@
+-- Core CRM schema. IF NOT EXISTS lets databases created before migrations
+-- existed adopt this baseline without changes.
+
+CREATE TABLE IF NOT EXISTS roles (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        VARCHAR(50) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    permissions JSONB NOT NULL DEFAULT '[]',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    username      VARCHAR(50) NOT NULL UNIQUE,
+    email         VARCHAR(255) NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    first_name    VARCHAR(100) NOT NULL,
+    last_name     VARCHAR(100) NOT NULL,
+    role_id       UUID NOT NULL REFERENCES roles (id),
+    department    VARCHAR(100),
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    is_active     BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_role_id ON users (role_id);
+
+CREATE TABLE IF NOT EXISTS customers (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_name    VARCHAR(255) NOT NULL,
+    industry        VARCHAR(100),
+    address         TEXT,
+    city            VARCHAR(100),
+    province        VARCHAR(100),
+    postal_code     VARCHAR(10),
+    phone           VARCHAR(50),
+    website         VARCHAR(255),
+    customer_status VARCHAR(50) NOT NULL DEFAULT 'lead',
+    customer_type   VARCHAR(50),
+    assigned_to     UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by      UUID REFERENCES users (id) ON DELETE SET NULL,
+    notes           TEXT,
+    annual_revenue  NUMERIC(15, 2),
+    tags            TEXT[] NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS idx_customers_assigned_to ON customers (assigned_to);
+CREATE INDEX IF NOT EXISTS idx_customers_created_at ON customers (created_at DESC, id DESC);
+
+CREATE TABLE IF NOT EXISTS contacts (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id UUID NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    first_name  VARCHAR(100) NOT NULL,
+    last_name   VARCHAR(100) NOT NULL,
+    position    VARCHAR(100),
+    email       VARCHAR(255),
+    phone       VARCHAR(50),
+    mobile      VARCHAR(50),
+    is_primary  BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    notes       TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_contacts_customer_id ON contacts (customer_id);
+
+CREATE TABLE IF NOT EXISTS opportunities (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name                VARCHAR(255) NOT NULL,
+    customer_id         UUID NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    contact_id          UUID REFERENCES contacts (id) ON DELETE SET NULL,
+    amount              NUMERIC(15, 2),
+    stage               VARCHAR(50) NOT NULL,
+    probability         INTEGER CHECK (probability BETWEEN 0 AND 100),
+    expected_close_date DATE,
+    assigned_to         UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by          UUID REFERENCES users (id) ON DELETE SET NULL,
+    source              VARCHAR(100),
+    description         TEXT,
+    status              VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'won', 'lost')),
+    reason_lost         TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_opportunities_customer_id ON opportunities (customer_id);
+CREATE INDEX IF NOT EXISTS idx_opportunities_assigned_to ON opportunities (assigned_to);
+CREATE INDEX IF NOT EXISTS idx_opportunities_created_at ON opportunities (created_at DESC, id DESC);
+
+CREATE TABLE IF NOT EXISTS products (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        VARCHAR(255) NOT NULL,
+    description TEXT,
+    sku         VARCHAR(100) UNIQUE,
+    unit_price  NUMERIC(15, 2) NOT NULL,
+    category    VARCHAR(100),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    is_active   BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE TABLE IF NOT EXISTS opportunity_products (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    opportunity_id UUID NOT NULL REFERENCES opportunities (id) ON DELETE CASCADE,
+    product_id     UUID NOT NULL REFERENCES products (id),
+    quantity       INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+    unit_price     NUMERIC(15, 2) NOT NULL,
+    discount       NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    total          NUMERIC(15, 2) NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_opportunity_products_opportunity_id ON opportunity_products (opportunity_id);
+
+CREATE TABLE IF NOT EXISTS interactions (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id      UUID NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    contact_id       UUID REFERENCES contacts (id) ON DELETE SET NULL,
+    user_id          UUID NOT NULL REFERENCES users (id),
+    interaction_type VARCHAR(50) NOT NULL,
+    subject          VARCHAR(255) NOT NULL,
+    description      TEXT,
+    interaction_date TIMESTAMPTZ NOT NULL,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    opportunity_id   UUID REFERENCES opportunities (id) ON DELETE SET NULL,
+    follow_up_date   TIMESTAMPTZ,
+    status           VARCHAR(50)
+);
+
+CREATE INDEX IF NOT EXISTS idx_interactions_customer_id ON interactions (customer_id);
+
+CREATE TABLE IF NOT EXISTS tasks (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    title          VARCHAR(255) NOT NULL,
+    description    TEXT,
+    due_date       TIMESTAMPTZ NOT NULL,
+    priority       VARCHAR(20) NOT NULL DEFAULT 'medium',
+    status         VARCHAR(20) NOT NULL DEFAULT 'pending',
+    assigned_to    UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_by     UUID REFERENCES users (id) ON DELETE SET NULL,
+    customer_id    UUID REFERENCES customers (id) ON DELETE SET NULL,
+    opportunity_id UUID REFERENCES opportunities (id) ON DELETE SET NULL,
+    contact_id     UUID REFERENCES contacts (id) ON DELETE SET NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_assigned_to ON tasks (assigned_to);
+CREATE INDEX IF NOT EXISTS idx_tasks_created_at ON tasks (created_at DESC, id DESC);
+
+CREATE TABLE IF NOT EXISTS activity_logs (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       UUID REFERENCES users (id) ON DELETE SET NULL,
+    activity_type VARCHAR(50) NOT NULL,
+    entity_type   VARCHAR(50) NOT NULL,
+    entity_id     UUID NOT NULL,
+    description   TEXT NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    metadata      JSONB
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_logs_entity ON activity_logs (entity_type, entity_id);
@@ -0,0 +1,7 @@
+-- Matches the expression searched by UserService.List.
+CREATE INDEX IF NOT EXISTS idx_users_search ON users USING GIN (
+    to_tsvector('simple', first_name || ' ' || last_name || ' ' || username || ' ' || email)
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_users_department ON users (department);
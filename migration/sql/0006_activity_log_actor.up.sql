@@ -0,0 +1,4 @@
+-- Set when an admin acts through an impersonation token.
+ALTER TABLE activity_logs ADD COLUMN IF NOT EXISTS actor_id UUID REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_activity_logs_actor_id ON activity_logs (actor_id) WHERE actor_id IS NOT NULL;
@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS manager_id UUID REFERENCES users (id) ON DELETE SET NULL;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_manager_not_self;
+ALTER TABLE users ADD CONSTRAINT users_manager_not_self CHECK (manager_id <> id);
+
+CREATE INDEX IF NOT EXISTS idx_users_manager_id ON users (manager_id);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS teams (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id    UUID NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role       VARCHAR(20) NOT NULL DEFAULT 'member' CHECK (role IN ('lead', 'member')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_members_user_id ON team_members (user_id);
+
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS team_id UUID REFERENCES teams (id) ON DELETE SET NULL;
+ALTER TABLE opportunities ADD COLUMN IF NOT EXISTS team_id UUID REFERENCES teams (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_customers_team_id ON customers (team_id);
+CREATE INDEX IF NOT EXISTS idx_opportunities_team_id ON opportunities (team_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS record_reassignments;
+DROP TABLE IF EXISTS user_delegations;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS user_delegations (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    delegator_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    delegate_id  UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    starts_at    TIMESTAMPTZ NOT NULL,
+    ends_at      TIMESTAMPTZ NOT NULL,
+    reason       TEXT,
+    created_by   UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at   TIMESTAMPTZ,
+    CHECK (ends_at > starts_at),
+    CHECK (delegate_id <> delegator_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_delegations_delegate_id ON user_delegations (delegate_id) WHERE revoked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_user_delegations_delegator_id ON user_delegations (delegator_id);
+
+CREATE TABLE IF NOT EXISTS record_reassignments (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    from_user_id    UUID NOT NULL REFERENCES users (id),
+    to_user_id      UUID NOT NULL REFERENCES users (id),
+    performed_by    UUID NOT NULL REFERENCES users (id),
+    filter          JSONB NOT NULL,
+    customer_ids    UUID[] NOT NULL DEFAULT '{}',
+    opportunity_ids UUID[] NOT NULL DEFAULT '{}',
+    task_ids        UUID[] NOT NULL DEFAULT '{}',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_tasks_search_trgm;
+DROP INDEX IF EXISTS idx_tasks_search_fts;
+DROP INDEX IF EXISTS idx_products_search_trgm;
+DROP INDEX IF EXISTS idx_products_search_fts;
+DROP INDEX IF EXISTS idx_opportunities_search_trgm;
+DROP INDEX IF EXISTS idx_opportunities_search_fts;
+DROP INDEX IF EXISTS idx_contacts_search_trgm;
+DROP INDEX IF EXISTS idx_contacts_search_fts;
+DROP INDEX IF EXISTS idx_customers_search_trgm;
+DROP INDEX IF EXISTS idx_customers_search_fts;
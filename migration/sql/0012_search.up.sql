@@ -0,0 +1,38 @@
+-- Full-text and trigram indexes for /api/v1/search. The expressions must match
+-- the searched expressions in the postgres SearchRepository exactly.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_customers_search_fts ON customers USING GIN (
+    to_tsvector('simple', company_name)
+);
+CREATE INDEX IF NOT EXISTS idx_customers_search_trgm ON customers USING GIN (
+    company_name gin_trgm_ops
+);
+
+CREATE INDEX IF NOT EXISTS idx_contacts_search_fts ON contacts USING GIN (
+    to_tsvector('simple', first_name || ' ' || last_name || ' ' || coalesce(email, '') || ' ' || coalesce(phone, '') || ' ' || coalesce(mobile, ''))
+);
+CREATE INDEX IF NOT EXISTS idx_contacts_search_trgm ON contacts USING GIN (
+    (first_name || ' ' || last_name || ' ' || coalesce(email, '') || ' ' || coalesce(phone, '') || ' ' || coalesce(mobile, '')) gin_trgm_ops
+);
+
+CREATE INDEX IF NOT EXISTS idx_opportunities_search_fts ON opportunities USING GIN (
+    to_tsvector('simple', name)
+);
+CREATE INDEX IF NOT EXISTS idx_opportunities_search_trgm ON opportunities USING GIN (
+    name gin_trgm_ops
+);
+
+CREATE INDEX IF NOT EXISTS idx_products_search_fts ON products USING GIN (
+    to_tsvector('simple', name || ' ' || coalesce(sku, ''))
+);
+CREATE INDEX IF NOT EXISTS idx_products_search_trgm ON products USING GIN (
+    (name || ' ' || coalesce(sku, '')) gin_trgm_ops
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_search_fts ON tasks USING GIN (
+    to_tsvector('simple', title)
+);
+CREATE INDEX IF NOT EXISTS idx_tasks_search_trgm ON tasks USING GIN (
+    title gin_trgm_ops
+);
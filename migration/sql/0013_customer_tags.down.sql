@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_customers_tags;
+DROP TABLE IF EXISTS tags;
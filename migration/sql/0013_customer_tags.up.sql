@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name       VARCHAR(100) NOT NULL UNIQUE,
+    color      CHAR(7) NOT NULL DEFAULT '#9E9E9E',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Adopt the tags already in use into the vocabulary.
+INSERT INTO tags (name)
+SELECT DISTINCT tag FROM customers, unnest(tags) AS tag
+WHERE tag <> ''
+ON CONFLICT (name) DO NOTHING;
+
+-- Serves the tags any-of (&&), all-of (@>) and contains filters.
+CREATE INDEX IF NOT EXISTS idx_customers_tags ON customers USING GIN (tags);
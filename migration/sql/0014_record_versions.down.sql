@@ -0,0 +1,3 @@
+ALTER TABLE user_invitations DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE user_delegations DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE user_passkeys DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,9 @@
+-- Passkeys, delegations and invitations get an updated_at so their changes
+-- can be guarded with ETags like the other records.
+ALTER TABLE user_passkeys ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE user_delegations ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE user_invitations ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE user_passkeys SET updated_at = COALESCE(last_used_at, created_at);
+UPDATE user_delegations SET updated_at = COALESCE(revoked_at, created_at);
+UPDATE user_invitations SET updated_at = COALESCE(accepted_at, revoked_at, created_at);
@@ -0,0 +1,6 @@
+-- Fails while a deleted user shares a username or email with another user.
+DROP INDEX IF EXISTS users_email_key;
+DROP INDEX IF EXISTS users_username_key;
+
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
@@ -0,0 +1,8 @@
+-- Usernames and emails only have to be unique among users that are not
+-- deleted, so a deleted user can be invited or created again. The indexes
+-- keep the constraint names, which API errors use to name the field.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_key ON users (username) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users (email) WHERE deleted_at IS NULL;
@@ -7,9 +7,16 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	ActivityImpersonationStarted = "impersonation_started"
+	ActivityImpersonatedRequest  = "impersonated_request"
+	ActivityRecordsReassigned    = "records_reassigned"
+)
+
 type ActivityLog struct {
 	ID           uuid.UUID       `json:"id"`
 	UserID       *uuid.UUID      `json:"user_id,omitempty"`
+	ActorID      *uuid.UUID      `json:"actor_id,omitempty"`
 	ActivityType string          `json:"activity_type"`
 	EntityType   string          `json:"entity_type"`
 	EntityID     uuid.UUID       `json:"entity_id"`
@@ -1,8 +1,8 @@
 package model
 
 type LoginRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
 
 type LoginResponse struct {
@@ -15,7 +15,7 @@ type LoginResponse struct {
 }
 
 type RefreshTokenRequest struct {
-	RefreshToken string `json:"refresh_token"`
+	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
 type RefreshTokenResponse struct {
@@ -25,14 +25,26 @@ type RefreshTokenResponse struct {
 }
 
 type ForgotPasswordRequest struct {
-	Email string `json:"email"`
+	Email string `json:"email" validate:"required,email"`
 }
 
 type ResetPasswordRequest struct {
-	Token       string `json:"token"`
-	NewPassword string `json:"new_password"`
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8,max=72"`
 }
 
 type MessageResponse struct {
 	Message string `json:"message"`
 }
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+type ImpersonationResponse struct {
+	AccessToken string `json:"access_token"`
+	UserID      string `json:"user_id"`
+	ActorID     string `json:"actor_id"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
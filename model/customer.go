@@ -19,10 +19,20 @@ type Customer struct {
 	CustomerStatus string     `json:"customer_status"`
 	CustomerType   *string    `json:"customer_type,omitempty"`
 	AssignedTo     *uuid.UUID `json:"assigned_to,omitempty"`
+	TeamID         *uuid.UUID `json:"team_id,omitempty"`
 	CreatedAt      time.Time  `json:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at"`
 	CreatedBy      *uuid.UUID `json:"created_by,omitempty"`
 	Notes          *string    `json:"notes,omitempty"`
 	AnnualRevenue  *float64   `json:"annual_revenue,omitempty"`
 	Tags           []string   `json:"tags,omitempty"`
+
+	// Included on request.
+	Contacts      []*Contact     `json:"contacts,omitempty"`
+	Opportunities []*Opportunity `json:"opportunities,omitempty"`
+}
+
+type CustomerListResponse struct {
+	Data       []*Customer `json:"data"`
+	Pagination Pagination  `json:"pagination"`
 }
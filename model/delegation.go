@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Delegation struct {
+	ID          uuid.UUID  `json:"id"`
+	DelegatorID uuid.UUID  `json:"delegator_id"`
+	DelegateID  uuid.UUID  `json:"delegate_id"`
+	StartsAt    time.Time  `json:"starts_at"`
+	EndsAt      time.Time  `json:"ends_at"`
+	Reason      *string    `json:"reason,omitempty"`
+	CreatedBy   uuid.UUID  `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreateDelegationRequest hands the delegator's records to the delegate for
+// the given window. DelegatorID defaults to the caller; only admins may set it.
+type CreateDelegationRequest struct {
+	DelegatorID *uuid.UUID `json:"delegator_id,omitempty"`
+	DelegateID  uuid.UUID  `json:"delegate_id" validate:"required"`
+	StartsAt    *time.Time `json:"starts_at,omitempty"`
+	EndsAt      time.Time  `json:"ends_at" validate:"required"`
+	Reason      *string    `json:"reason,omitempty"`
+}
@@ -0,0 +1,12 @@
+package model
+
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// ReadinessResponse lists the result of every readiness check; Status is
+// "ready" only when all of them pass.
+type ReadinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
@@ -6,6 +6,12 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	OpportunityStatusOpen = "open"
+	OpportunityStatusWon  = "won"
+	OpportunityStatusLost = "lost"
+)
+
 type Opportunity struct {
 	ID                uuid.UUID  `json:"id"`
 	Name              string     `json:"name"`
@@ -16,6 +22,7 @@ type Opportunity struct {
 	Probability       *int       `json:"probability,omitempty"`
 	ExpectedCloseDate *time.Time `json:"expected_close_date,omitempty"`
 	AssignedTo        *uuid.UUID `json:"assigned_to,omitempty"`
+	TeamID            *uuid.UUID `json:"team_id,omitempty"`
 	CreatedAt         time.Time  `json:"created_at"`
 	UpdatedAt         time.Time  `json:"updated_at"`
 	CreatedBy         *uuid.UUID `json:"created_by,omitempty"`
@@ -23,4 +30,12 @@ type Opportunity struct {
 	Description       *string    `json:"description,omitempty"`
 	Status            string     `json:"status"`
 	ReasonLost        *string    `json:"reason_lost,omitempty"`
+
+	// Included on request.
+	Customer *Customer `json:"customer,omitempty"`
+}
+
+type OpportunityListResponse struct {
+	Data       []*Opportunity `json:"data"`
+	Pagination Pagination     `json:"pagination"`
 }
@@ -0,0 +1,57 @@
+package model
+
+import "encoding/json"
+
+// Optional is a PATCH field that tells an absent key apart from an explicit
+// null. Set is false when the key was absent; Null is true when it was null.
+type Optional[T any] struct {
+	Value T
+	Set   bool
+	Null  bool
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		var zero T
+		o.Value, o.Null = zero, true
+		return nil
+	}
+	return json.Unmarshal(data, &o.Value)
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Set || o.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+// IsSet reports whether the key was present.
+func (o Optional[T]) IsSet() bool {
+	return o.Set
+}
+
+// IsNull reports whether the key was present with a null value.
+func (o Optional[T]) IsNull() bool {
+	return o.Null
+}
+
+// Apply copies a non-null value into dst and leaves it alone otherwise.
+func (o Optional[T]) Apply(dst *T) {
+	if o.Set && !o.Null {
+		*dst = o.Value
+	}
+}
+
+// ApplyPtr copies the value into a nullable dst, clearing it on null.
+func (o Optional[T]) ApplyPtr(dst **T) {
+	switch {
+	case !o.Set:
+	case o.Null:
+		*dst = nil
+	default:
+		v := o.Value
+		*dst = &v
+	}
+}
@@ -0,0 +1,8 @@
+package model
+
+type Pagination struct {
+	Limit      int    `json:"limit"`
+	Total      int64  `json:"total"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Passkey struct {
+	ID           uuid.UUID  `json:"id"`
+	UserID       uuid.UUID  `json:"user_id"`
+	CredentialID []byte     `json:"credential_id"`
+	Name         string     `json:"name"`
+	Credential   []byte     `json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+}
+
+type PasskeyBeginResponse struct {
+	SessionID uuid.UUID   `json:"session_id"`
+	Options   interface{} `json:"options"`
+}
+
+type PasskeyRegisterFinishRequest struct {
+	SessionID  uuid.UUID       `json:"session_id" validate:"required"`
+	Name       string          `json:"name" validate:"max=100"`
+	Credential json.RawMessage `json:"credential" swaggertype:"object" validate:"required"`
+}
+
+type PasskeyLoginBeginRequest struct {
+	Username string `json:"username,omitempty"`
+}
+
+type PasskeyLoginFinishRequest struct {
+	SessionID  uuid.UUID       `json:"session_id" validate:"required"`
+	Credential json.RawMessage `json:"credential" swaggertype:"object" validate:"required"`
+}
+
+type RenamePasskeyRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
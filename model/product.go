@@ -17,3 +17,8 @@ type Product struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 	IsActive    bool      `json:"is_active"`
 }
+
+type ProductListResponse struct {
+	Data       []*Product `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
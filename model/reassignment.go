@@ -0,0 +1,48 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EntityCustomers     = "customers"
+	EntityOpportunities = "opportunities"
+	EntityTasks         = "tasks"
+)
+
+// ReassignmentFilter selects the records to move away from FromUserID. An
+// empty EntityTypes means customers, opportunities and tasks. OpenOnly, which
+// defaults to true, skips closed opportunities and completed tasks.
+type ReassignmentFilter struct {
+	FromUserID       uuid.UUID  `json:"from_user_id" validate:"required"`
+	EntityTypes      []string   `json:"entity_types,omitempty" validate:"dive,oneof=customers opportunities tasks"`
+	OpenOnly         *bool      `json:"open_only,omitempty"`
+	CustomerStatus   *string    `json:"customer_status,omitempty"`
+	OpportunityStage *string    `json:"opportunity_stage,omitempty"`
+	TaskStatus       *string    `json:"task_status,omitempty"`
+	DueBefore        *time.Time `json:"due_before,omitempty"`
+}
+
+type ReassignmentRequest struct {
+	ReassignmentFilter
+	ToUserID uuid.UUID `json:"to_user_id" validate:"required"`
+	DryRun   bool      `json:"dry_run"`
+}
+
+// Reassignment is the audit record of a bulk reassignment. Dry runs are
+// returned with the same shape but are never stored.
+type Reassignment struct {
+	ID             uuid.UUID       `json:"id"`
+	FromUserID     uuid.UUID       `json:"from_user_id"`
+	ToUserID       uuid.UUID       `json:"to_user_id"`
+	PerformedBy    uuid.UUID       `json:"performed_by"`
+	Filter         json.RawMessage `json:"filter" swaggertype:"object"`
+	CustomerIDs    []uuid.UUID     `json:"customer_ids"`
+	OpportunityIDs []uuid.UUID     `json:"opportunity_ids"`
+	TaskIDs        []uuid.UUID     `json:"task_ids"`
+	DryRun         bool            `json:"dry_run"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package model
+
+type PipelineStageSummary struct {
+	Stage          string  `json:"stage"`
+	Count          int64   `json:"count"`
+	TotalAmount    float64 `json:"total_amount"`
+	WeightedAmount float64 `json:"weighted_amount"`
+}
+
+type TaskStatusSummary struct {
+	Status  string `json:"status"`
+	Count   int64  `json:"count"`
+	Overdue int64  `json:"overdue"`
+}
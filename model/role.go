@@ -7,11 +7,35 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	PermissionImpersonateUsers = "users.impersonate"
+)
+
 type Role struct {
 	ID          uuid.UUID       `json:"id"`
 	Name        string          `json:"name"`
 	Description string          `json:"description,omitempty"`
-	Permissions json.RawMessage `json:"permissions,omitempty"`
+	Permissions json.RawMessage `json:"permissions,omitempty" swaggertype:"object"`
 	CreatedAt   time.Time       `json:"created_at"`
 	UpdatedAt   time.Time       `json:"updated_at"`
 }
+
+// PermissionList returns the permissions granted by the role. Permissions may
+// be stored either as a JSON array of names or as an object of name to bool.
+func (r *Role) PermissionList() []string {
+	var list []string
+	if err := json.Unmarshal(r.Permissions, &list); err == nil {
+		return list
+	}
+
+	var set map[string]bool
+	if err := json.Unmarshal(r.Permissions, &set); err == nil {
+		for name, granted := range set {
+			if granted {
+				list = append(list, name)
+			}
+		}
+	}
+
+	return list
+}
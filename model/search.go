@@ -0,0 +1,44 @@
+package model
+
+import "github.com/google/uuid"
+
+// Kinds of record returned by the global search.
+const (
+	SearchTypeCustomer    = "customer"
+	SearchTypeContact     = "contact"
+	SearchTypeOpportunity = "opportunity"
+	SearchTypeProduct     = "product"
+	SearchTypeTask        = "task"
+)
+
+var SearchTypes = []string{
+	SearchTypeCustomer,
+	SearchTypeContact,
+	SearchTypeOpportunity,
+	SearchTypeProduct,
+	SearchTypeTask,
+}
+
+type SearchParams struct {
+	Query string
+	// Types restricts the search to these kinds of record; empty means all.
+	Types []string
+	Limit int
+}
+
+// SearchResult is one matching record. Highlight is the matched text with the
+// matching terms wrapped in <mark> tags; everything else in it is HTML-escaped.
+type SearchResult struct {
+	Type       string     `json:"type"`
+	ID         uuid.UUID  `json:"id"`
+	Title      string     `json:"title"`
+	Subtitle   *string    `json:"subtitle,omitempty"`
+	CustomerID *uuid.UUID `json:"customer_id,omitempty"`
+	// HTML-escaped matched text with the matching terms wrapped in <mark> tags
+	Highlight string  `json:"highlight"`
+	Score     float64 `json:"score"`
+}
+
+type SearchResponse struct {
+	Data []*SearchResult `json:"data"`
+}
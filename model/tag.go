@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tag is an entry of the customer tag vocabulary. Customers store tags by
+// name, so renaming, merging or deleting a tag rewrites the customers using it.
+type Tag struct {
+	ID            uuid.UUID `json:"id"`
+	Name          string    `json:"name"`
+	Color         string    `json:"color"`
+	CustomerCount int64     `json:"customer_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// CustomerTagsRequest adds and removes tags on many customers at once. Added
+// tags must exist in the vocabulary.
+type CustomerTagsRequest struct {
+	CustomerIDs []uuid.UUID `json:"customer_ids" validate:"required,max=1000"`
+	Add         []string    `json:"add"`
+	Remove      []string    `json:"remove"`
+}
+
+type CustomerTagsResult struct {
+	Updated int64 `json:"updated"`
+}
@@ -21,4 +21,13 @@ type Task struct {
 	CreatedAt     time.Time  `json:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at"`
 	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+
+	// Included on request.
+	Customer    *Customer    `json:"customer,omitempty"`
+	Opportunity *Opportunity `json:"opportunity,omitempty"`
+}
+
+type TaskListResponse struct {
+	Data       []*Task    `json:"data"`
+	Pagination Pagination `json:"pagination"`
 }
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TeamRoleLead   = "lead"
+	TeamRoleMember = "member"
+)
+
+type Team struct {
+	ID          uuid.UUID     `json:"id"`
+	Name        string        `json:"name"`
+	Description *string       `json:"description,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	Members     []*TeamMember `json:"members,omitempty"`
+}
+
+type TeamMember struct {
+	TeamID    uuid.UUID `json:"team_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Role      string    `json:"role"`
+	Username  string    `json:"username"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+type TeamRecordsRequest struct {
+	CustomerIDs    []uuid.UUID `json:"customer_ids"`
+	OpportunityIDs []uuid.UUID `json:"opportunity_ids"`
+}
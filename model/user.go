@@ -7,15 +7,36 @@ import (
 )
 
 type User struct {
-	ID           uuid.UUID `json:"id"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"`
-	FirstName    string    `json:"first_name"`
-	LastName     string    `json:"last_name"`
-	RoleID       uuid.UUID `json:"role_id"`
-	Department   *string   `json:"department,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	IsActive     bool      `json:"is_active"`
+	ID           uuid.UUID  `json:"id"`
+	Username     string     `json:"username"`
+	Email        string     `json:"email"`
+	PasswordHash string     `json:"-"`
+	FirstName    string     `json:"first_name"`
+	LastName     string     `json:"last_name"`
+	RoleID       uuid.UUID  `json:"role_id"`
+	Department   *string    `json:"department,omitempty"`
+	ManagerID    *uuid.UUID `json:"manager_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	IsActive     bool       `json:"is_active"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+
+	// Included on request.
+	Role    *Role `json:"role,omitempty"`
+	Manager *User `json:"manager,omitempty"`
+}
+
+type UserListResponse struct {
+	Data       []*User    `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+type OrgChartNode struct {
+	ID         uuid.UUID       `json:"id"`
+	Username   string          `json:"username"`
+	FirstName  string          `json:"first_name"`
+	LastName   string          `json:"last_name"`
+	Department *string         `json:"department,omitempty"`
+	ManagerID  *uuid.UUID      `json:"manager_id,omitempty"`
+	Reports    []*OrgChartNode `json:"reports"`
 }
@@ -0,0 +1,11 @@
+package model
+
+import "github.com/google/uuid"
+
+// VisibilityScope describes which owners' records a user may see. All is set
+// for admins; otherwise UserIDs holds the user, anyone currently delegating to
+// them, and everyone reporting to those users, directly or indirectly.
+type VisibilityScope struct {
+	All     bool
+	UserIDs []uuid.UUID
+}
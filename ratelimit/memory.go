@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	window  time.Duration
+}
+
+// MemoryStore keeps buckets in process memory. Limits are per instance.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+
+	// Now returns the current time; tests may replace it.
+	Now func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*bucket{},
+		Now:     time.Now,
+	}
+}
+
+// Take
+func (s *MemoryStore) Take(ctx context.Context, key string, policy Policy) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.Now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(policy.Limit), updated: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(policy.Limit), b.tokens+now.Sub(b.updated).Seconds()*policy.rate())
+	b.updated = now
+	b.window = policy.Window
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	return result(policy, b.tokens, allowed), nil
+}
+
+// sweep drops buckets idle for longer than their window, which have refilled
+// completely and are no different from a new bucket. It runs at most once a
+// minute.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) >= b.window {
+			delete(s.buckets, key)
+		}
+	}
+}
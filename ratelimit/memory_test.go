@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.Now = func() time.Time { return now }
+
+	ctx := context.Background()
+	policy := Policy{Name: "test", Limit: 3, Window: 3 * time.Second} // one token a second
+
+	take := func() Result {
+		t.Helper()
+		res, err := store.Take(ctx, "key", policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	// A new bucket starts full and allows a burst of Limit requests.
+	for want := 2; want >= 0; want-- {
+		res := take()
+		if !res.Allowed || res.Remaining != want || res.Limit != 3 {
+			t.Fatalf("burst: %+v, want allowed with %d remaining", res, want)
+		}
+	}
+
+	res := take()
+	if res.Allowed || res.Remaining != 0 {
+		t.Fatalf("empty bucket: %+v, want denied", res)
+	}
+	if res.RetryAfter != time.Second || res.Reset != 3*time.Second {
+		t.Errorf("empty bucket: retry after %s, reset %s, want 1s and 3s", res.RetryAfter, res.Reset)
+	}
+
+	// Half a token is not enough.
+	now = now.Add(500 * time.Millisecond)
+	if res := take(); res.Allowed || res.RetryAfter != 500*time.Millisecond {
+		t.Errorf("after 500ms: %+v, want denied for another 500ms", res)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if res := take(); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("after 1s: %+v, want one request allowed", res)
+	}
+
+	// Refilling stops at Limit however long the bucket was idle.
+	now = now.Add(time.Hour)
+	if res := take(); !res.Allowed || res.Remaining != 2 {
+		t.Errorf("after an hour: %+v, want a full bucket", res)
+	}
+
+	// Buckets are independent.
+	other, err := store.Take(ctx, "other", policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !other.Allowed || other.Remaining != 2 {
+		t.Errorf("other key: %+v, want a full bucket", other)
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.Now = func() time.Time { return now }
+
+	ctx := context.Background()
+	short := Policy{Name: "short", Limit: 1, Window: time.Minute}
+	long := Policy{Name: "long", Limit: 1, Window: time.Hour}
+
+	for key, policy := range map[string]Policy{"short": short, "long": long} {
+		if _, err := store.Take(ctx, key, policy); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := store.Take(ctx, "trigger", short); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := store.buckets["short"]; ok {
+		t.Error("idle bucket outlived its window")
+	}
+	if _, ok := store.buckets["long"]; !ok {
+		t.Error("bucket swept before its window passed")
+	}
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"sync/atomic"
+
+	"customize_crm/logging"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// pruneEvery is how many Take calls pass between deletions of idle buckets.
+const pruneEvery = 1000
+
+// PostgresStore keeps buckets in the rate_limit_buckets table so every
+// replica shares them. Refill is computed with the database clock, and the
+// upsert locks the row, so concurrent requests cannot overspend a bucket.
+type PostgresStore struct {
+	db    *pgxpool.Pool
+	calls atomic.Uint64
+}
+
+func NewPostgresStore(db *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Take
+func (s *PostgresStore) Take(ctx context.Context, key string, policy Policy) (Result, error) {
+	// The clock is read once, for the inserted row, and the update reuses it
+	// through EXCLUDED. clock_timestamp is used rather than CURRENT_TIMESTAMP,
+	// the transaction start, which is earlier than updated_at when the request
+	// waited on the row lock of a concurrent one. Every SET expression sees
+	// the row as it was before the update, so the refilled token count is
+	// computed the same way in each.
+	query := `
+		INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, expires_at, updated_at)
+		SELECT $1, $2::float8 - 1, true, clock.t + make_interval(secs => $4), clock.t
+		FROM (SELECT clock_timestamp() AS t) clock
+		ON CONFLICT (key) DO UPDATE SET
+			tokens = LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM EXCLUDED.updated_at - b.updated_at)::float8, 0) * $3::float8)
+				- CASE WHEN LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM EXCLUDED.updated_at - b.updated_at)::float8, 0) * $3::float8) >= 1 THEN 1 ELSE 0 END,
+			allowed = LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM EXCLUDED.updated_at - b.updated_at)::float8, 0) * $3::float8) >= 1,
+			expires_at = EXCLUDED.expires_at,
+			updated_at = GREATEST(b.updated_at, EXCLUDED.updated_at)
+		RETURNING b.tokens, b.allowed
+	`
+
+	var tokens float64
+	var allowed bool
+	err := s.db.QueryRow(ctx, query, key, policy.Limit, policy.rate(), policy.Window.Seconds()).Scan(&tokens, &allowed)
+	if err != nil {
+		return Result{}, err
+	}
+
+	// The token is already spent, so a failed prune must not fail the request.
+	if s.calls.Add(1)%pruneEvery == 0 {
+		if err := s.prune(ctx); err != nil {
+			logging.FromContext(ctx).Error("rate limit bucket prune failed", "error", err)
+		}
+	}
+
+	return result(policy, tokens, allowed), nil
+}
+
+// prune deletes buckets that have refilled completely.
+func (s *PostgresStore) prune(ctx context.Context) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM rate_limit_buckets WHERE expires_at < CURRENT_TIMESTAMP`)
+	return err
+}
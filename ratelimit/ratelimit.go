@@ -0,0 +1,59 @@
+// Package ratelimit implements token-bucket rate limiting with pluggable
+// bucket storage: in memory for a single instance, or in PostgreSQL so the
+// limits hold across replicas.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Policy allows Limit requests per Window. Buckets start full and refill
+// continuously, so short bursts of up to Limit requests are allowed.
+type Policy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// rate returns the refill rate in tokens per second.
+func (p Policy) rate() float64 {
+	return float64(p.Limit) / p.Window.Seconds()
+}
+
+// Result describes the bucket after a request was counted against it.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request would be allowed; it is
+	// zero when Allowed is true.
+	RetryAfter time.Duration
+}
+
+// Store takes one token from the bucket identified by key.
+type Store interface {
+	Take(ctx context.Context, key string, policy Policy) (Result, error)
+}
+
+// result builds the Result for a bucket left with tokens.
+func result(policy Policy, tokens float64, allowed bool) Result {
+	rate := policy.rate()
+	res := Result{
+		Allowed:   allowed,
+		Limit:     policy.Limit,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     seconds((float64(policy.Limit) - tokens) / rate),
+	}
+	if !allowed {
+		res.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return res
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Max(0, s) * float64(time.Second))
+}
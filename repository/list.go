@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"time"
+
+	"customize_crm/listquery"
+
+	"github.com/google/uuid"
+)
+
+type PageParams struct {
+	Limit      int
+	Cursor     string
+	AssignedTo *uuid.UUID
+	Query      listquery.Query
+}
+
+type UserListParams struct {
+	Limit       int
+	Cursor      string
+	RoleID      *uuid.UUID
+	RoleName    string
+	Department  string
+	IsActive    *bool
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Search      string
+	Deleted     bool
+	Query       listquery.Query
+}
+
+// CustomerListSchema is the allow-list of the customer list query.
+var CustomerListSchema = &listquery.Schema{
+	Fields: map[string]listquery.Field{
+		"id":              {Column: "id", Type: listquery.UUID},
+		"company_name":    {Column: "company_name", Type: listquery.String, Sortable: true},
+		"industry":        {Column: "industry", Type: listquery.String},
+		"address":         {Column: "address", Type: listquery.String},
+		"city":            {Column: "city", Type: listquery.String},
+		"province":        {Column: "province", Type: listquery.String},
+		"postal_code":     {Column: "postal_code", Type: listquery.String},
+		"phone":           {Column: "phone", Type: listquery.String},
+		"website":         {Column: "website", Type: listquery.String},
+		"customer_status": {Column: "customer_status", Type: listquery.String, Sortable: true},
+		"customer_type":   {Column: "customer_type", Type: listquery.String},
+		"assigned_to":     {Column: "assigned_to", Type: listquery.UUID},
+		"team_id":         {Column: "team_id", Type: listquery.UUID},
+		"created_at":      {Column: "created_at", Type: listquery.Time, Sortable: true},
+		"updated_at":      {Column: "updated_at", Type: listquery.Time, Sortable: true},
+		"created_by":      {Column: "created_by", Type: listquery.UUID},
+		"notes":           {Column: "notes", Type: listquery.String},
+		"annual_revenue":  {Column: "annual_revenue", Type: listquery.Number},
+		"tags":            {Column: "tags", Type: listquery.Strings},
+	},
+	Includes: []string{"contacts", "opportunities"},
+}
+
+// OpportunityListSchema is the allow-list of the opportunity list query.
+var OpportunityListSchema = &listquery.Schema{
+	Fields: map[string]listquery.Field{
+		"id":                  {Column: "id", Type: listquery.UUID},
+		"name":                {Column: "name", Type: listquery.String, Sortable: true},
+		"customer_id":         {Column: "customer_id", Type: listquery.UUID},
+		"contact_id":          {Column: "contact_id", Type: listquery.UUID},
+		"amount":              {Column: "amount", Type: listquery.Number},
+		"stage":               {Column: "stage", Type: listquery.String, Sortable: true},
+		"probability":         {Column: "probability", Type: listquery.Number},
+		"expected_close_date": {Column: "expected_close_date", Type: listquery.Time},
+		"assigned_to":         {Column: "assigned_to", Type: listquery.UUID},
+		"team_id":             {Column: "team_id", Type: listquery.UUID},
+		"created_at":          {Column: "created_at", Type: listquery.Time, Sortable: true},
+		"updated_at":          {Column: "updated_at", Type: listquery.Time, Sortable: true},
+		"created_by":          {Column: "created_by", Type: listquery.UUID},
+		"source":              {Column: "source", Type: listquery.String},
+		"description":         {Column: "description", Type: listquery.String},
+		"status":              {Column: "status", Type: listquery.String, Sortable: true},
+		"reason_lost":         {Column: "reason_lost", Type: listquery.String},
+	},
+	Includes: []string{"customer"},
+}
+
+// ProductListSchema is the allow-list of the product list query.
+var ProductListSchema = &listquery.Schema{
+	Fields: map[string]listquery.Field{
+		"id":          {Column: "id", Type: listquery.UUID},
+		"name":        {Column: "name", Type: listquery.String, Sortable: true},
+		"description": {Column: "description", Type: listquery.String},
+		"sku":         {Column: "sku", Type: listquery.String},
+		"unit_price":  {Column: "unit_price", Type: listquery.Number, Sortable: true},
+		"category":    {Column: "category", Type: listquery.String},
+		"created_at":  {Column: "created_at", Type: listquery.Time, Sortable: true},
+		"updated_at":  {Column: "updated_at", Type: listquery.Time, Sortable: true},
+		"is_active":   {Column: "is_active", Type: listquery.Bool},
+	},
+}
+
+// TaskListSchema is the allow-list of the task list query.
+var TaskListSchema = &listquery.Schema{
+	Fields: map[string]listquery.Field{
+		"id":             {Column: "id", Type: listquery.UUID},
+		"title":          {Column: "title", Type: listquery.String, Sortable: true},
+		"description":    {Column: "description", Type: listquery.String},
+		"due_date":       {Column: "due_date", Type: listquery.Time, Sortable: true},
+		"priority":       {Column: "priority", Type: listquery.String, Sortable: true},
+		"status":         {Column: "status", Type: listquery.String, Sortable: true},
+		"assigned_to":    {Column: "assigned_to", Type: listquery.UUID},
+		"created_by":     {Column: "created_by", Type: listquery.UUID},
+		"customer_id":    {Column: "customer_id", Type: listquery.UUID},
+		"opportunity_id": {Column: "opportunity_id", Type: listquery.UUID},
+		"contact_id":     {Column: "contact_id", Type: listquery.UUID},
+		"created_at":     {Column: "created_at", Type: listquery.Time, Sortable: true},
+		"updated_at":     {Column: "updated_at", Type: listquery.Time, Sortable: true},
+		"completed_at":   {Column: "completed_at", Type: listquery.Time},
+	},
+	Includes: []string{"customer", "opportunity"},
+}
+
+// UserListSchema is the allow-list of the user directory query.
+var UserListSchema = &listquery.Schema{
+	Fields: map[string]listquery.Field{
+		"id":         {Column: "id", Type: listquery.UUID},
+		"username":   {Column: "username", Type: listquery.String, Sortable: true},
+		"email":      {Column: "email", Type: listquery.String, Sortable: true},
+		"first_name": {Column: "first_name", Type: listquery.String, Sortable: true},
+		"last_name":  {Column: "last_name", Type: listquery.String, Sortable: true},
+		"role_id":    {Column: "role_id", Type: listquery.UUID},
+		"department": {Column: "department", Type: listquery.String},
+		"manager_id": {Column: "manager_id", Type: listquery.UUID},
+		"created_at": {Column: "created_at", Type: listquery.Time, Sortable: true},
+		"updated_at": {Column: "updated_at", Type: listquery.Time, Sortable: true},
+		"is_active":  {Column: "is_active", Type: listquery.Bool},
+		"deleted_at": {Column: "deleted_at", Type: listquery.Time},
+	},
+	Includes: []string{"role", "manager"},
+}
@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+)
+
+var _ repository.ActivityLogRepository = (*ActivityLogRepository)(nil)
+
+type ActivityLogRepository struct {
+	store *Store
+}
+
+// Create
+func (r *ActivityLogRepository) Create(ctx context.Context, entry *model.ActivityLog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	e := *entry
+	e.ID, e.CreatedAt, _ = r.store.stamp(e.ID, time.Time{})
+	entry.ID, entry.CreatedAt = e.ID, e.CreatedAt
+	r.store.activityLogs = append(r.store.activityLogs, &e)
+
+	return nil
+}
+
+// All returns the stored entries, oldest first.
+func (r *ActivityLogRepository) All() []*model.ActivityLog {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	entries := make([]*model.ActivityLog, 0, len(r.store.activityLogs))
+	for _, entry := range r.store.activityLogs {
+		e := *entry
+		entries = append(entries, &e)
+	}
+
+	return entries
+}
@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+var _ repository.ContactRepository = (*ContactRepository)(nil)
+
+type ContactRepository struct {
+	store *Store
+}
+
+// ListByCustomers
+func (r *ContactRepository) ListByCustomers(ctx context.Context, customerIDs []uuid.UUID) ([]*model.Contact, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	wanted := map[uuid.UUID]bool{}
+	for _, id := range customerIDs {
+		wanted[id] = true
+	}
+
+	contacts := []*model.Contact{}
+	for _, contact := range r.store.contacts {
+		if wanted[contact.CustomerID] {
+			c := *contact
+			contacts = append(contacts, &c)
+		}
+	}
+
+	sort.Slice(contacts, func(i, j int) bool {
+		a, b := contacts[i], contacts[j]
+		if a.IsPrimary != b.IsPrimary {
+			return a.IsPrimary
+		}
+		if a.LastName != b.LastName {
+			return a.LastName < b.LastName
+		}
+		return a.FirstName < b.FirstName
+	})
+
+	return contacts, nil
+}
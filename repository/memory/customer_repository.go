@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+
+	"customize_crm/listquery"
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+var _ repository.CustomerRepository = (*CustomerRepository)(nil)
+
+type CustomerRepository struct {
+	store *Store
+}
+
+// List
+func (r *CustomerRepository) List(ctx context.Context, scope *model.VisibilityScope, params repository.PageParams) (*model.CustomerListResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	customers := []*model.Customer{}
+	for _, customer := range r.store.customers {
+		if visible(scope, customer.AssignedTo, customer.CreatedBy) && isAssignedTo(customer.AssignedTo, params.AssignedTo) &&
+			listquery.Match(customer, params.Query.Filters) {
+			c := *customer
+			customers = append(customers, &c)
+		}
+	}
+
+	customers, pagination, err := page(customers, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CustomerListResponse{Data: customers, Pagination: pagination}, nil
+}
+
+// GetByIDs
+func (r *CustomerRepository) GetByIDs(ctx context.Context, scope *model.VisibilityScope, ids []uuid.UUID) ([]*model.Customer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	customers := []*model.Customer{}
+	for _, id := range ids {
+		if customer, ok := r.store.customers[id]; ok && visible(scope, customer.AssignedTo, customer.CreatedBy) {
+			c := *customer
+			customers = append(customers, &c)
+		}
+	}
+
+	return customers, nil
+}
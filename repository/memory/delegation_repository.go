@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+var _ repository.DelegationRepository = (*DelegationRepository)(nil)
+
+type DelegationRepository struct {
+	store *Store
+}
+
+// Create
+func (r *DelegationRepository) Create(ctx context.Context, delegation *model.Delegation) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delegate, ok := r.store.users[delegation.DelegateID]
+	if !ok || !delegate.IsActive || delegate.DeletedAt != nil {
+		return repository.ErrNotFound
+	}
+
+	d := *delegation
+	d.ID, d.CreatedAt, d.UpdatedAt = r.store.stamp(uuid.Nil, time.Time{})
+	delegation.ID, delegation.CreatedAt, delegation.UpdatedAt = d.ID, d.CreatedAt, d.UpdatedAt
+	r.store.delegations = append(r.store.delegations, &d)
+
+	return nil
+}
+
+// ListForUser
+func (r *DelegationRepository) ListForUser(ctx context.Context, userID uuid.UUID) ([]*model.Delegation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	delegations := []*model.Delegation{}
+	for _, delegation := range r.store.delegations {
+		if delegation.DelegatorID == userID || delegation.DelegateID == userID {
+			d := *delegation
+			delegations = append(delegations, &d)
+		}
+	}
+
+	sort.SliceStable(delegations, func(i, j int) bool {
+		return delegations[i].StartsAt.After(delegations[j].StartsAt)
+	})
+
+	return delegations, nil
+}
+
+// GetByID
+func (r *DelegationRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Delegation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	delegation := r.find(id)
+	if delegation == nil {
+		return nil, repository.ErrNotFound
+	}
+
+	d := *delegation
+	return &d, nil
+}
+
+// Revoke
+func (r *DelegationRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delegation := r.find(id)
+	if delegation == nil {
+		return repository.ErrNotFound
+	}
+
+	if delegation.RevokedAt == nil {
+		now := r.store.Now()
+		delegation.RevokedAt = &now
+		delegation.UpdatedAt = now
+	}
+
+	return nil
+}
+
+// find returns the stored delegation. The caller must hold the lock.
+func (r *DelegationRepository) find(id uuid.UUID) *model.Delegation {
+	for _, delegation := range r.store.delegations {
+		if delegation.ID == id {
+			return delegation
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+var _ repository.InvitationRepository = (*InvitationRepository)(nil)
+
+type InvitationRepository struct {
+	store *Store
+}
+
+// invitation is a stored invitation together with the hash of its token.
+type invitation struct {
+	model.Invitation
+	tokenHash string
+}
+
+// pending reports whether the invitation can still be renewed or revoked.
+func (i *invitation) pending() bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil
+}
+
+// Create
+func (r *InvitationRepository) Create(ctx context.Context, inv *model.Invitation, tokenHash string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored := &invitation{Invitation: *inv, tokenHash: tokenHash}
+	stored.ID, stored.CreatedAt, stored.UpdatedAt = r.store.stamp(uuid.Nil, time.Time{})
+	inv.ID, inv.CreatedAt, inv.UpdatedAt = stored.ID, stored.CreatedAt, stored.UpdatedAt
+	r.store.invitations[stored.ID] = stored
+
+	return nil
+}
+
+// GetAll
+func (r *InvitationRepository) GetAll(ctx context.Context) ([]*model.Invitation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	invitations := []*model.Invitation{}
+	for _, stored := range r.store.invitations {
+		if inv := r.withEmail(stored); inv != nil {
+			invitations = append(invitations, inv)
+		}
+	}
+
+	sort.Slice(invitations, func(i, j int) bool {
+		return invitations[i].CreatedAt.After(invitations[j].CreatedAt)
+	})
+
+	return invitations, nil
+}
+
+// GetByID
+func (r *InvitationRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Invitation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	stored, ok := r.store.invitations[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	inv := r.withEmail(stored)
+	if inv == nil {
+		return nil, repository.ErrNotFound
+	}
+
+	return inv, nil
+}
+
+// Renew
+func (r *InvitationRepository) Renew(ctx context.Context, id uuid.UUID, tokenHash string, expiresAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.invitations[id]
+	if !ok || !stored.pending() {
+		return repository.ErrNotFound
+	}
+
+	stored.tokenHash = tokenHash
+	stored.ExpiresAt = expiresAt
+	stored.UpdatedAt = r.store.Now()
+
+	return nil
+}
+
+// Revoke
+func (r *InvitationRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.invitations[id]
+	if !ok || !stored.pending() {
+		return repository.ErrNotFound
+	}
+
+	now := r.store.Now()
+	stored.RevokedAt = &now
+	stored.UpdatedAt = now
+
+	return nil
+}
+
+// Accept
+func (r *InvitationRepository) Accept(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.Now()
+	for _, stored := range r.store.invitations {
+		if stored.tokenHash == tokenHash && stored.pending() && stored.ExpiresAt.After(now) {
+			stored.AcceptedAt = &now
+			stored.UpdatedAt = now
+			return stored.UserID, nil
+		}
+	}
+
+	return uuid.Nil, repository.ErrNotFound
+}
+
+// withEmail copies the invitation with the invited user's email, or returns
+// nil when the user is gone. The caller must hold the lock.
+func (r *InvitationRepository) withEmail(stored *invitation) *model.Invitation {
+	user, ok := r.store.users[stored.UserID]
+	if !ok {
+		return nil
+	}
+
+	inv := stored.Invitation
+	inv.Email = user.Email
+	return &inv
+}
@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"customize_crm/listquery"
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+var _ repository.OpportunityRepository = (*OpportunityRepository)(nil)
+
+type OpportunityRepository struct {
+	store *Store
+}
+
+// List
+func (r *OpportunityRepository) List(ctx context.Context, scope *model.VisibilityScope, params repository.PageParams) (*model.OpportunityListResponse, error) {
+	return r.list(params, func(o *model.Opportunity) bool {
+		return visible(scope, o.AssignedTo, o.CreatedBy)
+	})
+}
+
+// ListByTeam
+func (r *OpportunityRepository) ListByTeam(ctx context.Context, teamID uuid.UUID, params repository.PageParams) (*model.OpportunityListResponse, error) {
+	return r.list(params, func(o *model.Opportunity) bool {
+		return r.store.ownedByTeam(teamID, o.TeamID, o.AssignedTo)
+	})
+}
+
+// GetByIDs
+func (r *OpportunityRepository) GetByIDs(ctx context.Context, scope *model.VisibilityScope, ids []uuid.UUID) ([]*model.Opportunity, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	opportunities := []*model.Opportunity{}
+	for _, id := range ids {
+		if opportunity, ok := r.store.opportunities[id]; ok && visible(scope, opportunity.AssignedTo, opportunity.CreatedBy) {
+			o := *opportunity
+			opportunities = append(opportunities, &o)
+		}
+	}
+
+	return opportunities, nil
+}
+
+// ListByCustomers
+func (r *OpportunityRepository) ListByCustomers(ctx context.Context, scope *model.VisibilityScope, customerIDs []uuid.UUID) ([]*model.Opportunity, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	wanted := map[uuid.UUID]bool{}
+	for _, id := range customerIDs {
+		wanted[id] = true
+	}
+
+	opportunities := []*model.Opportunity{}
+	for _, opportunity := range r.store.opportunities {
+		if wanted[opportunity.CustomerID] && visible(scope, opportunity.AssignedTo, opportunity.CreatedBy) {
+			o := *opportunity
+			opportunities = append(opportunities, &o)
+		}
+	}
+
+	sort.Slice(opportunities, func(i, j int) bool {
+		return listquery.Less(opportunities[i], opportunities[j], listquery.DefaultSort)
+	})
+
+	return opportunities, nil
+}
+
+func (r *OpportunityRepository) list(params repository.PageParams, include func(*model.Opportunity) bool) (*model.OpportunityListResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	opportunities := []*model.Opportunity{}
+	for _, opportunity := range r.store.opportunities {
+		if include(opportunity) && isAssignedTo(opportunity.AssignedTo, params.AssignedTo) && listquery.Match(opportunity, params.Query.Filters) {
+			o := *opportunity
+			opportunities = append(opportunities, &o)
+		}
+	}
+
+	opportunities, pagination, err := page(opportunities, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.OpportunityListResponse{Data: opportunities, Pagination: pagination}, nil
+}
+
+// Pipeline
+func (r *OpportunityRepository) Pipeline(ctx context.Context, scope *model.VisibilityScope) ([]*model.PipelineStageSummary, error) {
+	return r.pipeline(func(o *model.Opportunity) bool {
+		return visible(scope, o.AssignedTo, o.CreatedBy)
+	}), nil
+}
+
+// TeamPipeline
+func (r *OpportunityRepository) TeamPipeline(ctx context.Context, teamID uuid.UUID) ([]*model.PipelineStageSummary, error) {
+	return r.pipeline(func(o *model.Opportunity) bool {
+		return r.store.ownedByTeam(teamID, o.TeamID, o.AssignedTo)
+	}), nil
+}
+
+func (r *OpportunityRepository) pipeline(include func(*model.Opportunity) bool) []*model.PipelineStageSummary {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	stages := map[string]*model.PipelineStageSummary{}
+	for _, o := range r.store.opportunities {
+		if o.Status != model.OpportunityStatusOpen || !include(o) {
+			continue
+		}
+
+		summary, ok := stages[o.Stage]
+		if !ok {
+			summary = &model.PipelineStageSummary{Stage: o.Stage}
+			stages[o.Stage] = summary
+		}
+
+		summary.Count++
+		if o.Amount != nil {
+			summary.TotalAmount += *o.Amount
+			if o.Probability != nil {
+				summary.WeightedAmount += *o.Amount * float64(*o.Probability) / 100
+			}
+		}
+	}
+
+	summaries := []*model.PipelineStageSummary{}
+	for _, summary := range stages {
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Stage < summaries[j].Stage })
+
+	return summaries
+}
+
+// ownedByTeam reports whether a record is assigned to the team or to one of
+// its members. The caller must hold the lock.
+func (s *Store) ownedByTeam(teamID uuid.UUID, recordTeamID, assignedTo *uuid.UUID) bool {
+	if recordTeamID != nil && *recordTeamID == teamID {
+		return true
+	}
+	return assignedTo != nil && s.teamMembers[teamID][*assignedTo] != nil
+}
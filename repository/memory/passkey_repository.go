@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"time"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+var _ repository.PasskeyRepository = (*PasskeyRepository)(nil)
+
+type PasskeyRepository struct {
+	store *Store
+}
+
+// session is a pending WebAuthn ceremony.
+type session struct {
+	userID    *uuid.UUID
+	data      []byte
+	expiresAt time.Time
+}
+
+// Create
+func (r *PasskeyRepository) Create(ctx context.Context, passkey *model.Passkey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.passkeys {
+		if bytes.Equal(existing.CredentialID, passkey.CredentialID) {
+			return uniqueViolation("user_passkeys", "user_passkeys_credential_id_key")
+		}
+	}
+
+	p := *passkey
+	p.ID, p.CreatedAt, p.UpdatedAt = r.store.stamp(uuid.Nil, time.Time{})
+	passkey.ID, passkey.CreatedAt, passkey.UpdatedAt = p.ID, p.CreatedAt, p.UpdatedAt
+	r.store.passkeys[p.ID] = &p
+
+	return nil
+}
+
+// ListByUser
+func (r *PasskeyRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.Passkey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	passkeys := []*model.Passkey{}
+	for _, passkey := range r.store.passkeys {
+		if passkey.UserID == userID {
+			p := *passkey
+			passkeys = append(passkeys, &p)
+		}
+	}
+
+	sort.Slice(passkeys, func(i, j int) bool {
+		return passkeys[i].CreatedAt.Before(passkeys[j].CreatedAt)
+	})
+
+	return passkeys, nil
+}
+
+// GetByID
+func (r *PasskeyRepository) GetByID(ctx context.Context, userID, id uuid.UUID) (*model.Passkey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	passkey, ok := r.store.passkeys[id]
+	if !ok || passkey.UserID != userID {
+		return nil, repository.ErrNotFound
+	}
+
+	p := *passkey
+	return &p, nil
+}
+
+// UpdateCredential
+func (r *PasskeyRepository) UpdateCredential(ctx context.Context, userID uuid.UUID, credentialID, credential []byte) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, passkey := range r.store.passkeys {
+		if passkey.UserID == userID && bytes.Equal(passkey.CredentialID, credentialID) {
+			now := r.store.Now()
+			passkey.Credential = credential
+			passkey.LastUsedAt = &now
+			passkey.UpdatedAt = now
+		}
+	}
+
+	return nil
+}
+
+// Rename
+func (r *PasskeyRepository) Rename(ctx context.Context, userID, id uuid.UUID, name string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	passkey, ok := r.store.passkeys[id]
+	if !ok || passkey.UserID != userID {
+		return repository.ErrNotFound
+	}
+
+	passkey.Name = name
+	passkey.UpdatedAt = r.store.Now()
+
+	return nil
+}
+
+// Delete
+func (r *PasskeyRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	passkey, ok := r.store.passkeys[id]
+	if !ok || passkey.UserID != userID {
+		return repository.ErrNotFound
+	}
+
+	delete(r.store.passkeys, id)
+
+	return nil
+}
+
+// CreateSession
+func (r *PasskeyRepository) CreateSession(ctx context.Context, userID *uuid.UUID, data []byte, expiresAt time.Time) (uuid.UUID, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	id := uuid.New()
+	r.store.sessions[id] = &session{userID: userID, data: data, expiresAt: expiresAt}
+
+	return id, nil
+}
+
+// TakeSession
+func (r *PasskeyRepository) TakeSession(ctx context.Context, id uuid.UUID) (*uuid.UUID, []byte, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	s, ok := r.store.sessions[id]
+	if !ok || !s.expiresAt.After(r.store.Now()) {
+		return nil, nil, repository.ErrNotFound
+	}
+
+	delete(r.store.sessions, id)
+
+	return s.userID, s.data, nil
+}
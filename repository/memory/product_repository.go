@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"context"
+
+	"customize_crm/listquery"
+	"customize_crm/model"
+	"customize_crm/repository"
+)
+
+var _ repository.ProductRepository = (*ProductRepository)(nil)
+
+type ProductRepository struct {
+	store *Store
+}
+
+// List
+func (r *ProductRepository) List(ctx context.Context, params repository.PageParams) (*model.ProductListResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	products := []*model.Product{}
+	for _, product := range r.store.products {
+		if listquery.Match(product, params.Query.Filters) {
+			p := *product
+			products = append(products, &p)
+		}
+	}
+
+	products, pagination, err := page(products, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ProductListResponse{Data: products, Pagination: pagination}, nil
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+var _ repository.ReassignmentRepository = (*ReassignmentRepository)(nil)
+
+type ReassignmentRepository struct {
+	store *Store
+}
+
+// Reassign
+func (r *ReassignmentRepository) Reassign(ctx context.Context, reassignment *model.Reassignment, entityTypes []string, req model.ReassignmentRequest) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	target, ok := r.store.users[req.ToUserID]
+	if !ok || !target.IsActive || target.DeletedAt != nil {
+		return repository.ErrInvalidReassignTarget
+	}
+
+	openOnly := req.OpenOnly == nil || *req.OpenOnly
+	from := req.FromUserID
+	to := req.ToUserID
+	now := r.store.Now()
+
+	// move collects the matching record and, unless this is a dry run,
+	// hands it to the target user.
+	move := func(ids *[]uuid.UUID, id uuid.UUID, assignedTo **uuid.UUID, updatedAt *time.Time) {
+		*ids = append(*ids, id)
+		if !req.DryRun {
+			*assignedTo = &to
+			*updatedAt = now
+		}
+	}
+
+	for _, entityType := range entityTypes {
+		ids := []uuid.UUID{}
+
+		switch entityType {
+		case model.EntityCustomers:
+			for _, c := range r.store.customers {
+				if isAssignedTo(c.AssignedTo, &from) && (req.CustomerStatus == nil || c.CustomerStatus == *req.CustomerStatus) {
+					move(&ids, c.ID, &c.AssignedTo, &c.UpdatedAt)
+				}
+			}
+			reassignment.CustomerIDs = sorted(ids)
+		case model.EntityOpportunities:
+			for _, o := range r.store.opportunities {
+				if isAssignedTo(o.AssignedTo, &from) &&
+					(!openOnly || o.Status == model.OpportunityStatusOpen) &&
+					(req.OpportunityStage == nil || o.Stage == *req.OpportunityStage) {
+					move(&ids, o.ID, &o.AssignedTo, &o.UpdatedAt)
+				}
+			}
+			reassignment.OpportunityIDs = sorted(ids)
+		case model.EntityTasks:
+			for _, t := range r.store.tasks {
+				if isAssignedTo(t.AssignedTo, &from) &&
+					(!openOnly || t.CompletedAt == nil) &&
+					(req.TaskStatus == nil || t.Status == *req.TaskStatus) &&
+					(req.DueBefore == nil || t.DueDate.Before(*req.DueBefore)) {
+					move(&ids, t.ID, &t.AssignedTo, &t.UpdatedAt)
+				}
+			}
+			reassignment.TaskIDs = sorted(ids)
+		}
+	}
+
+	if req.DryRun {
+		return nil
+	}
+
+	stored := *reassignment
+	stored.ID, stored.CreatedAt, _ = r.store.stamp(uuid.Nil, time.Time{})
+	reassignment.ID, reassignment.CreatedAt = stored.ID, stored.CreatedAt
+	r.store.reassignments = append(r.store.reassignments, &stored)
+
+	return nil
+}
+
+// GetAll
+func (r *ReassignmentRepository) GetAll(ctx context.Context) ([]*model.Reassignment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	reassignments := []*model.Reassignment{}
+	for i := len(r.store.reassignments) - 1; i >= 0; i-- {
+		stored := *r.store.reassignments[i]
+		reassignments = append(reassignments, &stored)
+	}
+
+	return reassignments, nil
+}
+
+// sorted orders the IDs so results do not depend on map iteration.
+func sorted(ids []uuid.UUID) []uuid.UUID {
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+	return ids
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+var _ repository.RoleRepository = (*RoleRepository)(nil)
+
+type RoleRepository struct {
+	store *Store
+}
+
+// GetByID
+func (r *RoleRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Role, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	role, ok := r.store.roles[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	copied := *role
+	return &copied, nil
+}
+
+// GetByName
+func (r *RoleRepository) GetByName(ctx context.Context, name string) (*model.Role, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, role := range r.store.roles {
+		if role.Name == name {
+			copied := *role
+			return &copied, nil
+		}
+	}
+
+	return nil, repository.ErrNotFound
+}
+
+// CreateIfMissing
+func (r *RoleRepository) CreateIfMissing(ctx context.Context, role *model.Role) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.roles {
+		if existing.Name == role.Name {
+			return false, nil
+		}
+	}
+
+	copied := *role
+	copied.ID, copied.CreatedAt, copied.UpdatedAt = r.store.stamp(copied.ID, copied.CreatedAt)
+	role.ID, role.CreatedAt, role.UpdatedAt = copied.ID, copied.CreatedAt, copied.UpdatedAt
+	r.store.roles[copied.ID] = &copied
+
+	return true, nil
+}
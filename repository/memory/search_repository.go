@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+var _ repository.SearchRepository = (*SearchRepository)(nil)
+
+type SearchRepository struct {
+	store *Store
+}
+
+// Search matches records containing every word of the query, ignoring case.
+// It has no typo tolerance; the score is the share of the text the matched
+// words cover.
+func (r *SearchRepository) Search(ctx context.Context, scope *model.VisibilityScope, params model.SearchParams) ([]*model.SearchResult, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	words := strings.Fields(strings.ToLower(params.Query))
+	wanted := func(kind string) bool {
+		if len(params.Types) == 0 {
+			return true
+		}
+		for _, t := range params.Types {
+			if t == kind {
+				return true
+			}
+		}
+		return false
+	}
+
+	results := []*model.SearchResult{}
+	add := func(kind string, id uuid.UUID, title string, subtitle *string, customerID *uuid.UUID, text string) {
+		if highlight, score, ok := searchMatch(text, words); ok {
+			results = append(results, &model.SearchResult{
+				Type: kind, ID: id, Title: title, Subtitle: subtitle, CustomerID: customerID,
+				Highlight: highlight, Score: score,
+			})
+		}
+	}
+
+	companyName := func(customerID *uuid.UUID) *string {
+		if customerID == nil {
+			return nil
+		}
+		if c, ok := r.store.customers[*customerID]; ok {
+			return &c.CompanyName
+		}
+		return nil
+	}
+
+	if wanted(model.SearchTypeCustomer) {
+		for _, c := range r.store.customers {
+			if visible(scope, c.AssignedTo, c.CreatedBy) {
+				id := c.ID
+				add(model.SearchTypeCustomer, c.ID, c.CompanyName, c.Industry, &id, c.CompanyName)
+			}
+		}
+	}
+
+	if wanted(model.SearchTypeContact) {
+		for _, ct := range r.store.contacts {
+			c, ok := r.store.customers[ct.CustomerID]
+			if !ok || !visible(scope, c.AssignedTo, c.CreatedBy) {
+				continue
+			}
+			name := ct.FirstName + " " + ct.LastName
+			text := strings.Join([]string{name, deref(ct.Email), deref(ct.Phone), deref(ct.Mobile)}, " ")
+			customerID := ct.CustomerID
+			add(model.SearchTypeContact, ct.ID, name, &c.CompanyName, &customerID, text)
+		}
+	}
+
+	if wanted(model.SearchTypeOpportunity) {
+		for _, o := range r.store.opportunities {
+			if visible(scope, o.AssignedTo, o.CreatedBy) {
+				customerID := o.CustomerID
+				add(model.SearchTypeOpportunity, o.ID, o.Name, companyName(&customerID), &customerID, o.Name)
+			}
+		}
+	}
+
+	if wanted(model.SearchTypeProduct) {
+		for _, p := range r.store.products {
+			add(model.SearchTypeProduct, p.ID, p.Name, p.SKU, nil, p.Name+" "+deref(p.SKU))
+		}
+	}
+
+	if wanted(model.SearchTypeTask) {
+		for _, t := range r.store.tasks {
+			if visible(scope, t.AssignedTo, t.CreatedBy) {
+				add(model.SearchTypeTask, t.ID, t.Title, companyName(t.CustomerID), t.CustomerID, t.Title)
+			}
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Title < b.Title
+	})
+
+	if len(results) > params.Limit {
+		results = results[:params.Limit]
+	}
+
+	return results, nil
+}
+
+// searchMatch reports whether text contains every word, and returns it with
+// the words wrapped in <mark> tags.
+func searchMatch(text string, words []string) (string, float64, bool) {
+	if len(words) == 0 || text == "" {
+		return "", 0, false
+	}
+
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		// Offsets into lower must be valid in text.
+		lower = text
+	}
+	marked := make([]bool, len(text))
+	covered := 0
+	for _, word := range words {
+		i := strings.Index(lower, word)
+		if i < 0 {
+			return "", 0, false
+		}
+		for ; i >= 0; i = indexFrom(lower, word, i+len(word)) {
+			for j := i; j < i+len(word); j++ {
+				if !marked[j] {
+					marked[j] = true
+					covered++
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString("<mark>")
+		}
+		b.WriteByte(text[i])
+		if marked[i] && (i == len(text)-1 || !marked[i+1]) {
+			b.WriteString("</mark>")
+		}
+	}
+
+	return b.String(), float64(covered) / float64(len(text)), true
+}
+
+func indexFrom(s, substr string, from int) int {
+	i := strings.Index(s[from:], substr)
+	if i < 0 {
+		return -1
+	}
+	return from + i
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
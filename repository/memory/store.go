@@ -0,0 +1,312 @@
+// Package memory implements the repository interfaces in process. It mirrors
+// the behavior of the postgres implementations closely enough for tests and
+// local experiments, including unique constraint errors on users, roles,
+// teams, tags and passkeys.
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"customize_crm/listquery"
+	"customize_crm/model"
+	"customize_crm/repository"
+	"customize_crm/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Store holds every record. The repositories returned by its accessors share
+// the store and its lock.
+type Store struct {
+	mu sync.RWMutex
+
+	users         map[uuid.UUID]*model.User
+	roles         map[uuid.UUID]*model.Role
+	customers     map[uuid.UUID]*model.Customer
+	opportunities map[uuid.UUID]*model.Opportunity
+	tasks         map[uuid.UUID]*model.Task
+	contacts      map[uuid.UUID]*model.Contact
+	products      map[uuid.UUID]*model.Product
+	tags          map[uuid.UUID]*model.Tag
+	teams         map[uuid.UUID]*model.Team
+	teamMembers   map[uuid.UUID]map[uuid.UUID]*model.TeamMember
+	delegations   []*model.Delegation
+	invitations   map[uuid.UUID]*invitation
+	passkeys      map[uuid.UUID]*model.Passkey
+	sessions      map[uuid.UUID]*session
+	reassignments []*model.Reassignment
+	activityLogs  []*model.ActivityLog
+
+	// Now returns the current time. Tests may replace it for deterministic
+	// timestamps.
+	Now func() time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		users:         map[uuid.UUID]*model.User{},
+		roles:         map[uuid.UUID]*model.Role{},
+		customers:     map[uuid.UUID]*model.Customer{},
+		opportunities: map[uuid.UUID]*model.Opportunity{},
+		tasks:         map[uuid.UUID]*model.Task{},
+		contacts:      map[uuid.UUID]*model.Contact{},
+		products:      map[uuid.UUID]*model.Product{},
+		tags:          map[uuid.UUID]*model.Tag{},
+		teams:         map[uuid.UUID]*model.Team{},
+		teamMembers:   map[uuid.UUID]map[uuid.UUID]*model.TeamMember{},
+		invitations:   map[uuid.UUID]*invitation{},
+		passkeys:      map[uuid.UUID]*model.Passkey{},
+		sessions:      map[uuid.UUID]*session{},
+		Now:           time.Now,
+	}
+}
+
+func (s *Store) Users() *UserRepository {
+	return &UserRepository{store: s}
+}
+
+func (s *Store) Roles() *RoleRepository {
+	return &RoleRepository{store: s}
+}
+
+func (s *Store) Customers() *CustomerRepository {
+	return &CustomerRepository{store: s}
+}
+
+func (s *Store) Opportunities() *OpportunityRepository {
+	return &OpportunityRepository{store: s}
+}
+
+func (s *Store) Tasks() *TaskRepository {
+	return &TaskRepository{store: s}
+}
+
+func (s *Store) Contacts() *ContactRepository {
+	return &ContactRepository{store: s}
+}
+
+func (s *Store) Products() *ProductRepository {
+	return &ProductRepository{store: s}
+}
+
+func (s *Store) Search() *SearchRepository {
+	return &SearchRepository{store: s}
+}
+
+func (s *Store) Tags() *TagRepository {
+	return &TagRepository{store: s}
+}
+
+func (s *Store) Teams() *TeamRepository {
+	return &TeamRepository{store: s}
+}
+
+func (s *Store) Delegations() *DelegationRepository {
+	return &DelegationRepository{store: s}
+}
+
+func (s *Store) Invitations() *InvitationRepository {
+	return &InvitationRepository{store: s}
+}
+
+func (s *Store) Passkeys() *PasskeyRepository {
+	return &PasskeyRepository{store: s}
+}
+
+func (s *Store) Reassignments() *ReassignmentRepository {
+	return &ReassignmentRepository{store: s}
+}
+
+func (s *Store) ActivityLogs() *ActivityLogRepository {
+	return &ActivityLogRepository{store: s}
+}
+
+// Transactor runs functions directly: the store has no transactions, so
+// changes made before a failure are kept.
+func (s *Store) Transactor() *Transactor {
+	return &Transactor{}
+}
+
+// AddCustomer stores a copy of the customer, filling in a missing ID and
+// timestamps.
+func (s *Store) AddCustomer(customer *model.Customer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := *customer
+	c.ID, c.CreatedAt, c.UpdatedAt = s.stamp(c.ID, c.CreatedAt)
+	*customer = c
+	s.customers[c.ID] = &c
+}
+
+// AddOpportunity stores a copy of the opportunity, filling in a missing ID,
+// status and timestamps.
+func (s *Store) AddOpportunity(opportunity *model.Opportunity) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := *opportunity
+	o.ID, o.CreatedAt, o.UpdatedAt = s.stamp(o.ID, o.CreatedAt)
+	if o.Status == "" {
+		o.Status = model.OpportunityStatusOpen
+	}
+	*opportunity = o
+	s.opportunities[o.ID] = &o
+}
+
+// AddTask stores a copy of the task, filling in a missing ID and timestamps.
+func (s *Store) AddTask(task *model.Task) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := *task
+	t.ID, t.CreatedAt, t.UpdatedAt = s.stamp(t.ID, t.CreatedAt)
+	*task = t
+	s.tasks[t.ID] = &t
+}
+
+// AddContact stores a copy of the contact, filling in a missing ID and
+// timestamps.
+func (s *Store) AddContact(contact *model.Contact) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := *contact
+	c.ID, c.CreatedAt, c.UpdatedAt = s.stamp(c.ID, c.CreatedAt)
+	*contact = c
+	s.contacts[c.ID] = &c
+}
+
+// AddProduct stores a copy of the product, filling in a missing ID and
+// timestamps.
+func (s *Store) AddProduct(product *model.Product) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := *product
+	p.ID, p.CreatedAt, p.UpdatedAt = s.stamp(p.ID, p.CreatedAt)
+	*product = p
+	s.products[p.ID] = &p
+}
+
+// AddDelegation stores a copy of the delegation, filling in a missing ID and
+// creation time.
+func (s *Store) AddDelegation(delegation *model.Delegation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d := *delegation
+	d.ID, d.CreatedAt, d.UpdatedAt = s.stamp(d.ID, d.CreatedAt)
+	*delegation = d
+	s.delegations = append(s.delegations, &d)
+}
+
+// AddTeamMember makes the user a member of the team.
+func (s *Store) AddTeamMember(teamID, userID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.setTeamMember(teamID, userID, model.TeamRoleMember)
+}
+
+// setTeamMember adds the member or changes its role. The caller must hold
+// the lock.
+func (s *Store) setTeamMember(teamID, userID uuid.UUID, role string) {
+	if s.teamMembers[teamID] == nil {
+		s.teamMembers[teamID] = map[uuid.UUID]*model.TeamMember{}
+	}
+	if member, ok := s.teamMembers[teamID][userID]; ok {
+		member.Role = role
+		return
+	}
+	s.teamMembers[teamID][userID] = &model.TeamMember{TeamID: teamID, UserID: userID, Role: role, JoinedAt: s.Now()}
+}
+
+func (s *Store) stamp(id uuid.UUID, createdAt time.Time) (uuid.UUID, time.Time, time.Time) {
+	if id == uuid.Nil {
+		id = uuid.New()
+	}
+
+	now := s.Now()
+	if createdAt.IsZero() {
+		createdAt = now
+	}
+
+	return id, createdAt, now
+}
+
+// uniqueViolation builds the error Postgres returns for a unique constraint,
+// so callers handle both implementations the same way.
+func uniqueViolation(table, constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23505",
+		Message:        `duplicate key value violates unique constraint "` + constraint + `"`,
+		TableName:      table,
+		ConstraintName: constraint,
+	}
+}
+
+// visible reports whether any of the owners is in scope.
+func visible(scope *model.VisibilityScope, owners ...*uuid.UUID) bool {
+	if scope.All {
+		return true
+	}
+
+	for _, owner := range owners {
+		if owner == nil {
+			continue
+		}
+		for _, id := range scope.UserIDs {
+			if *owner == id {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func isAssignedTo(assignedTo, want *uuid.UUID) bool {
+	return want == nil || (assignedTo != nil && *assignedTo == *want)
+}
+
+// page sorts the items in the order of the list query, newest first by
+// default, and applies keyset pagination like the postgres implementations.
+func page[T any](items []T, params repository.PageParams) ([]T, model.Pagination, error) {
+	order := params.Query.Order()
+	sort.Slice(items, func(i, j int) bool {
+		return listquery.Less(items[i], items[j], order)
+	})
+
+	pagination := model.Pagination{Limit: params.Limit, Total: int64(len(items))}
+
+	if params.Cursor != "" {
+		cursor, err := utils.DecodeCursor(params.Cursor)
+		if err != nil {
+			return nil, pagination, err
+		}
+
+		value, err := order.ParseCursor(cursor.Value)
+		if err != nil {
+			return nil, pagination, err
+		}
+
+		start := sort.Search(len(items), func(i int) bool {
+			return listquery.After(items[i], order, value, cursor.ID)
+		})
+		items = items[start:]
+	}
+
+	if len(items) > params.Limit {
+		items = items[:params.Limit]
+
+		pagination.HasMore = true
+		pagination.NextCursor = utils.EncodeCursor(listquery.Cursor(items[len(items)-1], order))
+	}
+
+	return items, pagination, nil
+}
@@ -0,0 +1,277 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+var _ repository.TagRepository = (*TagRepository)(nil)
+
+type TagRepository struct {
+	store *Store
+}
+
+// Create
+func (r *TagRepository) Create(ctx context.Context, tag *model.Tag) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.named(tag.Name) != nil {
+		return uniqueViolation("tags", "tags_name_key")
+	}
+
+	t := *tag
+	t.ID, t.CreatedAt, t.UpdatedAt = r.store.stamp(uuid.Nil, time.Time{})
+	t.CustomerCount = 0
+	tag.ID, tag.CreatedAt, tag.UpdatedAt = t.ID, t.CreatedAt, t.UpdatedAt
+	r.store.tags[t.ID] = &t
+
+	return nil
+}
+
+// GetAll
+func (r *TagRepository) GetAll(ctx context.Context) ([]*model.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tags := []*model.Tag{}
+	for _, tag := range r.store.tags {
+		tags = append(tags, r.counted(tag))
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+
+	return tags, nil
+}
+
+// GetByID
+func (r *TagRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tag, ok := r.store.tags[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	return r.counted(tag), nil
+}
+
+// Update
+func (r *TagRepository) Update(ctx context.Context, tag *model.Tag, updatedAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, err := r.current(tag.ID, &updatedAt)
+	if err != nil {
+		return err
+	}
+	if other := r.named(tag.Name); other != nil && other.ID != tag.ID {
+		return uniqueViolation("tags", "tags_name_key")
+	}
+
+	if tag.Name != existing.Name {
+		r.replaceOnCustomers(existing.Name, tag.Name)
+	}
+
+	existing.Name = tag.Name
+	existing.Color = tag.Color
+	existing.UpdatedAt = r.store.Now()
+	tag.CreatedAt, tag.UpdatedAt = existing.CreatedAt, existing.UpdatedAt
+
+	return nil
+}
+
+// Delete
+func (r *TagRepository) Delete(ctx context.Context, id uuid.UUID, updatedAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tag, err := r.current(id, &updatedAt)
+	if err != nil {
+		return err
+	}
+
+	now := r.store.Now()
+	for _, customer := range r.store.customers {
+		if tags := without(customer.Tags, tag.Name); len(tags) != len(customer.Tags) {
+			customer.Tags = tags
+			customer.UpdatedAt = now
+		}
+	}
+
+	delete(r.store.tags, id)
+
+	return nil
+}
+
+// Merge
+func (r *TagRepository) Merge(ctx context.Context, sourceID, targetID uuid.UUID, sourceUpdatedAt time.Time) (*model.Tag, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	source, err := r.current(sourceID, &sourceUpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	target, err := r.current(targetID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r.replaceOnCustomers(source.Name, target.Name)
+	delete(r.store.tags, sourceID)
+	target.UpdatedAt = r.store.Now()
+
+	return r.counted(target), nil
+}
+
+// UpdateCustomerTags
+func (r *TagRepository) UpdateCustomerTags(ctx context.Context, scope *model.VisibilityScope, req *model.CustomerTagsRequest) (int64, []string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var unknown []string
+	for _, name := range req.Add {
+		if r.named(name) == nil {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return 0, unknown, nil
+	}
+
+	removed := map[string]bool{}
+	for _, name := range req.Remove {
+		removed[name] = true
+	}
+
+	now := r.store.Now()
+	var updated int64
+	for _, id := range req.CustomerIDs {
+		customer, ok := r.store.customers[id]
+		if !ok || !visible(scope, customer.AssignedTo, customer.CreatedBy) {
+			continue
+		}
+
+		var tags []string
+		for _, name := range dedupe(append(append([]string{}, customer.Tags...), req.Add...)) {
+			if !removed[name] {
+				tags = append(tags, name)
+			}
+		}
+		if equalTags(tags, customer.Tags) {
+			continue
+		}
+
+		customer.Tags = tags
+		customer.UpdatedAt = now
+		updated++
+	}
+
+	return updated, nil, nil
+}
+
+// current returns the stored tag, which must not have changed since
+// updatedAt when that is given. The caller must hold the lock.
+func (r *TagRepository) current(id uuid.UUID, updatedAt *time.Time) (*model.Tag, error) {
+	tag, ok := r.store.tags[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if updatedAt != nil && !tag.UpdatedAt.Equal(*updatedAt) {
+		return nil, repository.ErrStale
+	}
+
+	return tag, nil
+}
+
+// named returns the stored tag with the name. The caller must hold the lock.
+func (r *TagRepository) named(name string) *model.Tag {
+	for _, tag := range r.store.tags {
+		if tag.Name == name {
+			return tag
+		}
+	}
+	return nil
+}
+
+// counted copies the tag with its customer count. The caller must hold the
+// lock.
+func (r *TagRepository) counted(tag *model.Tag) *model.Tag {
+	t := *tag
+	t.CustomerCount = 0
+	for _, customer := range r.store.customers {
+		if len(without(customer.Tags, tag.Name)) != len(customer.Tags) {
+			t.CustomerCount++
+		}
+	}
+
+	return &t
+}
+
+// replaceOnCustomers renames the tag on every customer that has it. The
+// caller must hold the lock.
+func (r *TagRepository) replaceOnCustomers(oldName, newName string) {
+	now := r.store.Now()
+	for _, customer := range r.store.customers {
+		if len(without(customer.Tags, oldName)) == len(customer.Tags) {
+			continue
+		}
+
+		tags := make([]string, len(customer.Tags))
+		for i, name := range customer.Tags {
+			if name == oldName {
+				name = newName
+			}
+			tags[i] = name
+		}
+
+		customer.Tags = dedupe(tags)
+		customer.UpdatedAt = now
+	}
+}
+
+// dedupe removes repeated tags, keeping the first occurrence of each in
+// place.
+func dedupe(tags []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, name := range tags {
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
+
+func without(tags []string, name string) []string {
+	var rest []string
+	for _, tag := range tags {
+		if tag != name {
+			rest = append(rest, tag)
+		}
+	}
+	return rest
+}
+
+func equalTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
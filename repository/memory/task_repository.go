@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"customize_crm/listquery"
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+var _ repository.TaskRepository = (*TaskRepository)(nil)
+
+type TaskRepository struct {
+	store *Store
+}
+
+// List
+func (r *TaskRepository) List(ctx context.Context, scope *model.VisibilityScope, params repository.PageParams) (*model.TaskListResponse, error) {
+	return r.list(params, func(t *model.Task) bool {
+		return visible(scope, t.AssignedTo, t.CreatedBy)
+	})
+}
+
+// ListByTeam
+func (r *TaskRepository) ListByTeam(ctx context.Context, teamID uuid.UUID, params repository.PageParams) (*model.TaskListResponse, error) {
+	return r.list(params, func(t *model.Task) bool {
+		if t.AssignedTo != nil && r.store.teamMembers[teamID][*t.AssignedTo] != nil {
+			return true
+		}
+		if t.CustomerID != nil {
+			if c, ok := r.store.customers[*t.CustomerID]; ok && c.TeamID != nil && *c.TeamID == teamID {
+				return true
+			}
+		}
+		if t.OpportunityID != nil {
+			if o, ok := r.store.opportunities[*t.OpportunityID]; ok && o.TeamID != nil && *o.TeamID == teamID {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (r *TaskRepository) list(params repository.PageParams, include func(*model.Task) bool) (*model.TaskListResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tasks := []*model.Task{}
+	for _, task := range r.store.tasks {
+		if include(task) && isAssignedTo(task.AssignedTo, params.AssignedTo) && listquery.Match(task, params.Query.Filters) {
+			t := *task
+			tasks = append(tasks, &t)
+		}
+	}
+
+	tasks, pagination, err := page(tasks, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TaskListResponse{Data: tasks, Pagination: pagination}, nil
+}
+
+// StatusSummary
+func (r *TaskRepository) StatusSummary(ctx context.Context, scope *model.VisibilityScope) ([]*model.TaskStatusSummary, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	now := r.store.Now()
+	statuses := map[string]*model.TaskStatusSummary{}
+
+	for _, t := range r.store.tasks {
+		if !visible(scope, t.AssignedTo, t.CreatedBy) {
+			continue
+		}
+
+		summary, ok := statuses[t.Status]
+		if !ok {
+			summary = &model.TaskStatusSummary{Status: t.Status}
+			statuses[t.Status] = summary
+		}
+
+		summary.Count++
+		if t.CompletedAt == nil && t.DueDate.Before(now) {
+			summary.Overdue++
+		}
+	}
+
+	summaries := []*model.TaskStatusSummary{}
+	for _, summary := range statuses {
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Status < summaries[j].Status })
+
+	return summaries, nil
+}
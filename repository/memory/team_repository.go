@@ -0,0 +1,265 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+var _ repository.TeamRepository = (*TeamRepository)(nil)
+
+type TeamRepository struct {
+	store *Store
+}
+
+// Create
+func (r *TeamRepository) Create(ctx context.Context, team *model.Team) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.teams {
+		if existing.Name == team.Name {
+			return uniqueViolation("teams", "teams_name_key")
+		}
+	}
+
+	t := *team
+	t.ID, t.CreatedAt, t.UpdatedAt = r.store.stamp(uuid.Nil, time.Time{})
+	t.Members = nil
+	team.ID, team.CreatedAt, team.UpdatedAt = t.ID, t.CreatedAt, t.UpdatedAt
+	r.store.teams[t.ID] = &t
+
+	return nil
+}
+
+// GetAll
+func (r *TeamRepository) GetAll(ctx context.Context) ([]*model.Team, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	teams := []*model.Team{}
+	for _, team := range r.store.teams {
+		t := *team
+		teams = append(teams, &t)
+	}
+
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].Name < teams[j].Name
+	})
+
+	return teams, nil
+}
+
+// GetByID
+func (r *TeamRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Team, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	team, ok := r.store.teams[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	t := *team
+	return &t, nil
+}
+
+// Update
+func (r *TeamRepository) Update(ctx context.Context, team *model.Team) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, err := r.current(team.ID, team.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	for _, other := range r.store.teams {
+		if other.ID != team.ID && other.Name == team.Name {
+			return uniqueViolation("teams", "teams_name_key")
+		}
+	}
+
+	existing.Name = team.Name
+	existing.Description = team.Description
+	existing.UpdatedAt = r.store.Now()
+	team.CreatedAt, team.UpdatedAt = existing.CreatedAt, existing.UpdatedAt
+
+	return nil
+}
+
+// Delete
+func (r *TeamRepository) Delete(ctx context.Context, id uuid.UUID, updatedAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, err := r.current(id, updatedAt); err != nil {
+		return err
+	}
+
+	now := r.store.Now()
+	for _, customer := range r.store.customers {
+		if customer.TeamID != nil && *customer.TeamID == id {
+			customer.TeamID = nil
+			customer.UpdatedAt = now
+		}
+	}
+	for _, opportunity := range r.store.opportunities {
+		if opportunity.TeamID != nil && *opportunity.TeamID == id {
+			opportunity.TeamID = nil
+			opportunity.UpdatedAt = now
+		}
+	}
+
+	delete(r.store.teamMembers, id)
+	delete(r.store.teams, id)
+
+	return nil
+}
+
+// current returns the stored team while its updated_at still equals
+// updatedAt. The caller must hold the lock.
+func (r *TeamRepository) current(id uuid.UUID, updatedAt time.Time) (*model.Team, error) {
+	team, ok := r.store.teams[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if !team.UpdatedAt.Equal(updatedAt) {
+		return nil, repository.ErrStale
+	}
+
+	return team, nil
+}
+
+// GetMembers
+func (r *TeamRepository) GetMembers(ctx context.Context, teamID uuid.UUID) ([]*model.TeamMember, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	members := []*model.TeamMember{}
+	for userID, member := range r.store.teamMembers[teamID] {
+		user, ok := r.store.users[userID]
+		if !ok || user.DeletedAt != nil {
+			continue
+		}
+
+		m := *member
+		m.Username, m.FirstName, m.LastName = user.Username, user.FirstName, user.LastName
+		members = append(members, &m)
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		a, b := members[i], members[j]
+		if a.Role != b.Role {
+			return a.Role < b.Role
+		}
+		if a.LastName != b.LastName {
+			return a.LastName < b.LastName
+		}
+		return a.FirstName < b.FirstName
+	})
+
+	return members, nil
+}
+
+// GetMemberRole
+func (r *TeamRepository) GetMemberRole(ctx context.Context, teamID, userID uuid.UUID) (string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	member, ok := r.store.teamMembers[teamID][userID]
+	if !ok {
+		return "", repository.ErrNotFound
+	}
+
+	return member.Role, nil
+}
+
+// SetMember
+func (r *TeamRepository) SetMember(ctx context.Context, teamID, userID uuid.UUID, role string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	team, ok := r.store.teams[teamID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if user, ok := r.store.users[userID]; !ok || user.DeletedAt != nil {
+		return repository.ErrNotFound
+	}
+
+	r.store.setTeamMember(teamID, userID, role)
+	team.UpdatedAt = r.store.Now()
+
+	return nil
+}
+
+// RemoveMember
+func (r *TeamRepository) RemoveMember(ctx context.Context, teamID, userID uuid.UUID, updatedAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	team, err := r.current(teamID, updatedAt)
+	if err != nil {
+		return err
+	}
+	if _, ok := r.store.teamMembers[teamID][userID]; !ok {
+		return repository.ErrNotFound
+	}
+
+	delete(r.store.teamMembers[teamID], userID)
+	team.UpdatedAt = r.store.Now()
+
+	return nil
+}
+
+// AssignRecords
+func (r *TeamRepository) AssignRecords(ctx context.Context, teamID uuid.UUID, req model.TeamRecordsRequest, assign, ownedOnly bool, updatedAt *time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	team, ok := r.store.teams[teamID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if updatedAt != nil && !team.UpdatedAt.Equal(*updatedAt) {
+		return repository.ErrStale
+	}
+
+	now := r.store.Now()
+	team.UpdatedAt = now
+	update := func(recordTeamID **uuid.UUID, assignedTo *uuid.UUID, updatedAt *time.Time) {
+		if !assign && (*recordTeamID == nil || **recordTeamID != teamID) {
+			return
+		}
+		if ownedOnly && !r.store.ownedByTeam(teamID, *recordTeamID, assignedTo) {
+			return
+		}
+
+		if assign {
+			id := teamID
+			*recordTeamID = &id
+		} else {
+			*recordTeamID = nil
+		}
+		*updatedAt = now
+	}
+
+	for _, id := range req.CustomerIDs {
+		if c, ok := r.store.customers[id]; ok {
+			update(&c.TeamID, c.AssignedTo, &c.UpdatedAt)
+		}
+	}
+	for _, id := range req.OpportunityIDs {
+		if o, ok := r.store.opportunities[id]; ok {
+			update(&o.TeamID, o.AssignedTo, &o.UpdatedAt)
+		}
+	}
+
+	return nil
+}
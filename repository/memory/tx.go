@@ -0,0 +1,16 @@
+package memory
+
+import (
+	"context"
+
+	"customize_crm/repository"
+)
+
+var _ repository.Transactor = (*Transactor)(nil)
+
+type Transactor struct{}
+
+// WithinTx
+func (t *Transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
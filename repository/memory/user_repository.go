@@ -0,0 +1,449 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"customize_crm/listquery"
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+var _ repository.UserRepository = (*UserRepository)(nil)
+
+type UserRepository struct {
+	store *Store
+}
+
+// GetByID
+func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok || user.DeletedAt != nil {
+		return nil, repository.ErrNotFound
+	}
+
+	u := *user
+	return &u, nil
+}
+
+// GetDeletedByID
+func (r *UserRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok || user.DeletedAt == nil {
+		return nil, repository.ErrNotFound
+	}
+
+	u := *user
+	return &u, nil
+}
+
+// GetByUsername
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.Username == username && user.DeletedAt == nil {
+			u := *user
+			return &u, nil
+		}
+	}
+
+	return nil, repository.ErrNotFound
+}
+
+// GetAll
+func (r *UserRepository) GetAll(ctx context.Context) ([]*model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var users []*model.User
+	for _, user := range r.store.users {
+		if user.DeletedAt == nil {
+			users = append(users, directoryUser(user))
+		}
+	}
+
+	return users, nil
+}
+
+// List
+func (r *UserRepository) List(ctx context.Context, params repository.UserListParams) (*model.UserListResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := []*model.User{}
+	for _, user := range r.store.users {
+		if r.matches(user, params) && listquery.Match(user, params.Query.Filters) {
+			users = append(users, directoryUser(user))
+		}
+	}
+
+	users, pagination, err := page(users, repository.PageParams{Limit: params.Limit, Cursor: params.Cursor, Query: params.Query})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.UserListResponse{Data: users, Pagination: pagination}, nil
+}
+
+func (r *UserRepository) matches(user *model.User, params repository.UserListParams) bool {
+	if (user.DeletedAt != nil) != params.Deleted {
+		return false
+	}
+	if params.RoleID != nil && user.RoleID != *params.RoleID {
+		return false
+	}
+	if params.RoleName != "" {
+		role, ok := r.store.roles[user.RoleID]
+		if !ok || role.Name != params.RoleName {
+			return false
+		}
+	}
+	if params.Department != "" && (user.Department == nil || *user.Department != params.Department) {
+		return false
+	}
+	if params.IsActive != nil && user.IsActive != *params.IsActive {
+		return false
+	}
+	if params.CreatedFrom != nil && user.CreatedAt.Before(*params.CreatedFrom) {
+		return false
+	}
+	if params.CreatedTo != nil && !user.CreatedAt.Before(*params.CreatedTo) {
+		return false
+	}
+	if params.Search != "" {
+		search := strings.ToLower(params.Search)
+		text := strings.ToLower(strings.Join([]string{user.FirstName, user.LastName, user.Username, user.Email}, " "))
+		if !strings.Contains(text, search) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Create
+func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := r.unique(user); err != nil {
+		return err
+	}
+
+	u := *user
+	u.ID, u.CreatedAt, u.UpdatedAt = r.store.stamp(u.ID, time.Time{})
+	user.ID, user.CreatedAt, user.UpdatedAt = u.ID, u.CreatedAt, u.UpdatedAt
+	r.store.users[u.ID] = &u
+
+	return nil
+}
+
+// unique mirrors the partial unique indexes on username and email, which
+// leave deleted users out. The caller must hold the lock.
+func (r *UserRepository) unique(user *model.User) error {
+	for _, existing := range r.store.users {
+		if existing.ID == user.ID || existing.DeletedAt != nil {
+			continue
+		}
+		if existing.Username == user.Username {
+			return uniqueViolation("users", "users_username_key")
+		}
+		if existing.Email == user.Email {
+			return uniqueViolation("users", "users_email_key")
+		}
+	}
+
+	return nil
+}
+
+// Update
+func (r *UserRepository) Update(ctx context.Context, user *model.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.users[user.ID]
+	if !ok || existing.DeletedAt != nil {
+		return repository.ErrNotFound
+	}
+	if !existing.UpdatedAt.Equal(user.UpdatedAt) {
+		return repository.ErrStale
+	}
+
+	existing.FirstName = user.FirstName
+	existing.LastName = user.LastName
+	existing.Department = user.Department
+	existing.RoleID = user.RoleID
+	existing.IsActive = user.IsActive
+	existing.UpdatedAt = r.store.Now()
+	user.UpdatedAt = existing.UpdatedAt
+
+	return nil
+}
+
+// UpdatePasswordHash
+func (r *UserRepository) UpdatePasswordHash(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok || user.DeletedAt != nil {
+		return repository.ErrNotFound
+	}
+
+	user.PasswordHash = passwordHash
+	user.UpdatedAt = r.store.Now()
+
+	return nil
+}
+
+// Activate
+func (r *UserRepository) Activate(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok || user.DeletedAt != nil {
+		return repository.ErrNotFound
+	}
+
+	user.PasswordHash = passwordHash
+	user.IsActive = true
+	user.UpdatedAt = r.store.Now()
+
+	return nil
+}
+
+// Delete
+func (r *UserRepository) Delete(ctx context.Context, ids []uuid.UUID, reassignTo *uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	deleting := map[uuid.UUID]bool{}
+	for _, id := range ids {
+		deleting[id] = true
+	}
+
+	if reassignTo != nil {
+		id := *reassignTo
+		reassignTo = &id
+
+		target, ok := r.store.users[id]
+		if deleting[id] || !ok || target.DeletedAt != nil || !target.IsActive {
+			return repository.ErrInvalidReassignTarget
+		}
+	}
+
+	for id := range deleting {
+		user, ok := r.store.users[id]
+		if !ok || user.DeletedAt != nil {
+			return repository.ErrNotFound
+		}
+	}
+
+	now := r.store.Now()
+	reassigned := func(owner *uuid.UUID) bool {
+		return owner != nil && deleting[*owner]
+	}
+
+	for id := range deleting {
+		r.store.users[id].DeletedAt = &now
+		r.store.users[id].UpdatedAt = now
+	}
+	for _, user := range r.store.users {
+		if user.DeletedAt == nil && reassigned(user.ManagerID) {
+			user.ManagerID, user.UpdatedAt = reassignTo, now
+		}
+	}
+	for _, customer := range r.store.customers {
+		if reassigned(customer.AssignedTo) {
+			customer.AssignedTo, customer.UpdatedAt = reassignTo, now
+		}
+	}
+	for _, opportunity := range r.store.opportunities {
+		if reassigned(opportunity.AssignedTo) && opportunity.Status == model.OpportunityStatusOpen {
+			opportunity.AssignedTo, opportunity.UpdatedAt = reassignTo, now
+		}
+	}
+	for _, task := range r.store.tasks {
+		if reassigned(task.AssignedTo) && task.CompletedAt == nil {
+			task.AssignedTo, task.UpdatedAt = reassignTo, now
+		}
+	}
+
+	return nil
+}
+
+// Restore
+func (r *UserRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok || user.DeletedAt == nil {
+		return repository.ErrNotFound
+	}
+	if err := r.unique(user); err != nil {
+		return err
+	}
+
+	user.DeletedAt = nil
+	user.UpdatedAt = r.store.Now()
+
+	return nil
+}
+
+// SetManager
+func (r *UserRepository) SetManager(ctx context.Context, userID uuid.UUID, managerID *uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if managerID != nil {
+		if *managerID == userID {
+			return repository.ErrManagerCycle
+		}
+
+		manager, ok := r.store.users[*managerID]
+		if !ok || manager.DeletedAt != nil {
+			return repository.ErrNotFound
+		}
+
+		seen := map[uuid.UUID]bool{}
+		for current := manager; current != nil && !seen[current.ID]; {
+			if current.ID == userID {
+				return repository.ErrManagerCycle
+			}
+			seen[current.ID] = true
+
+			if current.ManagerID == nil {
+				break
+			}
+			current = r.store.users[*current.ManagerID]
+		}
+	}
+
+	user, ok := r.store.users[userID]
+	if !ok || user.DeletedAt != nil {
+		return repository.ErrNotFound
+	}
+
+	if managerID != nil {
+		id := *managerID
+		managerID = &id
+	}
+
+	user.ManagerID = managerID
+	user.UpdatedAt = r.store.Now()
+
+	return nil
+}
+
+// GetReportIDs
+func (r *UserRepository) GetReportIDs(ctx context.Context, userIDs ...uuid.UUID) ([]uuid.UUID, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	roots := map[uuid.UUID]bool{}
+	for _, id := range userIDs {
+		roots[id] = true
+	}
+
+	seen := map[uuid.UUID]bool{}
+	queue := userIDs
+	reports := []uuid.UUID{}
+
+	for len(queue) > 0 {
+		managerID := queue[0]
+		queue = queue[1:]
+
+		for _, user := range r.store.users {
+			if user.DeletedAt != nil || user.ManagerID == nil || *user.ManagerID != managerID || seen[user.ID] {
+				continue
+			}
+
+			seen[user.ID] = true
+			queue = append(queue, user.ID)
+			if !roots[user.ID] {
+				reports = append(reports, user.ID)
+			}
+		}
+	}
+
+	return reports, nil
+}
+
+// GetActiveDelegatorIDs
+func (r *UserRepository) GetActiveDelegatorIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	now := r.store.Now()
+	seen := map[uuid.UUID]bool{}
+	delegators := []uuid.UUID{}
+
+	for _, d := range r.store.delegations {
+		if d.DelegateID != userID || d.RevokedAt != nil || now.Before(d.StartsAt) || !now.Before(d.EndsAt) {
+			continue
+		}
+
+		delegator, ok := r.store.users[d.DelegatorID]
+		if !ok || delegator.DeletedAt != nil || seen[d.DelegatorID] {
+			continue
+		}
+
+		seen[d.DelegatorID] = true
+		delegators = append(delegators, d.DelegatorID)
+	}
+
+	return delegators, nil
+}
+
+// GetOrgChartNodes
+func (r *UserRepository) GetOrgChartNodes(ctx context.Context) ([]*model.OrgChartNode, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	nodes := []*model.OrgChartNode{}
+	for _, user := range r.store.users {
+		if user.DeletedAt != nil {
+			continue
+		}
+
+		nodes = append(nodes, &model.OrgChartNode{
+			ID:         user.ID,
+			Username:   user.Username,
+			FirstName:  user.FirstName,
+			LastName:   user.LastName,
+			Department: user.Department,
+			ManagerID:  user.ManagerID,
+			Reports:    []*model.OrgChartNode{},
+		})
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].LastName != nodes[j].LastName {
+			return nodes[i].LastName < nodes[j].LastName
+		}
+		return nodes[i].FirstName < nodes[j].FirstName
+	})
+
+	return nodes, nil
+}
+
+// directoryUser copies a user without the password hash.
+func directoryUser(user *model.User) *model.User {
+	u := *user
+	u.PasswordHash = ""
+	return &u
+}
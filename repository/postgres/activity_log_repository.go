@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ repository.ActivityLogRepository = (*ActivityLogRepository)(nil)
+
+type ActivityLogRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewActivityLogRepository(db *pgxpool.Pool) *ActivityLogRepository {
+	return &ActivityLogRepository{db: db}
+}
+
+// Create
+func (r *ActivityLogRepository) Create(ctx context.Context, entry *model.ActivityLog) error {
+	query := `
+		INSERT INTO activity_logs (user_id, actor_id, activity_type, entity_type, entity_id, description, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		entry.UserID, entry.ActorID, entry.ActivityType, entry.EntityType,
+		entry.EntityID, entry.Description, entry.Metadata,
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
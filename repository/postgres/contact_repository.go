@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ repository.ContactRepository = (*ContactRepository)(nil)
+
+type ContactRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewContactRepository(db *pgxpool.Pool) *ContactRepository {
+	return &ContactRepository{db: db}
+}
+
+// ListByCustomers
+func (r *ContactRepository) ListByCustomers(ctx context.Context, customerIDs []uuid.UUID) ([]*model.Contact, error) {
+	query := `
+		SELECT id, customer_id, first_name, last_name, position, email, phone, mobile,
+			   is_primary, created_at, updated_at, notes
+		FROM contacts
+		WHERE customer_id = ANY($1)
+		ORDER BY is_primary DESC, last_name, first_name
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, customerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Contact, error) {
+		var c model.Contact
+		err := row.Scan(
+			&c.ID, &c.CustomerID, &c.FirstName, &c.LastName, &c.Position, &c.Email, &c.Phone, &c.Mobile,
+			&c.IsPrimary, &c.CreatedAt, &c.UpdatedAt, &c.Notes,
+		)
+		return &c, err
+	})
+}
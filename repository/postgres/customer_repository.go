@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ repository.CustomerRepository = (*CustomerRepository)(nil)
+
+type CustomerRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCustomerRepository(db *pgxpool.Pool) *CustomerRepository {
+	return &CustomerRepository{db: db}
+}
+
+const customerColumns = `
+	c.id, c.company_name, c.industry, c.address, c.city, c.province, c.postal_code,
+	c.phone, c.website, c.customer_status, c.customer_type, c.assigned_to, c.team_id,
+	c.created_at, c.updated_at, c.created_by, c.notes, c.annual_revenue, c.tags
+`
+
+// List returns the customers visible within scope that match the list
+// query, newest first unless it sets another order.
+func (r *CustomerRepository) List(ctx context.Context, scope *model.VisibilityScope, params repository.PageParams) (*model.CustomerListResponse, error) {
+	var b queryBuilder
+	b.visible(scope, "c.assigned_to", "c.created_by")
+	if params.AssignedTo != nil {
+		b.where("c.assigned_to = " + b.arg(*params.AssignedTo))
+	}
+	b.filter("c", params.Query.Filters)
+
+	var total int64
+	if err := conn(ctx, r.db).QueryRow(ctx, "SELECT COUNT(*) FROM customers c "+b.whereClause(), b.args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	tail, err := b.page(params, "c")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, r.db).Query(ctx, "SELECT "+customerColumns+" FROM customers c "+b.whereClause()+" "+tail, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	customers := []*model.Customer{}
+
+	for rows.Next() {
+		customer, err := scanCustomer(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		customers = append(customers, customer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	customers, pagination := pageInfo(params, total, customers)
+
+	return &model.CustomerListResponse{Data: customers, Pagination: pagination}, nil
+}
+
+// GetByIDs
+func (r *CustomerRepository) GetByIDs(ctx context.Context, scope *model.VisibilityScope, ids []uuid.UUID) ([]*model.Customer, error) {
+	var b queryBuilder
+	b.where("c.id = ANY(" + b.arg(ids) + ")")
+	b.visible(scope, "c.assigned_to", "c.created_by")
+
+	rows, err := conn(ctx, r.db).Query(ctx, "SELECT "+customerColumns+" FROM customers c "+b.whereClause(), b.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Customer, error) {
+		return scanCustomer(row)
+	})
+}
+
+func scanCustomer(row pgx.Row) (*model.Customer, error) {
+	var c model.Customer
+
+	err := row.Scan(
+		&c.ID, &c.CompanyName, &c.Industry, &c.Address, &c.City, &c.Province, &c.PostalCode,
+		&c.Phone, &c.Website, &c.CustomerStatus, &c.CustomerType, &c.AssignedTo, &c.TeamID,
+		&c.CreatedAt, &c.UpdatedAt, &c.CreatedBy, &c.Notes, &c.AnnualRevenue, &c.Tags,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
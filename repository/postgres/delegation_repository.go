@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ repository.DelegationRepository = (*DelegationRepository)(nil)
+
+type DelegationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewDelegationRepository(db *pgxpool.Pool) *DelegationRepository {
+	return &DelegationRepository{db: db}
+}
+
+const delegationColumns = `
+	id, delegator_id, delegate_id, starts_at, ends_at, reason, created_by, created_at, updated_at, revoked_at
+`
+
+// Create
+func (r *DelegationRepository) Create(ctx context.Context, delegation *model.Delegation) error {
+	query := `
+		INSERT INTO user_delegations (delegator_id, delegate_id, starts_at, ends_at, reason, created_by)
+		SELECT $1, u.id, $3, $4, $5, $6
+		FROM users u
+		WHERE u.id = $2 AND u.is_active = TRUE AND u.deleted_at IS NULL
+		RETURNING id, created_at, updated_at
+	`
+
+	err := conn(ctx, r.db).QueryRow(ctx, query,
+		delegation.DelegatorID, delegation.DelegateID, delegation.StartsAt,
+		delegation.EndsAt, delegation.Reason, delegation.CreatedBy,
+	).Scan(&delegation.ID, &delegation.CreatedAt, &delegation.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+
+	return err
+}
+
+// ListForUser
+func (r *DelegationRepository) ListForUser(ctx context.Context, userID uuid.UUID) ([]*model.Delegation, error) {
+	query := `
+		SELECT ` + delegationColumns + `
+		FROM user_delegations
+		WHERE delegator_id = $1 OR delegate_id = $1
+		ORDER BY starts_at DESC
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Delegation, error) {
+		return scanDelegation(row)
+	})
+}
+
+// GetByID
+func (r *DelegationRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Delegation, error) {
+	query := `SELECT ` + delegationColumns + ` FROM user_delegations WHERE id = $1`
+
+	delegation, err := scanDelegation(conn(ctx, r.db).QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+
+	return delegation, err
+}
+
+// Revoke
+func (r *DelegationRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE user_delegations
+		SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP),
+			updated_at = CASE WHEN revoked_at IS NULL THEN CURRENT_TIMESTAMP ELSE updated_at END
+		WHERE id = $1
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func scanDelegation(row pgx.Row) (*model.Delegation, error) {
+	var delegation model.Delegation
+
+	err := row.Scan(
+		&delegation.ID, &delegation.DelegatorID, &delegation.DelegateID, &delegation.StartsAt,
+		&delegation.EndsAt, &delegation.Reason, &delegation.CreatedBy, &delegation.CreatedAt,
+		&delegation.UpdatedAt, &delegation.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &delegation, nil
+}
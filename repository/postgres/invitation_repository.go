@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ repository.InvitationRepository = (*InvitationRepository)(nil)
+
+type InvitationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewInvitationRepository(db *pgxpool.Pool) *InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+const invitationColumns = `
+	i.id, i.user_id, u.email, i.invited_by, i.expires_at, i.accepted_at, i.revoked_at, i.created_at, i.updated_at
+`
+
+// Create
+func (r *InvitationRepository) Create(ctx context.Context, invitation *model.Invitation, tokenHash string) error {
+	query := `
+		INSERT INTO user_invitations (user_id, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		invitation.UserID, tokenHash, invitation.InvitedBy, invitation.ExpiresAt,
+	).Scan(&invitation.ID, &invitation.CreatedAt, &invitation.UpdatedAt)
+}
+
+// GetAll
+func (r *InvitationRepository) GetAll(ctx context.Context) ([]*model.Invitation, error) {
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM user_invitations i
+		JOIN users u ON u.id = i.user_id
+		ORDER BY i.created_at DESC
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Invitation, error) {
+		return scanInvitation(row)
+	})
+}
+
+// GetByID
+func (r *InvitationRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Invitation, error) {
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM user_invitations i
+		JOIN users u ON u.id = i.user_id
+		WHERE i.id = $1
+	`
+
+	invitation, err := scanInvitation(conn(ctx, r.db).QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+
+	return invitation, err
+}
+
+// Renew
+func (r *InvitationRepository) Renew(ctx context.Context, id uuid.UUID, tokenHash string, expiresAt time.Time) error {
+	query := `
+		UPDATE user_invitations
+		SET token_hash = $1, expires_at = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND accepted_at IS NULL AND revoked_at IS NULL
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, tokenHash, expiresAt, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// Revoke
+func (r *InvitationRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE user_invitations
+		SET revoked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// Accept
+func (r *InvitationRepository) Accept(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	query := `
+		UPDATE user_invitations
+		SET accepted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1
+		  AND accepted_at IS NULL
+		  AND revoked_at IS NULL
+		  AND expires_at > CURRENT_TIMESTAMP
+		RETURNING user_id
+	`
+
+	var userID uuid.UUID
+	err := conn(ctx, r.db).QueryRow(ctx, query, tokenHash).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, repository.ErrNotFound
+	}
+
+	return userID, err
+}
+
+func scanInvitation(row pgx.Row) (*model.Invitation, error) {
+	var invitation model.Invitation
+
+	err := row.Scan(
+		&invitation.ID, &invitation.UserID, &invitation.Email, &invitation.InvitedBy,
+		&invitation.ExpiresAt, &invitation.AcceptedAt, &invitation.RevokedAt, &invitation.CreatedAt,
+		&invitation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
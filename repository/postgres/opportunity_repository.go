@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ repository.OpportunityRepository = (*OpportunityRepository)(nil)
+
+type OpportunityRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewOpportunityRepository(db *pgxpool.Pool) *OpportunityRepository {
+	return &OpportunityRepository{db: db}
+}
+
+const opportunityColumns = `
+	o.id, o.name, o.customer_id, o.contact_id, o.amount, o.stage, o.probability,
+	o.expected_close_date, o.assigned_to, o.team_id, o.created_at, o.updated_at, o.created_by,
+	o.source, o.description, o.status, o.reason_lost
+`
+
+// teamOwnersTemplate matches records owned by the team in $1: either
+// explicitly assigned to the team or assigned to one of its members.
+const teamOwnersTemplate = `(%[1]s.team_id = $1 OR %[1]s.assigned_to IN (SELECT user_id FROM team_members WHERE team_id = $1))`
+
+// List returns the opportunities visible within scope, newest first.
+func (r *OpportunityRepository) List(ctx context.Context, scope *model.VisibilityScope, params repository.PageParams) (*model.OpportunityListResponse, error) {
+	var b queryBuilder
+	b.visible(scope, "o.assigned_to", "o.created_by")
+	if params.AssignedTo != nil {
+		b.where("o.assigned_to = " + b.arg(*params.AssignedTo))
+	}
+
+	return r.list(ctx, &b, params)
+}
+
+// ListByTeam lists the team's opportunities, newest first.
+func (r *OpportunityRepository) ListByTeam(ctx context.Context, teamID uuid.UUID, params repository.PageParams) (*model.OpportunityListResponse, error) {
+	b := queryBuilder{args: []interface{}{teamID}}
+	b.where(fmt.Sprintf(teamOwnersTemplate, "o"))
+	if params.AssignedTo != nil {
+		b.where("o.assigned_to = " + b.arg(*params.AssignedTo))
+	}
+
+	return r.list(ctx, &b, params)
+}
+
+// GetByIDs
+func (r *OpportunityRepository) GetByIDs(ctx context.Context, scope *model.VisibilityScope, ids []uuid.UUID) ([]*model.Opportunity, error) {
+	var b queryBuilder
+	b.where("o.id = ANY(" + b.arg(ids) + ")")
+	b.visible(scope, "o.assigned_to", "o.created_by")
+
+	return r.collect(ctx, &b, "")
+}
+
+// ListByCustomers
+func (r *OpportunityRepository) ListByCustomers(ctx context.Context, scope *model.VisibilityScope, customerIDs []uuid.UUID) ([]*model.Opportunity, error) {
+	var b queryBuilder
+	b.where("o.customer_id = ANY(" + b.arg(customerIDs) + ")")
+	b.visible(scope, "o.assigned_to", "o.created_by")
+
+	return r.collect(ctx, &b, "ORDER BY o.created_at DESC, o.id DESC")
+}
+
+func (r *OpportunityRepository) collect(ctx context.Context, b *queryBuilder, tail string) ([]*model.Opportunity, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, "SELECT "+opportunityColumns+" FROM opportunities o "+b.whereClause()+" "+tail, b.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Opportunity, error) {
+		return scanOpportunity(row)
+	})
+}
+
+func (r *OpportunityRepository) list(ctx context.Context, b *queryBuilder, params repository.PageParams) (*model.OpportunityListResponse, error) {
+	b.filter("o", params.Query.Filters)
+
+	var total int64
+	if err := conn(ctx, r.db).QueryRow(ctx, "SELECT COUNT(*) FROM opportunities o "+b.whereClause(), b.args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	tail, err := b.page(params, "o")
+	if err != nil {
+		return nil, err
+	}
+
+	opportunities, err := r.collect(ctx, b, tail)
+	if err != nil {
+		return nil, err
+	}
+
+	opportunities, pagination := pageInfo(params, total, opportunities)
+
+	return &model.OpportunityListResponse{Data: opportunities, Pagination: pagination}, nil
+}
+
+// Pipeline
+func (r *OpportunityRepository) Pipeline(ctx context.Context, scope *model.VisibilityScope) ([]*model.PipelineStageSummary, error) {
+	var b queryBuilder
+	b.where("o.status = " + b.arg(model.OpportunityStatusOpen))
+	b.visible(scope, "o.assigned_to", "o.created_by")
+
+	return r.pipeline(ctx, &b)
+}
+
+// TeamPipeline summarizes the team's open opportunities by stage.
+func (r *OpportunityRepository) TeamPipeline(ctx context.Context, teamID uuid.UUID) ([]*model.PipelineStageSummary, error) {
+	b := queryBuilder{args: []interface{}{teamID}}
+	b.where("o.status = " + b.arg(model.OpportunityStatusOpen))
+	b.where(fmt.Sprintf(teamOwnersTemplate, "o"))
+
+	return r.pipeline(ctx, &b)
+}
+
+func (r *OpportunityRepository) pipeline(ctx context.Context, b *queryBuilder) ([]*model.PipelineStageSummary, error) {
+	query := `
+		SELECT o.stage, COUNT(*),
+			   COALESCE(SUM(o.amount), 0)::float8,
+			   COALESCE(SUM(o.amount * COALESCE(o.probability, 0) / 100.0), 0)::float8
+		FROM opportunities o
+		` + b.whereClause() + `
+		GROUP BY o.stage
+		ORDER BY o.stage
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.PipelineStageSummary, error) {
+		var summary model.PipelineStageSummary
+		err := row.Scan(&summary.Stage, &summary.Count, &summary.TotalAmount, &summary.WeightedAmount)
+		return &summary, err
+	})
+}
+
+func scanOpportunity(row pgx.Row) (*model.Opportunity, error) {
+	var o model.Opportunity
+
+	err := row.Scan(
+		&o.ID, &o.Name, &o.CustomerID, &o.ContactID, &o.Amount, &o.Stage, &o.Probability,
+		&o.ExpectedCloseDate, &o.AssignedTo, &o.TeamID, &o.CreatedAt, &o.UpdatedAt, &o.CreatedBy,
+		&o.Source, &o.Description, &o.Status, &o.ReasonLost,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ repository.PasskeyRepository = (*PasskeyRepository)(nil)
+
+type PasskeyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPasskeyRepository(db *pgxpool.Pool) *PasskeyRepository {
+	return &PasskeyRepository{db: db}
+}
+
+const passkeyColumns = `
+	id, user_id, credential_id, name, credential, created_at, updated_at, last_used_at
+`
+
+// Create
+func (r *PasskeyRepository) Create(ctx context.Context, passkey *model.Passkey) error {
+	query := `
+		INSERT INTO user_passkeys (user_id, credential_id, name, credential)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		passkey.UserID, passkey.CredentialID, passkey.Name, passkey.Credential,
+	).Scan(&passkey.ID, &passkey.CreatedAt, &passkey.UpdatedAt)
+}
+
+// ListByUser
+func (r *PasskeyRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.Passkey, error) {
+	query := `
+		SELECT ` + passkeyColumns + `
+		FROM user_passkeys
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Passkey, error) {
+		return scanPasskey(row)
+	})
+}
+
+// GetByID
+func (r *PasskeyRepository) GetByID(ctx context.Context, userID, id uuid.UUID) (*model.Passkey, error) {
+	query := `SELECT ` + passkeyColumns + ` FROM user_passkeys WHERE id = $1 AND user_id = $2`
+
+	passkey, err := scanPasskey(conn(ctx, r.db).QueryRow(ctx, query, id, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+
+	return passkey, err
+}
+
+// UpdateCredential
+func (r *PasskeyRepository) UpdateCredential(ctx context.Context, userID uuid.UUID, credentialID, credential []byte) error {
+	query := `
+		UPDATE user_passkeys
+		SET credential = $1, last_used_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE credential_id = $2 AND user_id = $3
+	`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, credential, credentialID, userID)
+	return err
+}
+
+// Rename
+func (r *PasskeyRepository) Rename(ctx context.Context, userID, id uuid.UUID, name string) error {
+	query := `UPDATE user_passkeys SET name = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND user_id = $3`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, name, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete
+func (r *PasskeyRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	query := `DELETE FROM user_passkeys WHERE id = $1 AND user_id = $2`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// CreateSession
+func (r *PasskeyRepository) CreateSession(ctx context.Context, userID *uuid.UUID, data []byte, expiresAt time.Time) (uuid.UUID, error) {
+	query := `
+		INSERT INTO webauthn_sessions (user_id, session_data, expires_at)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+
+	var id uuid.UUID
+	err := conn(ctx, r.db).QueryRow(ctx, query, userID, data, expiresAt).Scan(&id)
+	return id, err
+}
+
+// TakeSession
+func (r *PasskeyRepository) TakeSession(ctx context.Context, id uuid.UUID) (*uuid.UUID, []byte, error) {
+	query := `
+		DELETE FROM webauthn_sessions
+		WHERE id = $1 AND expires_at > CURRENT_TIMESTAMP
+		RETURNING user_id, session_data
+	`
+
+	var owner *uuid.UUID
+	var data []byte
+
+	err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(&owner, &data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return owner, data, nil
+}
+
+func scanPasskey(row pgx.Row) (*model.Passkey, error) {
+	var passkey model.Passkey
+
+	err := row.Scan(
+		&passkey.ID, &passkey.UserID, &passkey.CredentialID, &passkey.Name, &passkey.Credential,
+		&passkey.CreatedAt, &passkey.UpdatedAt, &passkey.LastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &passkey, nil
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ repository.ProductRepository = (*ProductRepository)(nil)
+
+type ProductRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewProductRepository(db *pgxpool.Pool) *ProductRepository {
+	return &ProductRepository{db: db}
+}
+
+const productColumns = `
+	p.id, p.name, p.description, p.sku, p.unit_price, p.category,
+	p.created_at, p.updated_at, p.is_active
+`
+
+// List returns the products matching the list query, newest first unless it
+// sets another order.
+func (r *ProductRepository) List(ctx context.Context, params repository.PageParams) (*model.ProductListResponse, error) {
+	var b queryBuilder
+	b.filter("p", params.Query.Filters)
+
+	var total int64
+	if err := conn(ctx, r.db).QueryRow(ctx, "SELECT COUNT(*) FROM products p "+b.whereClause(), b.args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	tail, err := b.page(params, "p")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, r.db).Query(ctx, "SELECT "+productColumns+" FROM products p "+b.whereClause()+" "+tail, b.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Product, error) {
+		var p model.Product
+		err := row.Scan(
+			&p.ID, &p.Name, &p.Description, &p.SKU, &p.UnitPrice, &p.Category,
+			&p.CreatedAt, &p.UpdatedAt, &p.IsActive,
+		)
+		return &p, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	products, pagination := pageInfo(params, total, products)
+
+	return &model.ProductListResponse{Data: products, Pagination: pagination}, nil
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"customize_crm/listquery"
+	"customize_crm/model"
+	"customize_crm/repository"
+	"customize_crm/utils"
+)
+
+// queryBuilder accumulates WHERE conditions and their positional arguments.
+type queryBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+func (b *queryBuilder) arg(v interface{}) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *queryBuilder) where(condition string) {
+	b.conditions = append(b.conditions, condition)
+}
+
+func (b *queryBuilder) whereClause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// visible restricts the query to rows owned, through any of the given
+// columns, by a user in scope.
+func (b *queryBuilder) visible(scope *model.VisibilityScope, columns ...string) {
+	if scope.All {
+		return
+	}
+
+	ids := b.arg(scope.UserIDs)
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		parts[i] = column + " = ANY(" + ids + ")"
+	}
+
+	b.where("(" + strings.Join(parts, " OR ") + ")")
+}
+
+// sqlTypes are the casts applied to filter and cursor arguments, so values
+// compare with the column's type rather than as text.
+var sqlTypes = map[listquery.Type]string{
+	listquery.String:  "text",
+	listquery.Number:  "numeric",
+	listquery.Bool:    "boolean",
+	listquery.Time:    "timestamptz",
+	listquery.UUID:    "uuid",
+	listquery.Strings: "text",
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// filter adds the conditions of a list query. Columns come from the entity's
+// allow-list, never from the request.
+func (b *queryBuilder) filter(alias string, filters []listquery.Filter) {
+	for _, f := range filters {
+		column := alias + "." + f.Column
+		cast := "::" + sqlTypes[f.Type]
+
+		switch f.Op {
+		case listquery.Eq:
+			b.where(column + " = " + b.arg(f.Value) + cast)
+		case listquery.Ne:
+			b.where(column + " IS DISTINCT FROM " + b.arg(f.Value) + cast)
+		case listquery.Lt:
+			b.where(column + " < " + b.arg(f.Value) + cast)
+		case listquery.Lte:
+			b.where(column + " <= " + b.arg(f.Value) + cast)
+		case listquery.Gt:
+			b.where(column + " > " + b.arg(f.Value) + cast)
+		case listquery.Gte:
+			b.where(column + " >= " + b.arg(f.Value) + cast)
+		case listquery.In:
+			b.where(column + " = ANY(" + b.arg(f.Value) + cast + "[])")
+		case listquery.Contains:
+			if f.Type == listquery.Strings {
+				b.where(column + " @> ARRAY[" + b.arg(f.Value) + cast + "]")
+			} else {
+				b.where(column + ` ILIKE '%' || ` + b.arg(likeEscaper.Replace(f.Value.(string))) + ` || '%'`)
+			}
+		case listquery.Any:
+			b.where(column + " && " + b.arg(f.Value) + cast + "[]")
+		case listquery.All:
+			b.where(column + " @> " + b.arg(f.Value) + cast + "[]")
+		case listquery.Null:
+			if f.Value.(bool) {
+				b.where(column + " IS NULL")
+			} else {
+				b.where(column + " IS NOT NULL")
+			}
+		}
+	}
+}
+
+// page applies keyset pagination in the order of the list query, newest
+// first by default, with the ID as tie-breaker, and returns the ORDER BY and
+// LIMIT clauses.
+func (b *queryBuilder) page(params repository.PageParams, alias string) (string, error) {
+	sort := params.Query.Order()
+	column := alias + "." + sort.Column
+	direction, comparator := "ASC", ">"
+	if sort.Desc {
+		direction, comparator = "DESC", "<"
+	}
+
+	if params.Cursor != "" {
+		cursor, err := utils.DecodeCursor(params.Cursor)
+		if err != nil {
+			return "", err
+		}
+		if _, err := sort.ParseCursor(cursor.Value); err != nil {
+			return "", err
+		}
+
+		b.where(fmt.Sprintf("(%s, %s.id) %s (%s::%s, %s)",
+			column, alias, comparator, b.arg(cursor.Value), sqlTypes[sort.Type], b.arg(cursor.ID)))
+	}
+
+	return fmt.Sprintf("ORDER BY %[1]s %[2]s, %[3]s.id %[2]s LIMIT %[4]s",
+		column, direction, alias, b.arg(params.Limit+1)), nil
+}
+
+// pageInfo trims the extra look-ahead row and builds the pagination envelope.
+func pageInfo[T any](params repository.PageParams, total int64, items []T) ([]T, model.Pagination) {
+	pagination := model.Pagination{Limit: params.Limit, Total: total}
+	if len(items) <= params.Limit {
+		return items, pagination
+	}
+
+	items = items[:params.Limit]
+	pagination.HasMore = true
+	pagination.NextCursor = utils.EncodeCursor(listquery.Cursor(items[len(items)-1], params.Query.Order()))
+
+	return items, pagination
+}
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"customize_crm/listquery"
+	"customize_crm/model"
+	"customize_crm/repository"
+	"customize_crm/utils"
+
+	"github.com/google/uuid"
+)
+
+func TestQueryBuilderFilter(t *testing.T) {
+	owner := uuid.New()
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		filter listquery.Filter
+		sql    string
+		arg    any
+	}{
+		{"eq", listquery.Filter{Column: "status", Type: listquery.String, Op: listquery.Eq, Value: "open"}, "c.status = $1::text", "open"},
+		{"ne keeps nulls", listquery.Filter{Column: "assigned_to", Type: listquery.UUID, Op: listquery.Ne, Value: owner}, "c.assigned_to IS DISTINCT FROM $1::uuid", owner},
+		{"gte", listquery.Filter{Column: "amount", Type: listquery.Number, Op: listquery.Gte, Value: 10.0}, "c.amount >= $1::numeric", 10.0},
+		{"lt", listquery.Filter{Column: "created_at", Type: listquery.Time, Op: listquery.Lt, Value: since}, "c.created_at < $1::timestamptz", since},
+		{"in", listquery.Filter{Column: "assigned_to", Type: listquery.UUID, Op: listquery.In, Value: []uuid.UUID{owner}}, "c.assigned_to = ANY($1::uuid[])", []uuid.UUID{owner}},
+		{"contains escapes wildcards", listquery.Filter{Column: "name", Type: listquery.String, Op: listquery.Contains, Value: `50%_off\`}, `c.name ILIKE '%' || $1 || '%'`, `50\%\_off\\`},
+		{"array contains", listquery.Filter{Column: "tags", Type: listquery.Strings, Op: listquery.Contains, Value: "vip"}, "c.tags @> ARRAY[$1::text]", "vip"},
+		{"any", listquery.Filter{Column: "tags", Type: listquery.Strings, Op: listquery.Any, Value: []string{"vip"}}, "c.tags && $1::text[]", []string{"vip"}},
+		{"all", listquery.Filter{Column: "tags", Type: listquery.Strings, Op: listquery.All, Value: []string{"vip"}}, "c.tags @> $1::text[]", []string{"vip"}},
+		{"null", listquery.Filter{Column: "closed_at", Type: listquery.Time, Op: listquery.Null, Value: true}, "c.closed_at IS NULL", nil},
+		{"not null", listquery.Filter{Column: "closed_at", Type: listquery.Time, Op: listquery.Null, Value: false}, "c.closed_at IS NOT NULL", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b queryBuilder
+			b.filter("c", []listquery.Filter{tt.filter})
+
+			if got := b.whereClause(); got != "WHERE "+tt.sql {
+				t.Errorf("where = %q, want %q", got, "WHERE "+tt.sql)
+			}
+
+			var want []interface{}
+			if tt.arg != nil {
+				want = []interface{}{tt.arg}
+			}
+			if !reflect.DeepEqual(b.args, want) {
+				t.Errorf("args = %#v, want %#v", b.args, want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilderConditionsShareArguments(t *testing.T) {
+	var b queryBuilder
+	b.where("c.deleted_at IS NULL")
+	b.visible(&model.VisibilityScope{UserIDs: []uuid.UUID{uuid.Nil}}, "c.assigned_to", "c.created_by")
+	b.filter("c", []listquery.Filter{{Column: "status", Type: listquery.String, Op: listquery.Eq, Value: "open"}})
+
+	want := "WHERE c.deleted_at IS NULL AND (c.assigned_to = ANY($1) OR c.created_by = ANY($1)) AND c.status = $2::text"
+	if got := b.whereClause(); got != want {
+		t.Errorf("where = %q, want %q", got, want)
+	}
+	if len(b.args) != 2 {
+		t.Errorf("args = %v, want 2", b.args)
+	}
+
+	var all queryBuilder
+	all.visible(&model.VisibilityScope{All: true}, "c.assigned_to")
+	if all.whereClause() != "" {
+		t.Error("an unrestricted scope added a condition")
+	}
+}
+
+func TestQueryBuilderPage(t *testing.T) {
+	id := uuid.New()
+	amountDesc := listquery.Query{Sort: listquery.Sort{Field: "amount", Column: "amount", Type: listquery.Number, Desc: true}}
+	nameAsc := listquery.Query{Sort: listquery.Sort{Field: "name", Column: "name", Type: listquery.String}}
+
+	tests := []struct {
+		name   string
+		params repository.PageParams
+		where  string
+		order  string
+		args   []interface{}
+	}{
+		{
+			"first page, default sort", repository.PageParams{Limit: 20},
+			"", "ORDER BY c.created_at DESC, c.id DESC LIMIT $1", []interface{}{21},
+		},
+		{
+			"descending after a cursor",
+			repository.PageParams{Limit: 5, Query: amountDesc, Cursor: utils.EncodeCursor(utils.Cursor{Value: "99.5", ID: id})},
+			"WHERE (c.amount, c.id) < ($1::numeric, $2)", "ORDER BY c.amount DESC, c.id DESC LIMIT $3", []interface{}{"99.5", id, 6},
+		},
+		{
+			"ascending after a cursor",
+			repository.PageParams{Limit: 5, Query: nameAsc, Cursor: utils.EncodeCursor(utils.Cursor{Value: "Acme", ID: id})},
+			"WHERE (c.name, c.id) > ($1::text, $2)", "ORDER BY c.name ASC, c.id ASC LIMIT $3", []interface{}{"Acme", id, 6},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b queryBuilder
+			order, err := b.page(tt.params, "c")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := b.whereClause(); got != tt.where {
+				t.Errorf("where = %q, want %q", got, tt.where)
+			}
+			if order != tt.order {
+				t.Errorf("order = %q, want %q", order, tt.order)
+			}
+			if !reflect.DeepEqual(b.args, tt.args) {
+				t.Errorf("args = %#v, want %#v", b.args, tt.args)
+			}
+		})
+	}
+
+	// A cursor whose value does not fit the sort is rejected before any SQL.
+	var b queryBuilder
+	cursor := utils.EncodeCursor(utils.Cursor{Value: "not a number", ID: id})
+	if _, err := b.page(repository.PageParams{Limit: 5, Query: amountDesc, Cursor: cursor}, "c"); !errors.Is(err, utils.ErrInvalidCursor) {
+		t.Errorf("error = %v, want ErrInvalidCursor", err)
+	}
+	if _, err := b.page(repository.PageParams{Limit: 5, Cursor: "%%%"}, "c"); !errors.Is(err, utils.ErrInvalidCursor) {
+		t.Errorf("undecodable cursor: error = %v, want ErrInvalidCursor", err)
+	}
+}
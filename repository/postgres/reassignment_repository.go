@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ repository.ReassignmentRepository = (*ReassignmentRepository)(nil)
+
+type ReassignmentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewReassignmentRepository(db *pgxpool.Pool) *ReassignmentRepository {
+	return &ReassignmentRepository{db: db}
+}
+
+// Reassign
+func (r *ReassignmentRepository) Reassign(ctx context.Context, reassignment *model.Reassignment, entityTypes []string, req model.ReassignmentRequest) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var active bool
+	err = tx.QueryRow(ctx,
+		`SELECT is_active FROM users WHERE id = $1 AND deleted_at IS NULL FOR SHARE`,
+		req.ToUserID,
+	).Scan(&active)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !active) {
+		return repository.ErrInvalidReassignTarget
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entityType := range entityTypes {
+		ids, err := reassignRecords(ctx, tx, entityType, req)
+		if err != nil {
+			return err
+		}
+
+		switch entityType {
+		case model.EntityCustomers:
+			reassignment.CustomerIDs = ids
+		case model.EntityOpportunities:
+			reassignment.OpportunityIDs = ids
+		case model.EntityTasks:
+			reassignment.TaskIDs = ids
+		}
+	}
+
+	if req.DryRun {
+		return nil
+	}
+
+	query := `
+		INSERT INTO record_reassignments
+			(from_user_id, to_user_id, performed_by, filter, customer_ids, opportunity_ids, task_ids)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err = tx.QueryRow(ctx, query,
+		reassignment.FromUserID, reassignment.ToUserID, reassignment.PerformedBy, reassignment.Filter,
+		reassignment.CustomerIDs, reassignment.OpportunityIDs, reassignment.TaskIDs,
+	).Scan(&reassignment.ID, &reassignment.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// reassignRecords updates the matching rows of one entity type and returns
+// their IDs.
+func reassignRecords(ctx context.Context, tx pgx.Tx, entityType string, req model.ReassignmentRequest) ([]uuid.UUID, error) {
+	openOnly := req.OpenOnly == nil || *req.OpenOnly
+
+	var b queryBuilder
+
+	to := b.arg(req.ToUserID)
+	b.where("assigned_to = " + b.arg(req.FromUserID))
+
+	switch entityType {
+	case model.EntityCustomers:
+		if req.CustomerStatus != nil {
+			b.where("customer_status = " + b.arg(*req.CustomerStatus))
+		}
+	case model.EntityOpportunities:
+		if openOnly {
+			b.where("status = " + b.arg(model.OpportunityStatusOpen))
+		}
+		if req.OpportunityStage != nil {
+			b.where("stage = " + b.arg(*req.OpportunityStage))
+		}
+	case model.EntityTasks:
+		if openOnly {
+			b.where("completed_at IS NULL")
+		}
+		if req.TaskStatus != nil {
+			b.where("status = " + b.arg(*req.TaskStatus))
+		}
+		if req.DueBefore != nil {
+			b.where("due_date < " + b.arg(*req.DueBefore))
+		}
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET assigned_to = %s, updated_at = CURRENT_TIMESTAMP
+		%s
+		RETURNING id
+	`, entityType, to, b.whereClause())
+
+	rows, err := tx.Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
+
+// GetAll
+func (r *ReassignmentRepository) GetAll(ctx context.Context) ([]*model.Reassignment, error) {
+	query := `
+		SELECT id, from_user_id, to_user_id, performed_by, filter,
+		       customer_ids, opportunity_ids, task_ids, created_at
+		FROM record_reassignments
+		ORDER BY created_at DESC
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Reassignment, error) {
+		var reassignment model.Reassignment
+		err := row.Scan(
+			&reassignment.ID, &reassignment.FromUserID, &reassignment.ToUserID, &reassignment.PerformedBy,
+			&reassignment.Filter, &reassignment.CustomerIDs, &reassignment.OpportunityIDs,
+			&reassignment.TaskIDs, &reassignment.CreatedAt,
+		)
+		return &reassignment, err
+	})
+}
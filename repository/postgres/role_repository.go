@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ repository.RoleRepository = (*RoleRepository)(nil)
+
+type RoleRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRoleRepository(db *pgxpool.Pool) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// GetByID
+func (r *RoleRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Role, error) {
+	query := `
+		SELECT id, name, description, permissions, created_at, updated_at
+		FROM roles
+		WHERE id = $1
+	`
+
+	return scanRole(conn(ctx, r.db).QueryRow(ctx, query, id))
+}
+
+// GetByName
+func (r *RoleRepository) GetByName(ctx context.Context, name string) (*model.Role, error) {
+	query := `
+		SELECT id, name, description, permissions, created_at, updated_at
+		FROM roles
+		WHERE name = $1
+	`
+
+	return scanRole(conn(ctx, r.db).QueryRow(ctx, query, name))
+}
+
+// CreateIfMissing
+func (r *RoleRepository) CreateIfMissing(ctx context.Context, role *model.Role) (bool, error) {
+	query := `
+		INSERT INTO roles (name, description, permissions)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING id, created_at, updated_at
+	`
+
+	err := conn(ctx, r.db).QueryRow(ctx, query, role.Name, role.Description, role.Permissions).
+		Scan(&role.ID, &role.CreatedAt, &role.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func scanRole(row pgx.Row) (*model.Role, error) {
+	var role model.Role
+
+	err := row.Scan(
+		&role.ID,
+		&role.Name,
+		&role.Description,
+		&role.Permissions,
+		&role.CreatedAt,
+		&role.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
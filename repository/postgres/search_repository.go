@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ repository.SearchRepository = (*SearchRepository)(nil)
+
+type SearchRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSearchRepository(db *pgxpool.Pool) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// searchSource describes how one kind of record is searched. Text is the
+// searched expression; it must match the expression of the full-text and
+// trigram indexes in migration 0012_search, or the indexes are not used.
+// Owners are the columns checked against the visibility scope; products have
+// none and are visible to everyone.
+type searchSource struct {
+	kind       string
+	alias      string
+	from       string
+	title      string
+	subtitle   string
+	customerID string
+	text       string
+	owners     []string
+}
+
+var searchSources = []searchSource{
+	{
+		kind:       model.SearchTypeCustomer,
+		alias:      "c",
+		from:       "customers c",
+		title:      "c.company_name",
+		subtitle:   "c.industry",
+		customerID: "c.id",
+		text:       "c.company_name",
+		owners:     []string{"c.assigned_to", "c.created_by"},
+	},
+	{
+		kind:       model.SearchTypeContact,
+		alias:      "ct",
+		from:       "contacts ct JOIN customers c ON c.id = ct.customer_id",
+		title:      "ct.first_name || ' ' || ct.last_name",
+		subtitle:   "c.company_name",
+		customerID: "ct.customer_id",
+		text: "(ct.first_name || ' ' || ct.last_name || ' ' || coalesce(ct.email, '') || ' ' || " +
+			"coalesce(ct.phone, '') || ' ' || coalesce(ct.mobile, ''))",
+		owners: []string{"c.assigned_to", "c.created_by"},
+	},
+	{
+		kind:       model.SearchTypeOpportunity,
+		alias:      "o",
+		from:       "opportunities o JOIN customers c ON c.id = o.customer_id",
+		title:      "o.name",
+		subtitle:   "c.company_name",
+		customerID: "o.customer_id",
+		text:       "o.name",
+		owners:     []string{"o.assigned_to", "o.created_by"},
+	},
+	{
+		kind:       model.SearchTypeProduct,
+		alias:      "p",
+		from:       "products p",
+		title:      "p.name",
+		subtitle:   "p.sku",
+		customerID: "NULL::uuid",
+		text:       "(p.name || ' ' || coalesce(p.sku, ''))",
+	},
+	{
+		kind:       model.SearchTypeTask,
+		alias:      "t",
+		from:       "tasks t LEFT JOIN customers c ON c.id = t.customer_id",
+		title:      "t.title",
+		subtitle:   "c.company_name",
+		customerID: "t.customer_id",
+		text:       "t.title",
+		owners:     []string{"t.assigned_to", "t.created_by"},
+	},
+}
+
+// Search matches whole words through the full-text indexes and misspelled or
+// partial words through the trigram indexes. The score adds the full-text
+// rank to the trigram word similarity, so exact matches rank first. Only
+// full-text matches are marked in the highlight, and the text is HTML-escaped
+// before the marks are added, so the highlight is safe to render as HTML.
+func (r *SearchRepository) Search(ctx context.Context, scope *model.VisibilityScope, params model.SearchParams) ([]*model.SearchResult, error) {
+	var b queryBuilder
+	q := b.arg(params.Query)
+	limit := b.arg(params.Limit)
+	tsquery := "websearch_to_tsquery('simple', " + q + ")"
+
+	var parts []string
+	for _, src := range searchSources {
+		if len(params.Types) > 0 && !containsString(params.Types, src.kind) {
+			continue
+		}
+
+		vector := "to_tsvector('simple', " + src.text + ")"
+
+		sb := queryBuilder{args: b.args}
+		sb.where("(" + vector + " @@ " + tsquery + " OR " + q + " <% " + src.text + ")")
+		if src.owners != nil {
+			sb.visible(scope, src.owners...)
+		}
+		b.args = sb.args
+
+		parts = append(parts, `(
+			SELECT '`+src.kind+`' AS type, `+src.alias+`.id, `+src.title+` AS title,
+				   `+src.subtitle+`::text AS subtitle, `+src.customerID+` AS customer_id,
+				   ts_headline('simple', `+escapeHTML(src.text)+`, `+tsquery+`, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS highlight,
+				   (ts_rank(`+vector+`, `+tsquery+`) + word_similarity(`+q+`, `+src.text+`))::float8 AS score
+			FROM `+src.from+`
+			`+sb.whereClause()+`
+			ORDER BY score DESC
+			LIMIT `+limit+`
+		)`)
+	}
+
+	if len(parts) == 0 {
+		return []*model.SearchResult{}, nil
+	}
+
+	query := `
+		SELECT type, id, title, subtitle, customer_id, highlight, score
+		FROM (` + strings.Join(parts, " UNION ALL ") + `) results
+		ORDER BY score DESC, title, id
+		LIMIT ` + limit
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.SearchResult, error) {
+		var s model.SearchResult
+		err := row.Scan(&s.Type, &s.ID, &s.Title, &s.Subtitle, &s.CustomerID, &s.Highlight, &s.Score)
+		return &s, err
+	})
+}
+
+// htmlEscapes are applied in order; & must come first so the entities added
+// for the other characters are not escaped again.
+var htmlEscapes = [][2]string{
+	{"&", "&amp;"},
+	{"<", "&lt;"},
+	{">", "&gt;"},
+	{`"`, "&quot;"},
+	{"'", "&#39;"},
+}
+
+// escapeHTML wraps a text expression so that its value is HTML-escaped.
+func escapeHTML(expr string) string {
+	for _, e := range htmlEscapes {
+		expr = "replace(" + expr + ", '" + strings.ReplaceAll(e[0], "'", "''") + "', '" + e[1] + "')"
+	}
+	return expr
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,294 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ repository.TagRepository = (*TagRepository)(nil)
+
+type TagRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTagRepository(db *pgxpool.Pool) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+const tagColumns = `
+	t.id, t.name, t.color, t.created_at, t.updated_at,
+	(SELECT COUNT(*) FROM customers c WHERE c.tags @> ARRAY[t.name::text])
+`
+
+// dedupeTags removes repeated tags from the array expression, keeping the
+// first occurrence of each in place.
+const dedupeTags = `ARRAY(SELECT tag FROM unnest(%s) WITH ORDINALITY AS u(tag, n) GROUP BY tag ORDER BY min(n))`
+
+func scanTag(row pgx.Row) (*model.Tag, error) {
+	var tag model.Tag
+	err := row.Scan(&tag.ID, &tag.Name, &tag.Color, &tag.CreatedAt, &tag.UpdatedAt, &tag.CustomerCount)
+	return &tag, err
+}
+
+// Create
+func (r *TagRepository) Create(ctx context.Context, tag *model.Tag) error {
+	query := `
+		INSERT INTO tags (name, color)
+		VALUES ($1, $2)
+		RETURNING id, created_at, updated_at
+	`
+
+	return conn(ctx, r.db).QueryRow(ctx, query, tag.Name, tag.Color).Scan(&tag.ID, &tag.CreatedAt, &tag.UpdatedAt)
+}
+
+// GetAll
+func (r *TagRepository) GetAll(ctx context.Context) ([]*model.Tag, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, "SELECT "+tagColumns+" FROM tags t ORDER BY t.name")
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Tag, error) {
+		return scanTag(row)
+	})
+}
+
+// GetByID
+func (r *TagRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Tag, error) {
+	tag, err := scanTag(conn(ctx, r.db).QueryRow(ctx, "SELECT "+tagColumns+" FROM tags t WHERE t.id = $1", id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// Update
+func (r *TagRepository) Update(ctx context.Context, tag *model.Tag, updatedAt time.Time) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	oldName, err := lockTag(ctx, tx, tag.ID, &updatedAt)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE tags
+		SET name = $1, color = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING created_at, updated_at
+	`
+
+	if err := tx.QueryRow(ctx, query, tag.Name, tag.Color, tag.ID).Scan(&tag.CreatedAt, &tag.UpdatedAt); err != nil {
+		return err
+	}
+
+	if tag.Name != oldName {
+		query = `
+			UPDATE customers
+			SET tags = ` + fmt.Sprintf(dedupeTags, `array_replace(tags, $1, $2)`) + `, updated_at = CURRENT_TIMESTAMP
+			WHERE tags @> ARRAY[$1::text]
+		`
+		if _, err := tx.Exec(ctx, query, oldName, tag.Name); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Delete
+func (r *TagRepository) Delete(ctx context.Context, id uuid.UUID, updatedAt time.Time) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	name, err := lockTag(ctx, tx, id, &updatedAt)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE customers
+		SET tags = array_remove(tags, $1), updated_at = CURRENT_TIMESTAMP
+		WHERE tags @> ARRAY[$1::text]
+	`
+	if _, err := tx.Exec(ctx, query, name); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM tags WHERE id = $1`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Merge
+func (r *TagRepository) Merge(ctx context.Context, sourceID, targetID uuid.UUID, sourceUpdatedAt time.Time) (*model.Tag, error) {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock in a fixed order so concurrent merges of the same pair cannot
+	// deadlock.
+	first, second := sourceID, targetID
+	if first.String() > second.String() {
+		first, second = second, first
+	}
+	names := map[uuid.UUID]string{}
+	for _, id := range []uuid.UUID{first, second} {
+		var updatedAt *time.Time
+		if id == sourceID {
+			updatedAt = &sourceUpdatedAt
+		}
+		if names[id], err = lockTag(ctx, tx, id, updatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `
+		UPDATE customers
+		SET tags = ` + fmt.Sprintf(dedupeTags, `array_replace(tags, $1, $2)`) + `, updated_at = CURRENT_TIMESTAMP
+		WHERE tags @> ARRAY[$1::text]
+	`
+	if _, err := tx.Exec(ctx, query, names[sourceID], names[targetID]); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM tags WHERE id = $1`, sourceID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE tags SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`, targetID); err != nil {
+		return nil, err
+	}
+
+	target, err := scanTag(tx.QueryRow(ctx, "SELECT "+tagColumns+" FROM tags t WHERE t.id = $1", targetID))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// lockTag locks the tag row and returns its current name. When updatedAt is
+// given, the tag must not have changed since.
+func lockTag(ctx context.Context, tx pgx.Tx, id uuid.UUID, updatedAt *time.Time) (string, error) {
+	var name string
+	var current time.Time
+	err := tx.QueryRow(ctx, `SELECT name, updated_at FROM tags WHERE id = $1 FOR UPDATE`, id).Scan(&name, &current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", repository.ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if updatedAt != nil && !current.Equal(*updatedAt) {
+		return "", repository.ErrStale
+	}
+
+	return name, nil
+}
+
+// UpdateCustomerTags
+func (r *TagRepository) UpdateCustomerTags(ctx context.Context, scope *model.VisibilityScope, req *model.CustomerTagsRequest) (int64, []string, error) {
+	add, remove := req.Add, req.Remove
+	if add == nil {
+		add = []string{}
+	}
+	if remove == nil {
+		remove = []string{}
+	}
+
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// The share locks keep the added tags from being renamed or deleted
+	// before the customers are updated.
+	if len(add) > 0 {
+		rows, err := tx.Query(ctx, `SELECT name FROM tags WHERE name = ANY($1) FOR SHARE`, add)
+		if err != nil {
+			return 0, nil, err
+		}
+		known, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return 0, nil, err
+		}
+		if unknown := missingTags(add, known); len(unknown) > 0 {
+			return 0, unknown, nil
+		}
+	}
+
+	query := `
+		UPDATE customers
+		SET tags = ARRAY(
+				SELECT tag FROM unnest(array_cat(tags, $2::text[])) WITH ORDINALITY AS u(tag, n)
+				WHERE tag <> ALL($3::text[])
+				GROUP BY tag ORDER BY min(n)
+			),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ANY($1)
+		  AND ($4 OR assigned_to = ANY($5) OR created_by = ANY($5))
+		  AND (NOT tags @> $2::text[] OR tags && $3::text[])
+	`
+
+	userIDs := scope.UserIDs
+	if userIDs == nil {
+		userIDs = []uuid.UUID{}
+	}
+
+	result, err := tx.Exec(ctx, query, req.CustomerIDs, add, remove, scope.All, userIDs)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, nil, err
+	}
+
+	return result.RowsAffected(), nil, nil
+}
+
+// missingTags returns the names that are not known, in order.
+func missingTags(names, known []string) []string {
+	exists := map[string]bool{}
+	for _, name := range known {
+		exists[name] = true
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !exists[name] {
+			missing = append(missing, name)
+		}
+	}
+
+	return missing
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ repository.TaskRepository = (*TaskRepository)(nil)
+
+type TaskRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTaskRepository(db *pgxpool.Pool) *TaskRepository {
+	return &TaskRepository{db: db}
+}
+
+const taskColumns = `
+	t.id, t.title, t.description, t.due_date, t.priority, t.status, t.assigned_to,
+	t.created_by, t.customer_id, t.opportunity_id, t.contact_id,
+	t.created_at, t.updated_at, t.completed_at
+`
+
+// List returns the tasks visible within scope, newest first.
+func (r *TaskRepository) List(ctx context.Context, scope *model.VisibilityScope, params repository.PageParams) (*model.TaskListResponse, error) {
+	var b queryBuilder
+	b.visible(scope, "t.assigned_to", "t.created_by")
+	if params.AssignedTo != nil {
+		b.where("t.assigned_to = " + b.arg(*params.AssignedTo))
+	}
+
+	return r.list(ctx, &b, params)
+}
+
+// ListByTeam lists tasks assigned to team members or linked to the team's
+// customers and opportunities, newest first.
+func (r *TaskRepository) ListByTeam(ctx context.Context, teamID uuid.UUID, params repository.PageParams) (*model.TaskListResponse, error) {
+	b := queryBuilder{args: []interface{}{teamID}}
+	b.where(`(
+		t.assigned_to IN (SELECT user_id FROM team_members WHERE team_id = $1)
+		OR t.customer_id IN (SELECT id FROM customers WHERE team_id = $1)
+		OR t.opportunity_id IN (SELECT id FROM opportunities WHERE team_id = $1)
+	)`)
+	if params.AssignedTo != nil {
+		b.where("t.assigned_to = " + b.arg(*params.AssignedTo))
+	}
+
+	return r.list(ctx, &b, params)
+}
+
+func (r *TaskRepository) list(ctx context.Context, b *queryBuilder, params repository.PageParams) (*model.TaskListResponse, error) {
+	b.filter("t", params.Query.Filters)
+
+	var total int64
+	if err := conn(ctx, r.db).QueryRow(ctx, "SELECT COUNT(*) FROM tasks t "+b.whereClause(), b.args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	tail, err := b.page(params, "t")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, r.db).Query(ctx, "SELECT "+taskColumns+" FROM tasks t "+b.whereClause()+" "+tail, b.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Task, error) {
+		return scanTask(row)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, pagination := pageInfo(params, total, tasks)
+
+	return &model.TaskListResponse{Data: tasks, Pagination: pagination}, nil
+}
+
+// StatusSummary summarizes tasks visible within scope by status.
+func (r *TaskRepository) StatusSummary(ctx context.Context, scope *model.VisibilityScope) ([]*model.TaskStatusSummary, error) {
+	var b queryBuilder
+	b.visible(scope, "t.assigned_to", "t.created_by")
+
+	query := `
+		SELECT t.status, COUNT(*),
+			   COUNT(*) FILTER (WHERE t.completed_at IS NULL AND t.due_date < CURRENT_TIMESTAMP)
+		FROM tasks t
+		` + b.whereClause() + `
+		GROUP BY t.status
+		ORDER BY t.status
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.TaskStatusSummary, error) {
+		var summary model.TaskStatusSummary
+		err := row.Scan(&summary.Status, &summary.Count, &summary.Overdue)
+		return &summary, err
+	})
+}
+
+func scanTask(row pgx.Row) (*model.Task, error) {
+	var t model.Task
+
+	err := row.Scan(
+		&t.ID, &t.Title, &t.Description, &t.DueDate, &t.Priority, &t.Status, &t.AssignedTo,
+		&t.CreatedBy, &t.CustomerID, &t.OpportunityID, &t.ContactID,
+		&t.CreatedAt, &t.UpdatedAt, &t.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,295 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ repository.TeamRepository = (*TeamRepository)(nil)
+
+type TeamRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTeamRepository(db *pgxpool.Pool) *TeamRepository {
+	return &TeamRepository{db: db}
+}
+
+// Create
+func (r *TeamRepository) Create(ctx context.Context, team *model.Team) error {
+	query := `
+		INSERT INTO teams (name, description)
+		VALUES ($1, $2)
+		RETURNING id, created_at, updated_at
+	`
+
+	return conn(ctx, r.db).QueryRow(ctx, query, team.Name, team.Description).
+		Scan(&team.ID, &team.CreatedAt, &team.UpdatedAt)
+}
+
+// GetAll
+func (r *TeamRepository) GetAll(ctx context.Context) ([]*model.Team, error) {
+	query := `
+		SELECT id, name, description, created_at, updated_at
+		FROM teams
+		ORDER BY name
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Team, error) {
+		var team model.Team
+		err := row.Scan(&team.ID, &team.Name, &team.Description, &team.CreatedAt, &team.UpdatedAt)
+		return &team, err
+	})
+}
+
+// GetByID
+func (r *TeamRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Team, error) {
+	query := `
+		SELECT id, name, description, created_at, updated_at
+		FROM teams
+		WHERE id = $1
+	`
+
+	var team model.Team
+	err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
+		&team.ID, &team.Name, &team.Description, &team.CreatedAt, &team.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &team, nil
+}
+
+// Update
+func (r *TeamRepository) Update(ctx context.Context, team *model.Team) error {
+	query := `
+		UPDATE teams
+		SET name = $1, description = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND updated_at = $4
+		RETURNING created_at, updated_at
+	`
+
+	err := conn(ctx, r.db).QueryRow(ctx, query, team.Name, team.Description, team.ID, team.UpdatedAt).
+		Scan(&team.CreatedAt, &team.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r.staleOrMissing(ctx, team.ID)
+	}
+
+	return err
+}
+
+// staleOrMissing tells why a conditional write matched no team.
+func (r *TeamRepository) staleOrMissing(ctx context.Context, id uuid.UUID) error {
+	var exists bool
+	if err := conn(ctx, r.db).QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM teams WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return repository.ErrStale
+	}
+	return repository.ErrNotFound
+}
+
+// Delete
+func (r *TeamRepository) Delete(ctx context.Context, id uuid.UUID, updatedAt time.Time) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockTeam(ctx, tx, id, &updatedAt); err != nil {
+		return err
+	}
+
+	queries := []string{
+		`UPDATE customers SET team_id = NULL, updated_at = CURRENT_TIMESTAMP WHERE team_id = $1`,
+		`UPDATE opportunities SET team_id = NULL, updated_at = CURRENT_TIMESTAMP WHERE team_id = $1`,
+		`DELETE FROM team_members WHERE team_id = $1`,
+	}
+
+	for _, query := range queries {
+		if _, err := tx.Exec(ctx, query, id); err != nil {
+			return err
+		}
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM teams WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetMembers
+func (r *TeamRepository) GetMembers(ctx context.Context, teamID uuid.UUID) ([]*model.TeamMember, error) {
+	query := `
+		SELECT m.team_id, m.user_id, m.role, u.username, u.first_name, u.last_name, m.created_at
+		FROM team_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.team_id = $1 AND u.deleted_at IS NULL
+		ORDER BY m.role, u.last_name, u.first_name
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.TeamMember, error) {
+		var member model.TeamMember
+		err := row.Scan(
+			&member.TeamID, &member.UserID, &member.Role, &member.Username,
+			&member.FirstName, &member.LastName, &member.JoinedAt,
+		)
+		return &member, err
+	})
+}
+
+// GetMemberRole
+func (r *TeamRepository) GetMemberRole(ctx context.Context, teamID, userID uuid.UUID) (string, error) {
+	var role string
+	err := conn(ctx, r.db).QueryRow(ctx,
+		`SELECT role FROM team_members WHERE team_id = $1 AND user_id = $2`,
+		teamID, userID,
+	).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", repository.ErrNotFound
+	}
+
+	return role, err
+}
+
+// SetMember
+func (r *TeamRepository) SetMember(ctx context.Context, teamID, userID uuid.UUID, role string) error {
+	query := `
+		WITH member AS (
+			INSERT INTO team_members (team_id, user_id, role)
+			SELECT $1, u.id, $3
+			FROM users u
+			WHERE u.id = $2 AND u.deleted_at IS NULL
+			  AND EXISTS (SELECT 1 FROM teams WHERE id = $1)
+			ON CONFLICT (team_id, user_id) DO UPDATE SET role = EXCLUDED.role
+			RETURNING team_id
+		)
+		UPDATE teams SET updated_at = CURRENT_TIMESTAMP
+		WHERE id IN (SELECT team_id FROM member)
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, teamID, userID, role)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// RemoveMember
+func (r *TeamRepository) RemoveMember(ctx context.Context, teamID, userID uuid.UUID, updatedAt time.Time) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockTeam(ctx, tx, teamID, &updatedAt); err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM team_members WHERE team_id = $1 AND user_id = $2`, teamID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE teams SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`, teamID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// AssignRecords
+func (r *TeamRepository) AssignRecords(ctx context.Context, teamID uuid.UUID, req model.TeamRecordsRequest, assign, ownedOnly bool, updatedAt *time.Time) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockTeam(ctx, tx, teamID, updatedAt); err != nil {
+		return err
+	}
+
+	for table, ids := range map[string][]uuid.UUID{
+		"customers":     req.CustomerIDs,
+		"opportunities": req.OpportunityIDs,
+	} {
+		if len(ids) == 0 {
+			continue
+		}
+
+		query := `UPDATE ` + table + ` r SET team_id = $1, updated_at = CURRENT_TIMESTAMP WHERE r.id = ANY($2)`
+		if !assign {
+			query = `UPDATE ` + table + ` r SET team_id = NULL, updated_at = CURRENT_TIMESTAMP WHERE r.id = ANY($2) AND r.team_id = $1`
+		}
+		if ownedOnly {
+			query += ` AND ` + fmt.Sprintf(teamOwnersTemplate, "r")
+		}
+
+		if _, err := tx.Exec(ctx, query, teamID, ids); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE teams SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`, teamID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// lockTeam locks the team row. When updatedAt is given, the team must not
+// have changed since.
+func lockTeam(ctx context.Context, tx pgx.Tx, id uuid.UUID, updatedAt *time.Time) error {
+	var current time.Time
+	err := tx.QueryRow(ctx, `SELECT updated_at FROM teams WHERE id = $1 FOR UPDATE`, id).Scan(&current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if updatedAt != nil && !current.Equal(*updatedAt) {
+		return repository.ErrStale
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"context"
+
+	"customize_crm/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ repository.Transactor = (*Transactor)(nil)
+
+// dbtx is the part of pgxpool.Pool and pgx.Tx the repositories use.
+type dbtx interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type txKey struct{}
+
+// conn returns the transaction started for ctx by Transactor.WithinTx, or the
+// pool outside of one. Transactions begun on it inside WithinTx become
+// savepoints.
+func conn(ctx context.Context, db *pgxpool.Pool) dbtx {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+type Transactor struct {
+	db *pgxpool.Pool
+}
+
+func NewTransactor(db *pgxpool.Pool) *Transactor {
+	return &Transactor{db: db}
+}
+
+// WithinTx commits when fn succeeds and rolls back otherwise.
+func (t *Transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := conn(ctx, t.db).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,532 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var _ repository.UserRepository = (*UserRepository)(nil)
+
+type UserRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewUserRepository(db *pgxpool.Pool) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// GetByID
+func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	query := `
+		SELECT id, username, email, password_hash, first_name, last_name, 
+			   role_id, department, manager_id, created_at, updated_at, is_active
+		FROM users
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	return scanUser(conn(ctx, r.db).QueryRow(ctx, query, id))
+}
+
+// GetDeletedByID
+func (r *UserRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	query := `
+		SELECT id, username, email, password_hash, first_name, last_name, 
+			   role_id, department, manager_id, created_at, updated_at, is_active
+		FROM users
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+
+	return scanUser(conn(ctx, r.db).QueryRow(ctx, query, id))
+}
+
+// GetByUsername
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	query := `
+		SELECT id, username, email, password_hash, first_name, last_name, 
+			   role_id, department, manager_id, created_at, updated_at, is_active
+		FROM users
+		WHERE username = $1 AND deleted_at IS NULL
+	`
+
+	return scanUser(conn(ctx, r.db).QueryRow(ctx, query, username))
+}
+
+// GetAll
+func (r *UserRepository) GetAll(ctx context.Context) ([]*model.User, error) {
+	query := `
+		SELECT id, username, email, first_name, last_name,
+			   role_id, department, manager_id, created_at, updated_at, is_active, deleted_at
+		FROM users
+		WHERE deleted_at IS NULL
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*model.User
+
+	for rows.Next() {
+		user, err := scanDirectoryUser(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// List returns one page of the user directory together with the total number
+// of users matching the filters. Pagination is keyset based on the sort column
+// and the user ID.
+func (r *UserRepository) List(ctx context.Context, params repository.UserListParams) (*model.UserListResponse, error) {
+	var b queryBuilder
+
+	if params.Deleted {
+		b.where("u.deleted_at IS NOT NULL")
+	} else {
+		b.where("u.deleted_at IS NULL")
+	}
+
+	if params.RoleID != nil {
+		b.where("u.role_id = " + b.arg(*params.RoleID))
+	}
+	if params.RoleName != "" {
+		b.where("r.name = " + b.arg(params.RoleName))
+	}
+	if params.Department != "" {
+		b.where("u.department = " + b.arg(params.Department))
+	}
+	if params.IsActive != nil {
+		b.where("u.is_active = " + b.arg(*params.IsActive))
+	}
+	if params.CreatedFrom != nil {
+		b.where("u.created_at >= " + b.arg(*params.CreatedFrom))
+	}
+	if params.CreatedTo != nil {
+		b.where("u.created_at < " + b.arg(*params.CreatedTo))
+	}
+	if params.Search != "" {
+		search := b.arg(params.Search)
+		b.where(fmt.Sprintf(`(
+			to_tsvector('simple', u.first_name || ' ' || u.last_name || ' ' || u.username || ' ' || u.email)
+				@@ websearch_to_tsquery('simple', %[1]s)
+			OR u.username ILIKE '%%' || %[1]s || '%%'
+			OR u.email ILIKE '%%' || %[1]s || '%%'
+		)`, search))
+	}
+	b.filter("u", params.Query.Filters)
+
+	from := `FROM users u JOIN roles r ON r.id = u.role_id`
+
+	var total int64
+	if err := conn(ctx, r.db).QueryRow(ctx, "SELECT COUNT(*) "+from+" "+b.whereClause(), b.args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	pageParams := repository.PageParams{Limit: params.Limit, Cursor: params.Cursor, Query: params.Query}
+	tail, err := b.page(pageParams, "u")
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT u.id, u.username, u.email, u.first_name, u.last_name,
+			   u.role_id, u.department, u.manager_id, u.created_at, u.updated_at, u.is_active, u.deleted_at
+		` + from + `
+		` + b.whereClause() + `
+		` + tail
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*model.User{}
+
+	for rows.Next() {
+		user, err := scanDirectoryUser(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	users, pagination := pageInfo(pageParams, total, users)
+
+	return &model.UserListResponse{Data: users, Pagination: pagination}, nil
+}
+
+// Create
+func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
+	query := `
+		INSERT INTO users (username, email, password_hash, first_name, last_name, role_id, department, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		user.Username, user.Email, user.PasswordHash, user.FirstName, user.LastName,
+		user.RoleID, user.Department, user.IsActive,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+}
+
+// Update
+func (r *UserRepository) Update(ctx context.Context, user *model.User) error {
+	query := `
+		UPDATE users
+		SET first_name = $1, last_name = $2, department = $3, role_id = $4, is_active = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $6 AND deleted_at IS NULL AND updated_at = $7
+		RETURNING updated_at
+	`
+
+	err := conn(ctx, r.db).QueryRow(ctx, query,
+		user.FirstName, user.LastName, user.Department, user.RoleID, user.IsActive, user.ID, user.UpdatedAt,
+	).Scan(&user.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r.staleOrMissing(ctx, user.ID)
+	}
+
+	return err
+}
+
+// staleOrMissing tells why a conditional update matched no row.
+func (r *UserRepository) staleOrMissing(ctx context.Context, id uuid.UUID) error {
+	var exists bool
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)`, id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return repository.ErrStale
+	}
+	return repository.ErrNotFound
+}
+
+// UpdatePasswordHash
+func (r *UserRepository) UpdatePasswordHash(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	query := `
+		UPDATE users
+		SET password_hash = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, passwordHash, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// Activate
+func (r *UserRepository) Activate(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	query := `
+		UPDATE users
+		SET password_hash = $1, is_active = TRUE, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, passwordHash, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete
+func (r *UserRepository) Delete(ctx context.Context, ids []uuid.UUID, reassignTo *uuid.UUID) error {
+	// Repeated IDs would otherwise make the row count below fall short.
+	ids = uniqueIDs(ids)
+
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if reassignTo != nil {
+		for _, id := range ids {
+			if id == *reassignTo {
+				return repository.ErrInvalidReassignTarget
+			}
+		}
+
+		var active bool
+		err := tx.QueryRow(ctx,
+			`SELECT is_active FROM users WHERE id = $1 AND deleted_at IS NULL FOR SHARE`,
+			*reassignTo,
+		).Scan(&active)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && !active) {
+			return repository.ErrInvalidReassignTarget
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE users
+		SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ANY($1) AND deleted_at IS NULL
+	`, ids)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != int64(len(ids)) {
+		return repository.ErrNotFound
+	}
+
+	reassignQueries := []string{
+		`UPDATE users SET manager_id = $2, updated_at = CURRENT_TIMESTAMP
+		 WHERE manager_id = ANY($1) AND deleted_at IS NULL`,
+		`UPDATE customers SET assigned_to = $2, updated_at = CURRENT_TIMESTAMP
+		 WHERE assigned_to = ANY($1)`,
+		`UPDATE opportunities SET assigned_to = $2, updated_at = CURRENT_TIMESTAMP
+		 WHERE assigned_to = ANY($1) AND status = '` + model.OpportunityStatusOpen + `'`,
+		`UPDATE tasks SET assigned_to = $2, updated_at = CURRENT_TIMESTAMP
+		 WHERE assigned_to = ANY($1) AND completed_at IS NULL`,
+	}
+
+	for _, query := range reassignQueries {
+		if _, err := tx.Exec(ctx, query, ids, reassignTo); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Restore
+func (r *UserRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE users
+		SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// SetManager
+func (r *UserRepository) SetManager(ctx context.Context, userID uuid.UUID, managerID *uuid.UUID) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Serialize hierarchy changes so two concurrent updates cannot together
+	// introduce a cycle that neither sees on its own.
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('users.manager_id'))`); err != nil {
+		return err
+	}
+
+	if managerID != nil {
+		if *managerID == userID {
+			return repository.ErrManagerCycle
+		}
+
+		var exists bool
+		err := tx.QueryRow(ctx,
+			`SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)`,
+			*managerID,
+		).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return repository.ErrNotFound
+		}
+
+		cycleQuery := `
+			WITH RECURSIVE chain AS (
+				SELECT id, manager_id FROM users WHERE id = $1
+				UNION
+				SELECT u.id, u.manager_id FROM users u JOIN chain c ON u.id = c.manager_id
+			)
+			SELECT EXISTS(SELECT 1 FROM chain WHERE id = $2)
+		`
+
+		var cycle bool
+		if err := tx.QueryRow(ctx, cycleQuery, *managerID, userID).Scan(&cycle); err != nil {
+			return err
+		}
+		if cycle {
+			return repository.ErrManagerCycle
+		}
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE users
+		SET manager_id = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND deleted_at IS NULL
+	`, managerID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetReportIDs
+func (r *UserRepository) GetReportIDs(ctx context.Context, userIDs ...uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		WITH RECURSIVE reports AS (
+			SELECT id FROM users WHERE manager_id = ANY($1) AND deleted_at IS NULL
+			UNION
+			SELECT u.id FROM users u JOIN reports r ON u.manager_id = r.id
+			WHERE u.deleted_at IS NULL
+		)
+		SELECT id FROM reports WHERE id <> ALL($1)
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
+
+// GetActiveDelegatorIDs
+func (r *UserRepository) GetActiveDelegatorIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT DISTINCT d.delegator_id
+		FROM user_delegations d
+		JOIN users u ON u.id = d.delegator_id
+		WHERE d.delegate_id = $1
+		  AND d.revoked_at IS NULL
+		  AND CURRENT_TIMESTAMP >= d.starts_at
+		  AND CURRENT_TIMESTAMP < d.ends_at
+		  AND u.deleted_at IS NULL
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
+
+// GetOrgChartNodes
+func (r *UserRepository) GetOrgChartNodes(ctx context.Context) ([]*model.OrgChartNode, error) {
+	query := `
+		SELECT id, username, first_name, last_name, department, manager_id
+		FROM users
+		WHERE deleted_at IS NULL
+		ORDER BY last_name, first_name
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.OrgChartNode, error) {
+		node := &model.OrgChartNode{Reports: []*model.OrgChartNode{}}
+		err := row.Scan(&node.ID, &node.Username, &node.FirstName, &node.LastName, &node.Department, &node.ManagerID)
+		return node, err
+	})
+}
+
+func scanUser(row pgx.Row) (*model.User, error) {
+	var user model.User
+	var department sql.NullString
+
+	err := row.Scan(
+		&user.ID, &user.Username, &user.Email, &user.PasswordHash,
+		&user.FirstName, &user.LastName, &user.RoleID, &department,
+		&user.ManagerID, &user.CreatedAt, &user.UpdatedAt, &user.IsActive,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if department.Valid {
+		dept := department.String
+		user.Department = &dept
+	}
+
+	return &user, nil
+}
+
+// scanDirectoryUser scans a user row that does not include the password hash.
+func scanDirectoryUser(row pgx.Row) (*model.User, error) {
+	var user model.User
+	var department sql.NullString
+
+	err := row.Scan(
+		&user.ID, &user.Username, &user.Email,
+		&user.FirstName, &user.LastName, &user.RoleID, &department,
+		&user.ManagerID, &user.CreatedAt, &user.UpdatedAt, &user.IsActive, &user.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if department.Valid {
+		dept := department.String
+		user.Department = &dept
+	}
+
+	return &user, nil
+}
+
+// uniqueIDs drops repeated IDs, keeping the first occurrence of each.
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,214 @@
+// Package repository defines the storage interfaces used by the services.
+// The postgres subpackage implements them on pgx; the memory subpackage keeps
+// everything in process for tests and local experiments.
+package repository
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"customize_crm/apperror"
+	"customize_crm/model"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound              = apperror.New(http.StatusNotFound, "not_found", "record not found")
+	ErrInvalidReassignTarget = apperror.New(http.StatusBadRequest, "invalid_reassign_target", "reassignment target must be a different, active user")
+	ErrManagerCycle          = apperror.New(http.StatusConflict, "manager_cycle", "manager assignment would create a reporting cycle")
+	ErrStale                 = apperror.ErrPreconditionFailed
+)
+
+// Transactor runs fn in a transaction. Repository calls made with the
+// context passed to fn take part in it, and an error from fn rolls them back.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// UserRepository stores users. Soft-deleted users are only returned by List
+// with params.Deleted set and by GetDeletedByID.
+type UserRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*model.User, error)
+	GetDeletedByID(ctx context.Context, id uuid.UUID) (*model.User, error)
+	GetByUsername(ctx context.Context, username string) (*model.User, error)
+	GetAll(ctx context.Context) ([]*model.User, error)
+	List(ctx context.Context, params UserListParams) (*model.UserListResponse, error)
+	Create(ctx context.Context, user *model.User) error
+
+	// Update only succeeds while the stored updated_at still equals
+	// user.UpdatedAt and returns ErrStale otherwise.
+	Update(ctx context.Context, user *model.User) error
+	UpdatePasswordHash(ctx context.Context, id uuid.UUID, passwordHash string) error
+	// Activate sets the password of an invited user and activates the account.
+	Activate(ctx context.Context, id uuid.UUID, passwordHash string) error
+
+	// Delete soft-deletes the users and hands their reports, customers, open
+	// opportunities and incomplete tasks to reassignTo, or unassigns them
+	// when it is nil.
+	Delete(ctx context.Context, ids []uuid.UUID, reassignTo *uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
+
+	// SetManager rejects assignments that would create a reporting cycle.
+	SetManager(ctx context.Context, userID uuid.UUID, managerID *uuid.UUID) error
+	GetReportIDs(ctx context.Context, userIDs ...uuid.UUID) ([]uuid.UUID, error)
+	GetActiveDelegatorIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+
+	// GetOrgChartNodes returns every user as an unlinked node, ordered by
+	// last and first name.
+	GetOrgChartNodes(ctx context.Context) ([]*model.OrgChartNode, error)
+}
+
+type RoleRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Role, error)
+	GetByName(ctx context.Context, name string) (*model.Role, error)
+	// CreateIfMissing reports whether the role was created; an existing role
+	// with the same name is left untouched.
+	CreateIfMissing(ctx context.Context, role *model.Role) (bool, error)
+}
+
+type CustomerRepository interface {
+	List(ctx context.Context, scope *model.VisibilityScope, params PageParams) (*model.CustomerListResponse, error)
+	// GetByIDs skips customers that do not exist or are not visible.
+	GetByIDs(ctx context.Context, scope *model.VisibilityScope, ids []uuid.UUID) ([]*model.Customer, error)
+}
+
+type ContactRepository interface {
+	// ListByCustomers returns the contacts of the customers, primary contacts
+	// first.
+	ListByCustomers(ctx context.Context, customerIDs []uuid.UUID) ([]*model.Contact, error)
+}
+
+type OpportunityRepository interface {
+	List(ctx context.Context, scope *model.VisibilityScope, params PageParams) (*model.OpportunityListResponse, error)
+	ListByTeam(ctx context.Context, teamID uuid.UUID, params PageParams) (*model.OpportunityListResponse, error)
+	// GetByIDs skips opportunities that do not exist or are not visible.
+	GetByIDs(ctx context.Context, scope *model.VisibilityScope, ids []uuid.UUID) ([]*model.Opportunity, error)
+	// ListByCustomers returns the visible opportunities of the customers,
+	// newest first.
+	ListByCustomers(ctx context.Context, scope *model.VisibilityScope, customerIDs []uuid.UUID) ([]*model.Opportunity, error)
+
+	// Pipeline summarizes open opportunities visible within scope by stage.
+	Pipeline(ctx context.Context, scope *model.VisibilityScope) ([]*model.PipelineStageSummary, error)
+	TeamPipeline(ctx context.Context, teamID uuid.UUID) ([]*model.PipelineStageSummary, error)
+}
+
+type TaskRepository interface {
+	List(ctx context.Context, scope *model.VisibilityScope, params PageParams) (*model.TaskListResponse, error)
+	ListByTeam(ctx context.Context, teamID uuid.UUID, params PageParams) (*model.TaskListResponse, error)
+	StatusSummary(ctx context.Context, scope *model.VisibilityScope) ([]*model.TaskStatusSummary, error)
+}
+
+type ProductRepository interface {
+	List(ctx context.Context, params PageParams) (*model.ProductListResponse, error)
+}
+
+type SearchRepository interface {
+	// Search returns the visible records matching params.Query, best match
+	// first.
+	Search(ctx context.Context, scope *model.VisibilityScope, params model.SearchParams) ([]*model.SearchResult, error)
+}
+
+type ActivityLogRepository interface {
+	Create(ctx context.Context, entry *model.ActivityLog) error
+}
+
+// InvitationRepository stores invitations by the hash of their token. Status
+// is left for the caller to derive.
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *model.Invitation, tokenHash string) error
+	GetAll(ctx context.Context) ([]*model.Invitation, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Invitation, error)
+
+	// Renew replaces the token and expiry of a pending invitation. It, Revoke
+	// and Accept return ErrNotFound when no pending invitation matches.
+	Renew(ctx context.Context, id uuid.UUID, tokenHash string, expiresAt time.Time) error
+	Revoke(ctx context.Context, id uuid.UUID) error
+	// Accept consumes the unexpired invitation with the token hash and
+	// returns the invitee's ID.
+	Accept(ctx context.Context, tokenHash string) (uuid.UUID, error)
+}
+
+// PasskeyRepository stores WebAuthn credentials and the sessions of ongoing
+// registration and login ceremonies.
+type PasskeyRepository interface {
+	Create(ctx context.Context, passkey *model.Passkey) error
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.Passkey, error)
+	GetByID(ctx context.Context, userID, id uuid.UUID) (*model.Passkey, error)
+	// UpdateCredential stores the credential state after a login and marks
+	// the passkey as used.
+	UpdateCredential(ctx context.Context, userID uuid.UUID, credentialID, credential []byte) error
+	Rename(ctx context.Context, userID, id uuid.UUID, name string) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+
+	CreateSession(ctx context.Context, userID *uuid.UUID, data []byte, expiresAt time.Time) (uuid.UUID, error)
+	// TakeSession deletes an unexpired session and returns its owner and
+	// data, so that every challenge can be answered at most once.
+	TakeSession(ctx context.Context, id uuid.UUID) (*uuid.UUID, []byte, error)
+}
+
+type TeamRepository interface {
+	Create(ctx context.Context, team *model.Team) error
+	GetAll(ctx context.Context) ([]*model.Team, error)
+	// GetByID returns the team without its members.
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Team, error)
+
+	// Update and Delete only succeed while the stored updated_at still
+	// equals the given one and return ErrStale otherwise. Delete also clears
+	// the team from its customers and opportunities.
+	Update(ctx context.Context, team *model.Team) error
+	Delete(ctx context.Context, id uuid.UUID, updatedAt time.Time) error
+
+	GetMembers(ctx context.Context, teamID uuid.UUID) ([]*model.TeamMember, error)
+	GetMemberRole(ctx context.Context, teamID, userID uuid.UUID) (string, error)
+	// SetMember and RemoveMember bump the team's updated_at. RemoveMember,
+	// like Delete, returns ErrStale once the team has changed.
+	SetMember(ctx context.Context, teamID, userID uuid.UUID, role string) error
+	RemoveMember(ctx context.Context, teamID, userID uuid.UUID, updatedAt time.Time) error
+
+	// AssignRecords assigns the customers and opportunities to the team, or
+	// clears the team from those it holds when assign is false. With
+	// ownedOnly set, only records owned by the team or its members change.
+	// It bumps the team's updated_at and, when updatedAt is given, returns
+	// ErrStale once the team has changed.
+	AssignRecords(ctx context.Context, teamID uuid.UUID, req model.TeamRecordsRequest, assign, ownedOnly bool, updatedAt *time.Time) error
+}
+
+type DelegationRepository interface {
+	// Create returns ErrNotFound unless the delegate is an active user.
+	Create(ctx context.Context, delegation *model.Delegation) error
+	ListForUser(ctx context.Context, userID uuid.UUID) ([]*model.Delegation, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Delegation, error)
+	// Revoke keeps the original revocation time of a revoked delegation.
+	Revoke(ctx context.Context, id uuid.UUID) error
+}
+
+type ReassignmentRepository interface {
+	// Reassign moves the records of the entity types matching req to
+	// req.ToUserID, fills in the reassignment's IDs and stores it. A dry run
+	// rolls the updates back and stores nothing.
+	Reassign(ctx context.Context, reassignment *model.Reassignment, entityTypes []string, req model.ReassignmentRequest) error
+	GetAll(ctx context.Context) ([]*model.Reassignment, error)
+}
+
+// TagRepository stores the customer tag vocabulary. Renaming, deleting and
+// merging tags rewrites the tags of the customers using them.
+type TagRepository interface {
+	Create(ctx context.Context, tag *model.Tag) error
+	GetAll(ctx context.Context) ([]*model.Tag, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Tag, error)
+
+	// Update and Delete only succeed while the stored updated_at still
+	// equals updatedAt and return ErrStale otherwise.
+	Update(ctx context.Context, tag *model.Tag, updatedAt time.Time) error
+	Delete(ctx context.Context, id uuid.UUID, updatedAt time.Time) error
+	// Merge replaces the source tag with the target and returns the target.
+	// The source's updated_at must still equal sourceUpdatedAt.
+	Merge(ctx context.Context, sourceID, targetID uuid.UUID, sourceUpdatedAt time.Time) (*model.Tag, error)
+
+	// UpdateCustomerTags changes the tags of the customers visible in scope
+	// and returns how many changed. When an added tag is not in the
+	// vocabulary nothing changes and the unknown names are returned instead.
+	UpdateCustomerTags(ctx context.Context, scope *model.VisibilityScope, req *model.CustomerTagsRequest) (int64, []string, error)
+}
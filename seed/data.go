@@ -0,0 +1,144 @@
+package seed
+
+type district struct {
+	name       string
+	postalCode string
+}
+
+type province struct {
+	name      string
+	districts []district
+	areaCode  string
+}
+
+// provinces pairs each province with a few of its districts and their
+// postal codes so that generated addresses are consistent.
+var provinces = []province{
+	{"Bangkok", []district{
+		{"Pathum Wan", "10330"}, {"Bang Rak", "10500"}, {"Khlong Toei", "10110"},
+		{"Sathon", "10120"}, {"Chatuchak", "10900"}, {"Huai Khwang", "10310"},
+		{"Bang Na", "10260"}, {"Lat Phrao", "10230"},
+	}, "2"},
+	{"Nonthaburi", []district{{"Mueang Nonthaburi", "11000"}, {"Pak Kret", "11120"}}, "2"},
+	{"Pathum Thani", []district{{"Mueang Pathum Thani", "12000"}, {"Khlong Luang", "12120"}}, "2"},
+	{"Samut Prakan", []district{{"Mueang Samut Prakan", "10270"}, {"Bang Phli", "10540"}}, "2"},
+	{"Samut Sakhon", []district{{"Mueang Samut Sakhon", "74000"}}, "34"},
+	{"Nakhon Pathom", []district{{"Mueang Nakhon Pathom", "73000"}}, "34"},
+	{"Phra Nakhon Si Ayutthaya", []district{{"Phra Nakhon Si Ayutthaya", "13000"}}, "35"},
+	{"Chon Buri", []district{{"Mueang Chon Buri", "20000"}, {"Si Racha", "20110"}, {"Bang Lamung", "20150"}}, "38"},
+	{"Rayong", []district{{"Mueang Rayong", "21000"}, {"Pluak Daeng", "21140"}}, "38"},
+	{"Chachoengsao", []district{{"Mueang Chachoengsao", "24000"}}, "38"},
+	{"Saraburi", []district{{"Mueang Saraburi", "18000"}}, "36"},
+	{"Nakhon Ratchasima", []district{{"Mueang Nakhon Ratchasima", "30000"}, {"Pak Chong", "30130"}}, "44"},
+	{"Khon Kaen", []district{{"Mueang Khon Kaen", "40000"}}, "43"},
+	{"Udon Thani", []district{{"Mueang Udon Thani", "41000"}}, "42"},
+	{"Ubon Ratchathani", []district{{"Mueang Ubon Ratchathani", "34000"}}, "45"},
+	{"Chiang Mai", []district{{"Mueang Chiang Mai", "50000"}, {"San Sai", "50210"}, {"Hang Dong", "50230"}}, "53"},
+	{"Chiang Rai", []district{{"Mueang Chiang Rai", "57000"}}, "53"},
+	{"Lampang", []district{{"Mueang Lampang", "52000"}}, "54"},
+	{"Phitsanulok", []district{{"Mueang Phitsanulok", "65000"}}, "55"},
+	{"Kanchanaburi", []district{{"Mueang Kanchanaburi", "71000"}}, "34"},
+	{"Prachuap Khiri Khan", []district{{"Hua Hin", "77110"}}, "32"},
+	{"Surat Thani", []district{{"Mueang Surat Thani", "84000"}, {"Ko Samui", "84140"}}, "77"},
+	{"Phuket", []district{{"Mueang Phuket", "83000"}, {"Kathu", "83120"}, {"Thalang", "83110"}}, "76"},
+	{"Krabi", []district{{"Mueang Krabi", "81000"}}, "75"},
+	{"Songkhla", []district{{"Hat Yai", "90110"}, {"Mueang Songkhla", "90000"}}, "74"},
+	{"Nakhon Si Thammarat", []district{{"Mueang Nakhon Si Thammarat", "80000"}}, "75"},
+}
+
+var roads = []string{
+	"Sukhumvit", "Rama IV", "Silom", "Phahonyothin", "Ratchadaphisek", "Phetchaburi",
+	"Charoen Krung", "Mittraphap", "Nimmanhaemin", "Thepkrasattri", "Chaeng Watthana",
+}
+
+var companyPrefixes = []string{
+	"Siam", "Thai", "Chao Phraya", "Lanna", "Isan", "Andaman", "Rattanakosin", "Mekong",
+	"Erawan", "Naga", "Suvarnabhumi", "Krungthep", "Ayothaya", "Sukhothai", "Golden Land",
+}
+
+var industries = []struct {
+	name     string
+	suffixes []string
+}{
+	{"Manufacturing", []string{"Industries", "Engineering", "Precision Parts"}},
+	{"Logistics", []string{"Logistics", "Transport", "Freight"}},
+	{"Food & Beverage", []string{"Foods", "Agro", "Beverage"}},
+	{"Retail", []string{"Trading", "Retail", "Mart"}},
+	{"Hospitality", []string{"Hotels & Resorts", "Hospitality"}},
+	{"Technology", []string{"Solutions", "Digital", "Systems"}},
+	{"Construction", []string{"Construction", "Development"}},
+	{"Healthcare", []string{"Medical", "Healthcare"}},
+}
+
+var firstNames = []string{
+	"Somchai", "Somsak", "Sombat", "Suda", "Siriporn", "Kanokwan", "Nattapong", "Wichai",
+	"Pimchanok", "Anan", "Pornthip", "Chaiwat", "Kittisak", "Supaporn", "Thanakorn",
+	"Wanida", "Apinya", "Narong", "Rattana", "Jirawat", "Nattaya", "Panya", "Kamon", "Malee",
+}
+
+var lastNames = []string{
+	"Saetang", "Srisuk", "Wongsawat", "Chaiyaporn", "Boonmee", "Rattanakul", "Phongsri",
+	"Kaewkla", "Thongdee", "Sukjai", "Charoenrat", "Suwannarat", "Jantarak", "Sirisombat",
+	"Thammasat", "Prasertsuk", "Kongsiri", "Yimyam",
+}
+
+var positions = []string{
+	"Managing Director", "Purchasing Manager", "IT Manager", "Operations Director",
+	"Finance Manager", "Procurement Officer", "General Manager", "Sales Director",
+}
+
+var products = []struct {
+	sku       string
+	name      string
+	category  string
+	unitPrice float64
+}{
+	{"CRM-STD", "CRM Standard License (per user/year)", "Software", 4900},
+	{"CRM-PRO", "CRM Professional License (per user/year)", "Software", 9900},
+	{"CRM-ENT", "CRM Enterprise License (per user/year)", "Software", 19900},
+	{"ERP-CORE", "ERP Core Module", "Software", 250000},
+	{"ERP-HR", "ERP HR & Payroll Module", "Software", 120000},
+	{"POS-TERM", "POS Terminal", "Hardware", 18500},
+	{"HHS-SCAN", "Handheld Barcode Scanner", "Hardware", 7200},
+	{"SRV-RACK", "Rack Server 2U", "Hardware", 185000},
+	{"NET-SW24", "24-Port Managed Switch", "Hardware", 23500},
+	{"IMP-STD", "Standard Implementation", "Services", 80000},
+	{"IMP-ENT", "Enterprise Implementation", "Services", 450000},
+	{"TRN-DAY", "On-site Training (per day)", "Services", 25000},
+	{"SUP-GOLD", "Gold Support (per year)", "Support", 60000},
+	{"SUP-PLAT", "Platinum Support (per year)", "Support", 150000},
+	{"CLD-STO", "Cloud Storage 1 TB (per year)", "Cloud", 12000},
+}
+
+var customerStatuses = []string{"lead", "lead", "prospect", "prospect", "active", "active", "active", "inactive"}
+
+var customerTypes = []string{"SME", "SME", "Enterprise", "Government"}
+
+var customerTags = []string{"vip", "key-account", "export", "boi", "referral", "new", "renewal"}
+
+var openStages = []struct {
+	name        string
+	probability int
+}{
+	{"prospecting", 10},
+	{"qualification", 25},
+	{"proposal", 50},
+	{"negotiation", 75},
+}
+
+var opportunitySources = []string{"Website", "Referral", "Trade Show", "Cold Call", "Partner", "LINE Official Account"}
+
+var lostReasons = []string{"Price too high", "Chose a competitor", "Budget frozen", "No decision", "Project cancelled"}
+
+var interactionTypes = []struct {
+	name    string
+	subject string
+}{
+	{"call", "Call with %s"},
+	{"email", "Email to %s"},
+	{"meeting", "Meeting at %s"},
+	{"demo", "Product demo for %s"},
+	{"line", "LINE chat with %s"},
+}
+
+var taskPriorities = []string{"low", "medium", "medium", "high"}
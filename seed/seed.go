@@ -0,0 +1,387 @@
+// Package seed generates coherent demo data for every CRM table. The same
+// Options always produce the same rows, and they are written with COPY.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"customize_crm/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Options struct {
+	Seed      int64
+	Customers int
+	Reps      int
+	// AsOf anchors every generated date; with the same Seed and AsOf the
+	// output is identical.
+	AsOf          time.Time
+	PasswordHash  string
+	ManagerRoleID uuid.UUID
+	RepRoleID     uuid.UUID
+}
+
+type table struct {
+	name    string
+	columns []string
+	rows    [][]interface{}
+}
+
+// Result holds the number of rows written per table, in insertion order.
+type Result struct {
+	Tables []string
+	Rows   map[string]int
+}
+
+type generator struct {
+	opts    Options
+	rng     *rand.Rand
+	tables  []*table
+	byName  map[string]*table
+	manager uuid.UUID
+	reps    []uuid.UUID
+	prices  map[uuid.UUID]float64
+	catalog []uuid.UUID
+}
+
+// Run generates the demo data and copies it into the database in a single
+// transaction.
+func Run(ctx context.Context, db *pgxpool.Pool, opts Options) (*Result, error) {
+	g := newGenerator(opts)
+	g.generate()
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	result := &Result{Rows: map[string]int{}}
+
+	for _, t := range g.tables {
+		n, err := tx.CopyFrom(ctx, pgx.Identifier{t.name}, t.columns, pgx.CopyFromRows(t.rows))
+		if err != nil {
+			return nil, fmt.Errorf("copy %s: %w", t.name, err)
+		}
+
+		result.Tables = append(result.Tables, t.name)
+		result.Rows[t.name] = int(n)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func newGenerator(opts Options) *generator {
+	g := &generator{
+		opts:   opts,
+		rng:    rand.New(rand.NewSource(opts.Seed)),
+		byName: map[string]*table{},
+		prices: map[uuid.UUID]float64{},
+	}
+
+	g.table("users", "id", "username", "email", "password_hash", "first_name", "last_name",
+		"role_id", "department", "manager_id", "created_at", "updated_at", "is_active")
+	g.table("products", "id", "name", "description", "sku", "unit_price", "category",
+		"created_at", "updated_at", "is_active")
+	g.table("customers", "id", "company_name", "industry", "address", "city", "province",
+		"postal_code", "phone", "website", "customer_status", "customer_type", "assigned_to",
+		"created_at", "updated_at", "created_by", "notes", "annual_revenue", "tags")
+	g.table("tags", "id", "name", "created_at", "updated_at")
+	g.table("contacts", "id", "customer_id", "first_name", "last_name", "position", "email",
+		"phone", "mobile", "is_primary", "created_at", "updated_at", "notes")
+	g.table("opportunities", "id", "name", "customer_id", "contact_id", "amount", "stage",
+		"probability", "expected_close_date", "assigned_to", "created_at", "updated_at",
+		"created_by", "source", "description", "status", "reason_lost")
+	g.table("opportunity_products", "id", "opportunity_id", "product_id", "quantity",
+		"unit_price", "discount", "total", "created_at", "updated_at")
+	g.table("interactions", "id", "customer_id", "contact_id", "user_id", "interaction_type",
+		"subject", "description", "interaction_date", "created_at", "updated_at",
+		"opportunity_id", "follow_up_date", "status")
+	g.table("tasks", "id", "title", "description", "due_date", "priority", "status",
+		"assigned_to", "created_by", "customer_id", "opportunity_id", "contact_id",
+		"created_at", "updated_at", "completed_at")
+	g.table("activity_logs", "id", "user_id", "activity_type", "entity_type", "entity_id",
+		"description", "created_at", "metadata")
+
+	return g
+}
+
+func (g *generator) table(name string, columns ...string) {
+	t := &table{name: name, columns: columns}
+	g.tables = append(g.tables, t)
+	g.byName[name] = t
+}
+
+func (g *generator) add(name string, values ...interface{}) {
+	t := g.byName[name]
+	t.rows = append(t.rows, values)
+}
+
+func (g *generator) generate() {
+	g.generateUsers()
+	g.generateProducts()
+
+	for i := 0; i < g.opts.Customers; i++ {
+		g.generateCustomer()
+	}
+
+	// Last, so adding the tags did not change the rows generated before.
+	g.generateTags()
+}
+
+func (g *generator) generateUsers() {
+	since := g.daysAgo(900, 800)
+
+	g.manager = g.id()
+	g.add("users", g.manager, "demo.manager", "demo.manager@example.co.th", g.opts.PasswordHash,
+		g.pick(firstNames), g.pick(lastNames), g.opts.ManagerRoleID, "Sales", nil, since, since, true)
+
+	for i := 1; i <= g.opts.Reps; i++ {
+		id := g.id()
+		firstName, lastName := g.pick(firstNames), g.pick(lastNames)
+		username := fmt.Sprintf("demo.rep%02d", i)
+
+		g.add("users", id, username, username+"@example.co.th", g.opts.PasswordHash,
+			firstName, lastName, g.opts.RepRoleID, "Sales", g.manager, since, since, true)
+
+		g.reps = append(g.reps, id)
+	}
+}
+
+func (g *generator) generateProducts() {
+	since := g.daysAgo(900, 800)
+
+	for _, p := range products {
+		id := g.id()
+		g.add("products", id, p.name, nil, "DEMO-"+p.sku, p.unitPrice, p.category, since, since, true)
+
+		g.prices[id] = p.unitPrice
+		g.catalog = append(g.catalog, id)
+	}
+}
+
+// generateTags adds the customer tags to the tag vocabulary; the backfill in
+// the tags migration has run long before the seeder.
+func (g *generator) generateTags() {
+	since := g.daysAgo(900, 800)
+
+	for _, tag := range customerTags {
+		g.add("tags", g.id(), tag, since, since)
+	}
+}
+
+func (g *generator) generateCustomer() {
+	id := g.id()
+	owner := g.reps[g.rng.Intn(len(g.reps))]
+	createdAt := g.daysAgo(730, 30)
+
+	industry := industries[g.rng.Intn(len(industries))]
+	prefix := g.pick(companyPrefixes)
+	suffix := g.pick(industry.suffixes)
+	name := prefix + " " + suffix + " Co., Ltd."
+	if g.rng.Intn(8) == 0 {
+		name = prefix + " " + suffix + " Public Co., Ltd."
+	}
+	domain := strings.ToLower(strings.NewReplacer(" ", "", "&", "").Replace(prefix+suffix)) + ".co.th"
+
+	p := provinces[g.rng.Intn(len(provinces))]
+	d := p.districts[g.rng.Intn(len(p.districts))]
+	address := fmt.Sprintf("%d/%d %s Road", 1+g.rng.Intn(999), 1+g.rng.Intn(99), g.pick(roads))
+
+	status := g.pick(customerStatuses)
+	revenue := float64(5+g.rng.Intn(2000)) * 1_000_000
+
+	g.add("customers", id, name, industry.name, address, d.name, p.name, d.postalCode,
+		g.landline(p.areaCode), "https://www."+domain, status, g.pick(customerTypes), owner,
+		createdAt, createdAt, owner, nil, revenue, g.tags())
+	g.log(owner, "created", "customer", id, "Created customer "+name, createdAt)
+
+	contacts := make([]uuid.UUID, 1+g.rng.Intn(4))
+	for i := range contacts {
+		contacts[i] = g.id()
+		firstName, lastName := g.pick(firstNames), g.pick(lastNames)
+		email := strings.ToLower(firstName+"."+lastName[:1]) + "@" + domain
+
+		g.add("contacts", contacts[i], id, firstName, lastName, g.pick(positions), email,
+			g.landline(p.areaCode), g.mobile(), i == 0, createdAt, createdAt, nil)
+	}
+
+	// Leads have not been worked yet; everyone else has a history.
+	if status == "lead" {
+		return
+	}
+
+	for n := g.rng.Intn(4); n > 0; n-- {
+		g.generateOpportunity(id, name, owner, contacts, createdAt)
+	}
+
+	for n := 1 + g.rng.Intn(5); n > 0; n-- {
+		at := g.between(createdAt, g.opts.AsOf)
+		interaction := interactionTypes[g.rng.Intn(len(interactionTypes))]
+		subject := fmt.Sprintf(interaction.subject, name)
+
+		var followUp interface{}
+		if g.rng.Intn(3) == 0 {
+			followUp = at.AddDate(0, 0, 7)
+		}
+
+		g.add("interactions", g.id(), id, g.pickID(contacts), owner, interaction.name,
+			subject, nil, at, at, at, nil, followUp, "completed")
+	}
+
+	if g.rng.Intn(2) == 0 {
+		g.generateTask("Quarterly check-in with "+name, owner, id, nil, g.pickID(contacts), createdAt)
+	}
+}
+
+func (g *generator) generateOpportunity(customerID uuid.UUID, customerName string, owner uuid.UUID, contacts []uuid.UUID, since time.Time) {
+	id := g.id()
+	createdAt := g.between(since, g.opts.AsOf)
+	updatedAt := createdAt
+
+	var items [][]interface{}
+	var amount float64
+	for _, i := range g.rng.Perm(len(g.catalog))[:1+g.rng.Intn(4)] {
+		productID := g.catalog[i]
+		quantity := 1 + g.rng.Intn(20)
+		discount := float64(g.rng.Intn(3)) * 5
+		total := round2(float64(quantity) * g.prices[productID] * (1 - discount/100))
+		amount += total
+
+		items = append(items, []interface{}{
+			g.id(), id, productID, quantity, g.prices[productID], discount, total, createdAt, createdAt,
+		})
+	}
+
+	status := model.OpportunityStatusOpen
+	switch roll := g.rng.Intn(100); {
+	case roll < 25:
+		status = model.OpportunityStatusWon
+	case roll < 40:
+		status = model.OpportunityStatusLost
+	}
+
+	var stage string
+	var probability int
+	var closeDate time.Time
+	var reasonLost interface{}
+
+	switch status {
+	case model.OpportunityStatusOpen:
+		s := openStages[g.rng.Intn(len(openStages))]
+		stage, probability = s.name, s.probability
+		closeDate = g.opts.AsOf.AddDate(0, 0, 7+g.rng.Intn(120))
+	case model.OpportunityStatusWon:
+		stage, probability = "closed_won", 100
+		closeDate = g.between(createdAt, g.opts.AsOf)
+		updatedAt = closeDate
+	case model.OpportunityStatusLost:
+		stage, probability = "closed_lost", 0
+		closeDate = g.between(createdAt, g.opts.AsOf)
+		updatedAt = closeDate
+		reasonLost = g.pick(lostReasons)
+	}
+
+	name := fmt.Sprintf("%s - %s", customerName, products[g.rng.Intn(len(products))].category)
+
+	g.add("opportunities", id, name, customerID, g.pickID(contacts), round2(amount), stage,
+		probability, closeDate, owner, createdAt, updatedAt, owner, g.pick(opportunitySources),
+		nil, status, reasonLost)
+	for _, item := range items {
+		g.add("opportunity_products", item...)
+	}
+
+	g.log(owner, "created", "opportunity", id, "Created opportunity "+name, createdAt)
+	if status != model.OpportunityStatusOpen {
+		g.log(owner, status, "opportunity", id, fmt.Sprintf("Opportunity %s marked %s", name, status), updatedAt)
+		return
+	}
+
+	g.generateTask("Follow up on "+stage+" for "+name, owner, customerID, &id, g.pickID(contacts), createdAt)
+}
+
+func (g *generator) generateTask(title string, owner, customerID uuid.UUID, opportunityID *uuid.UUID, contactID uuid.UUID, since time.Time) {
+	createdAt := g.between(since, g.opts.AsOf)
+	due := createdAt.AddDate(0, 0, 1+g.rng.Intn(45))
+
+	status := "pending"
+	var completedAt interface{}
+	if due.Before(g.opts.AsOf) && g.rng.Intn(3) > 0 {
+		status = "completed"
+		completedAt = due
+	} else if g.rng.Intn(3) == 0 {
+		status = "in_progress"
+	}
+
+	g.add("tasks", g.id(), title, nil, due, g.pick(taskPriorities), status, owner, g.manager,
+		customerID, opportunityID, contactID, createdAt, createdAt, completedAt)
+}
+
+func (g *generator) log(userID uuid.UUID, activityType, entityType string, entityID uuid.UUID, description string, at time.Time) {
+	metadata := fmt.Sprintf(`{"source":"seed","seed":%d}`, g.opts.Seed)
+	g.add("activity_logs", g.id(), userID, activityType, entityType, entityID, description, at, metadata)
+}
+
+// id draws a UUID from the seeded source so that IDs are reproducible too.
+func (g *generator) id() uuid.UUID {
+	return uuid.Must(uuid.NewRandomFromReader(g.rng))
+}
+
+func (g *generator) pick(values []string) string {
+	return values[g.rng.Intn(len(values))]
+}
+
+func (g *generator) pickID(values []uuid.UUID) uuid.UUID {
+	return values[g.rng.Intn(len(values))]
+}
+
+func (g *generator) tags() []string {
+	tags := []string{}
+	for _, tag := range customerTags {
+		if g.rng.Intn(5) == 0 {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// daysAgo returns a time between max and min days before AsOf.
+func (g *generator) daysAgo(max, min int) time.Time {
+	return g.opts.AsOf.Add(-time.Duration(min+g.rng.Intn(max-min+1)) * 24 * time.Hour).
+		Add(-time.Duration(g.rng.Intn(24*60)) * time.Minute)
+}
+
+func (g *generator) between(from, to time.Time) time.Time {
+	span := to.Sub(from)
+	if span <= 0 {
+		return from
+	}
+	return from.Add(time.Duration(g.rng.Int63n(int64(span))))
+}
+
+func (g *generator) landline(areaCode string) string {
+	if len(areaCode) == 1 {
+		return fmt.Sprintf("+66 %s %03d %04d", areaCode, g.rng.Intn(1000), g.rng.Intn(10000))
+	}
+	return fmt.Sprintf("+66 %s %03d %03d", areaCode, g.rng.Intn(1000), g.rng.Intn(1000))
+}
+
+func (g *generator) mobile() string {
+	return fmt.Sprintf("+66 %d%d %03d %04d", 6+g.rng.Intn(4), g.rng.Intn(10), g.rng.Intn(1000), g.rng.Intn(10000))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,88 @@
+package seed
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testOptions() Options {
+	return Options{
+		Seed:          1,
+		Customers:     30,
+		Reps:          3,
+		AsOf:          time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC),
+		PasswordHash:  "hash",
+		ManagerRoleID: uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		RepRoleID:     uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+	}
+}
+
+// column returns the values of one column of the generated table.
+func column(t *testing.T, g *generator, table, name string) []interface{} {
+	t.Helper()
+
+	tbl := g.byName[table]
+	for i, c := range tbl.columns {
+		if c == name {
+			values := make([]interface{}, len(tbl.rows))
+			for j, row := range tbl.rows {
+				values[j] = row[i]
+			}
+			return values
+		}
+	}
+
+	t.Fatalf("table %s has no column %s", table, name)
+	return nil
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	generate := func(opts Options) *generator {
+		g := newGenerator(opts)
+		g.generate()
+		return g
+	}
+
+	a, b := generate(testOptions()), generate(testOptions())
+	for i, tbl := range a.tables {
+		if len(tbl.rows) == 0 {
+			t.Errorf("table %s is empty", tbl.name)
+		}
+		if !reflect.DeepEqual(tbl.rows, b.tables[i].rows) {
+			t.Errorf("table %s differs between runs with the same seed", tbl.name)
+		}
+	}
+
+	opts := testOptions()
+	opts.Seed = 2
+	other := generate(opts)
+	if reflect.DeepEqual(column(t, a, "users", "id"), column(t, other, "users", "id")) {
+		t.Error("a different seed generated the same user IDs")
+	}
+}
+
+func TestCustomerTagsAreInVocabulary(t *testing.T) {
+	g := newGenerator(testOptions())
+	g.generate()
+
+	known := map[string]bool{}
+	for _, name := range column(t, g, "tags", "name") {
+		known[name.(string)] = true
+	}
+
+	used := 0
+	for _, tags := range column(t, g, "customers", "tags") {
+		for _, tag := range tags.([]string) {
+			used++
+			if !known[tag] {
+				t.Errorf("customer tag %q is missing from the tags table", tag)
+			}
+		}
+	}
+	if used == 0 {
+		t.Fatal("no customer was tagged")
+	}
+}
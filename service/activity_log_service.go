@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+)
+
+type ActivityLogService struct {
+	activityLogs repository.ActivityLogRepository
+}
+
+func NewActivityLogService(activityLogs repository.ActivityLogRepository) *ActivityLogService {
+	return &ActivityLogService{activityLogs: activityLogs}
+}
+
+// Create
+func (s *ActivityLogService) Create(ctx context.Context, entry *model.ActivityLog) error {
+	return s.activityLogs.Create(ctx, entry)
+}
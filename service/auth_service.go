@@ -2,21 +2,28 @@ package service
 
 import (
 	"context"
-	"customize_crm/model"
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
-	"os"
-	"strconv"
+	"net/http"
 	"time"
 
+	"customize_crm/apperror"
+	"customize_crm/config"
+	"customize_crm/model"
+
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
+var ErrImpersonationNotAllowed = apperror.New(http.StatusForbidden, "impersonation_not_allowed", "user cannot be impersonated")
+
 type AuthService struct {
-	userService *UserService
-	jwtSecret   string
-	accessExp   time.Duration
-	refreshExp  time.Duration
+	userService    *UserService
+	jwtSecret      string
+	accessExp      time.Duration
+	refreshExp     time.Duration
+	impersonateExp time.Duration
 }
 
 type TokenDetails struct {
@@ -28,22 +35,13 @@ type TokenDetails struct {
 	RtExpires    int64
 }
 
-func NewAuthService(userService *UserService) *AuthService {
-	accessMinutes, _ := strconv.Atoi(os.Getenv("JWT_ACCESS_TOKEN_EXPIRY_MINUTES"))
-	if accessMinutes == 0 {
-		accessMinutes = 15
-	}
-
-	refreshDays, _ := strconv.Atoi(os.Getenv("JWT_REFRESH_TOKEN_EXPIRY_DAYS"))
-	if refreshDays == 0 {
-		refreshDays = 7
-	}
-
+func NewAuthService(userService *UserService, cfg config.JWTConfig) *AuthService {
 	return &AuthService{
-		userService: userService,
-		jwtSecret:   os.Getenv("JWT_SECRET"),
-		accessExp:   time.Duration(accessMinutes) * time.Minute,
-		refreshExp:  time.Duration(refreshDays) * 24 * time.Hour,
+		userService:    userService,
+		jwtSecret:      cfg.Secret,
+		accessExp:      cfg.AccessTokenExpiry(),
+		refreshExp:     cfg.RefreshTokenExpiry(),
+		impersonateExp: cfg.ImpersonationTokenExpiry(),
 	}
 }
 
@@ -82,6 +80,10 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*T
 		return nil, fmt.Errorf("invalid token claims")
 	}
 
+	if _, ok := claims["act"]; ok {
+		return nil, fmt.Errorf("impersonation tokens cannot be refreshed")
+	}
+
 	exp, ok := claims["exp"].(float64)
 	if !ok {
 		return nil, fmt.Errorf("invalid expiration time")
@@ -90,12 +92,25 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*T
 		return nil, fmt.Errorf("token expired")
 	}
 
-	userID, ok := claims["sub"].(string)
+	sub, ok := claims["sub"].(string)
 	if !ok {
 		return nil, fmt.Errorf("invalid user ID")
 	}
+	userID, err := uuid.Parse(sub)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID")
+	}
 
-	return s.CreateTokens(userID)
+	// Deactivated and deleted users must not get fresh tokens.
+	user, err := s.userService.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, fmt.Errorf("user account is disabled")
+	}
+
+	return s.CreateTokens(userID.String())
 }
 
 func (s *AuthService) CreateTokens(userID string) (*TokenDetails, error) {
@@ -135,6 +150,76 @@ func (s *AuthService) CreateTokens(userID string) (*TokenDetails, error) {
 	return td, nil
 }
 
+// Impersonate checks that subjectID may be impersonated by actorID and
+// issues the impersonation token. Admins, inactive users and the actor
+// themselves cannot be impersonated.
+func (s *AuthService) Impersonate(ctx context.Context, actorID, subjectID uuid.UUID) (*TokenDetails, *model.User, error) {
+	if actorID == subjectID {
+		return nil, nil, ErrImpersonationNotAllowed
+	}
+
+	subject, err := s.userService.GetByID(ctx, subjectID)
+	if err != nil {
+		return nil, nil, ErrUserNotFound
+	}
+
+	if !subject.IsActive {
+		return nil, nil, ErrImpersonationNotAllowed
+	}
+
+	role, err := s.userService.GetRoleByID(ctx, subject.RoleID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if role.Name == "Admin" {
+		return nil, nil, ErrImpersonationNotAllowed
+	}
+
+	tokens, err := s.CreateImpersonationToken(actorID.String(), subjectID.String())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, subject, nil
+}
+
+// CreateImpersonationToken issues a short-lived access token for subjectID
+// that also names actorID as the real actor. No refresh token is issued.
+func (s *AuthService) CreateImpersonationToken(actorID, subjectID string) (*TokenDetails, error) {
+	td := &TokenDetails{
+		AccessUUID: uuid.New().String(),
+		AtExpires:  time.Now().Add(s.impersonateExp).Unix(),
+	}
+
+	claims := jwt.MapClaims{
+		"sub": subjectID,
+		"exp": td.AtExpires,
+		"jti": td.AccessUUID,
+		"act": map[string]string{"sub": actorID},
+	}
+
+	at := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	accessToken, err := at.SignedString([]byte(s.jwtSecret))
+	if err != nil {
+		return nil, err
+	}
+	td.AccessToken = accessToken
+
+	return td, nil
+}
+
 func (s *AuthService) Logout(ctx context.Context) error {
 	return nil
 }
+
+// GenerateJWTSecret returns a new random signing secret. Tokens signed with
+// the previous secret stop validating once the new one is in use.
+func GenerateJWTSecret() (string, error) {
+	buf := make([]byte, 48)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
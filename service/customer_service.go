@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+type CustomerService struct {
+	customers     repository.CustomerRepository
+	contacts      repository.ContactRepository
+	opportunities repository.OpportunityRepository
+}
+
+func NewCustomerService(customers repository.CustomerRepository, contacts repository.ContactRepository, opportunities repository.OpportunityRepository) *CustomerService {
+	return &CustomerService{customers: customers, contacts: contacts, opportunities: opportunities}
+}
+
+// List returns the customers visible within scope that match the list query,
+// with the contacts and visible opportunities it asks to include.
+func (s *CustomerService) List(ctx context.Context, scope *model.VisibilityScope, params repository.PageParams) (*model.CustomerListResponse, error) {
+	page, err := s.customers.List(ctx, scope, params)
+	if err != nil || len(page.Data) == 0 {
+		return page, err
+	}
+
+	ids := make([]uuid.UUID, len(page.Data))
+	byID := make(map[uuid.UUID]*model.Customer, len(page.Data))
+	for i, customer := range page.Data {
+		ids[i] = customer.ID
+		byID[customer.ID] = customer
+	}
+
+	if params.Query.Includes("contacts") {
+		contacts, err := s.contacts.ListByCustomers(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, contact := range contacts {
+			customer := byID[contact.CustomerID]
+			customer.Contacts = append(customer.Contacts, contact)
+		}
+	}
+
+	if params.Query.Includes("opportunities") {
+		opportunities, err := s.opportunities.ListByCustomers(ctx, scope, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, opportunity := range opportunities {
+			customer := byID[opportunity.CustomerID]
+			customer.Opportunities = append(customer.Opportunities, opportunity)
+		}
+	}
+
+	return page, nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"customize_crm/apperror"
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDelegationNotFound      = apperror.New(http.StatusNotFound, "delegation_not_found", "delegation not found")
+	ErrInvalidDelegationPeriod = apperror.New(http.StatusBadRequest, "invalid_delegation_period", "delegation must end after it starts")
+	ErrInvalidDelegate         = apperror.New(http.StatusBadRequest, "invalid_delegate", "delegate must be another active user")
+)
+
+type DelegationService struct {
+	delegations repository.DelegationRepository
+}
+
+func NewDelegationService(delegations repository.DelegationRepository) *DelegationService {
+	return &DelegationService{delegations: delegations}
+}
+
+// Create
+func (s *DelegationService) Create(ctx context.Context, delegation *model.Delegation) error {
+	if !delegation.EndsAt.After(delegation.StartsAt) {
+		return ErrInvalidDelegationPeriod
+	}
+
+	if delegation.DelegateID == delegation.DelegatorID {
+		return ErrInvalidDelegate
+	}
+
+	err := s.delegations.Create(ctx, delegation)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrInvalidDelegate
+	}
+
+	return err
+}
+
+// ListForUser returns the delegations the user has granted or received,
+// including past and revoked ones.
+func (s *DelegationService) ListForUser(ctx context.Context, userID uuid.UUID) ([]*model.Delegation, error) {
+	return s.delegations.ListForUser(ctx, userID)
+}
+
+// GetByID
+func (s *DelegationService) GetByID(ctx context.Context, id uuid.UUID) (*model.Delegation, error) {
+	delegation, err := s.delegations.GetByID(ctx, id)
+	return delegation, delegationError(err)
+}
+
+// Revoke ends a delegation immediately. Revoking twice is a no-op.
+func (s *DelegationService) Revoke(ctx context.Context, id uuid.UUID) error {
+	return delegationError(s.delegations.Revoke(ctx, id))
+}
+
+func delegationError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrDelegationNotFound
+	}
+	return err
+}
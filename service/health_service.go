@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"customize_crm/migration"
+	"customize_crm/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const readinessCheckTimeout = 2 * time.Second
+
+type HealthService struct {
+	db       *pgxpool.Pool
+	migrator *migration.Migrator
+	draining atomic.Bool
+}
+
+func NewHealthService(db *pgxpool.Pool) (*HealthService, error) {
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HealthService{db: db, migrator: migrator}, nil
+}
+
+// Drain marks the instance as not ready so load balancers stop routing new
+// requests to it while in-flight ones finish.
+func (s *HealthService) Drain() {
+	s.draining.Store(true)
+}
+
+// Readiness checks that the instance is not draining, that the pool can reach
+// the database and that the schema is at least at the version this build
+// embeds. A newer schema is accepted so old instances keep serving during a
+// rolling deploy.
+func (s *HealthService) Readiness(ctx context.Context) (*model.ReadinessResponse, bool) {
+	ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+
+	checks := map[string]string{}
+	ready := true
+	fail := func(name, msg string) {
+		checks[name] = msg
+		ready = false
+	}
+
+	if s.draining.Load() {
+		fail("shutdown", "draining")
+	} else {
+		checks["shutdown"] = "ok"
+	}
+
+	if err := s.db.Ping(ctx); err != nil {
+		fail("database", "unreachable")
+	} else {
+		checks["database"] = "ok"
+	}
+
+	version, err := s.migrator.Version(ctx)
+	switch {
+	case err != nil:
+		fail("migrations", "unable to read schema version")
+	case version < s.migrator.Latest():
+		fail("migrations", fmt.Sprintf("schema at version %d, expected %d", version, s.migrator.Latest()))
+	default:
+		checks["migrations"] = "ok"
+	}
+
+	status := "ready"
+	if !ready {
+		status = "not ready"
+	}
+
+	return &model.ReadinessResponse{Status: status, Checks: checks}, ready
+}
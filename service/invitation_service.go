@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"customize_crm/apperror"
+	"customize_crm/config"
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvitationNotFound = apperror.New(http.StatusNotFound, "invitation_not_found", "invitation not found")
+	ErrInvitationInvalid  = apperror.New(http.StatusBadRequest, "invitation_invalid", "invitation is invalid or has expired")
+	ErrInvitationClosed   = apperror.New(http.StatusConflict, "invitation_closed", "invitation has already been accepted or revoked")
+)
+
+type InvitationService struct {
+	transactor  repository.Transactor
+	invitations repository.InvitationRepository
+	users       repository.UserRepository
+	roles       repository.RoleRepository
+	mailService *MailService
+	acceptURL   string
+	expiry      time.Duration
+}
+
+func NewInvitationService(transactor repository.Transactor, invitations repository.InvitationRepository, users repository.UserRepository, roles repository.RoleRepository, mailService *MailService, cfg config.InvitationConfig) *InvitationService {
+	return &InvitationService{
+		transactor:  transactor,
+		invitations: invitations,
+		users:       users,
+		roles:       roles,
+		mailService: mailService,
+		acceptURL:   cfg.AcceptURL,
+		expiry:      cfg.Expiry(),
+	}
+}
+
+// Invite creates an inactive user together with a pending invitation and
+// emails the invitee a single-use link to set their password.
+func (s *InvitationService) Invite(ctx context.Context, user *model.User, invitedBy uuid.UUID) (*model.Invitation, error) {
+	token, tokenHash, err := newInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.roles.GetByID(ctx, user.RoleID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUnknownRole
+		}
+		return nil, err
+	}
+
+	user.PasswordHash = ""
+	user.IsActive = false
+
+	invitation := &model.Invitation{
+		Email:     user.Email,
+		InvitedBy: &invitedBy,
+		Status:    model.InvitationStatusPending,
+		ExpiresAt: time.Now().Add(s.expiry),
+	}
+
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.users.Create(ctx, user); err != nil {
+			return err
+		}
+
+		invitation.UserID = user.ID
+		return s.invitations.Create(ctx, invitation, tokenHash)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sendInvitation(user, token, invitation.ExpiresAt); err != nil {
+		return invitation, err
+	}
+
+	return invitation, nil
+}
+
+// GetAll
+func (s *InvitationService) GetAll(ctx context.Context) ([]*model.Invitation, error) {
+	invitations, err := s.invitations.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, invitation := range invitations {
+		setInvitationStatus(invitation)
+	}
+
+	return invitations, nil
+}
+
+// GetByID
+func (s *InvitationService) GetByID(ctx context.Context, id uuid.UUID) (*model.Invitation, error) {
+	invitation, err := s.invitations.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	setInvitationStatus(invitation)
+	return invitation, nil
+}
+
+// Resend issues a fresh token with a new expiry, invalidating the old link.
+func (s *InvitationService) Resend(ctx context.Context, id uuid.UUID) (*model.Invitation, error) {
+	invitation, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if invitation.AcceptedAt != nil || invitation.RevokedAt != nil {
+		return nil, ErrInvitationClosed
+	}
+
+	token, tokenHash, err := newInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.expiry)
+	err = s.invitations.Renew(ctx, id, tokenHash, expiresAt)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvitationClosed
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err = s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.GetByID(ctx, invitation.UserID)
+	if err != nil {
+		return nil, userError(err)
+	}
+
+	if err := s.sendInvitation(user, token, invitation.ExpiresAt); err != nil {
+		return invitation, err
+	}
+
+	return invitation, nil
+}
+
+// Revoke
+func (s *InvitationService) Revoke(ctx context.Context, id uuid.UUID) error {
+	err := s.invitations.Revoke(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		if _, err := s.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrInvitationClosed
+	}
+
+	return err
+}
+
+// Accept sets the invitee's password, activates the account and consumes
+// the invitation.
+func (s *InvitationService) Accept(ctx context.Context, token, password string) (*model.User, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	var userID uuid.UUID
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		id, err := s.invitations.Accept(ctx, hashInvitationToken(token))
+		if err != nil {
+			return err
+		}
+		userID = id
+
+		return s.users.Activate(ctx, userID, string(hashedPassword))
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvitationInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	return user, userError(err)
+}
+
+func (s *InvitationService) sendInvitation(user *model.User, token string, expiresAt time.Time) error {
+	link := s.acceptURL + "?token=" + token
+	if strings.Contains(s.acceptURL, "?") {
+		link = s.acceptURL + "&token=" + token
+	}
+
+	body := fmt.Sprintf(
+		"Hello %s,\n\nYou have been invited to Customize CRM as %s.\n"+
+			"Set your password to activate your account:\n\n%s\n\n"+
+			"This link can be used once and expires on %s.\n",
+		user.FirstName, user.Username, link, expiresAt.Format(time.RFC1123),
+	)
+
+	return s.mailService.Send(user.Email, "You're invited to Customize CRM", body)
+}
+
+// setInvitationStatus derives the status from the timestamps.
+func setInvitationStatus(invitation *model.Invitation) {
+	switch {
+	case invitation.AcceptedAt != nil:
+		invitation.Status = model.InvitationStatusAccepted
+	case invitation.RevokedAt != nil:
+		invitation.Status = model.InvitationStatusRevoked
+	case invitation.ExpiresAt.Before(time.Now()):
+		invitation.Status = model.InvitationStatusExpired
+	default:
+		invitation.Status = model.InvitationStatusPending
+	}
+}
+
+func newInvitationToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashInvitationToken(token), nil
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"net/smtp"
+	"strings"
+
+	"customize_crm/config"
+)
+
+type MailService struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func NewMailService(cfg config.SMTPConfig) *MailService {
+	return &MailService{
+		host:     cfg.Host,
+		port:     cfg.Port,
+		username: cfg.Username,
+		password: cfg.Password,
+		from:     cfg.From,
+	}
+}
+
+// Send delivers a plain text email. When SMTP_HOST is not configured only the
+// recipient and subject are logged; the body may carry single-use tokens.
+func (s *MailService) Send(to, subject, body string) error {
+	if s.host == "" {
+		slog.Warn("SMTP_HOST not set, email not sent", "to", to, "subject", subject)
+		return nil
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", s.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n\r\n")
+	msg.WriteString(body)
+
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	return smtp.SendMail(s.host+":"+s.port, auth, s.from, []string{to}, []byte(msg.String()))
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+type OpportunityService struct {
+	opportunities repository.OpportunityRepository
+	customers     repository.CustomerRepository
+}
+
+func NewOpportunityService(opportunities repository.OpportunityRepository, customers repository.CustomerRepository) *OpportunityService {
+	return &OpportunityService{opportunities: opportunities, customers: customers}
+}
+
+// List returns the opportunities visible within scope that match the list
+// query, with their customers when it asks to include them and they are
+// visible too.
+func (s *OpportunityService) List(ctx context.Context, scope *model.VisibilityScope, params repository.PageParams) (*model.OpportunityListResponse, error) {
+	page, err := s.opportunities.List(ctx, scope, params)
+	if err != nil || !params.Query.Includes("customer") {
+		return page, err
+	}
+
+	customerIDs := make([]uuid.UUID, len(page.Data))
+	for i, opportunity := range page.Data {
+		customerIDs[i] = opportunity.CustomerID
+	}
+
+	customers, err := customersByID(ctx, s.customers, scope, customerIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, opportunity := range page.Data {
+		opportunity.Customer = customers[opportunity.CustomerID]
+	}
+
+	return page, nil
+}
+
+// customersByID loads the visible customers among ids.
+func customersByID(ctx context.Context, repo repository.CustomerRepository, scope *model.VisibilityScope, ids []uuid.UUID) (map[uuid.UUID]*model.Customer, error) {
+	byID := map[uuid.UUID]*model.Customer{}
+	if len(ids) == 0 {
+		return byID, nil
+	}
+
+	customers, err := repo.GetByIDs(ctx, scope, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, customer := range customers {
+		byID[customer.ID] = customer
+	}
+
+	return byID, nil
+}
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"customize_crm/apperror"
+	"customize_crm/config"
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPasskeySessionNotFound = apperror.New(http.StatusBadRequest, "passkey_session_not_found", "passkey session not found or expired")
+	ErrPasskeyNotFound        = apperror.New(http.StatusNotFound, "passkey_not_found", "passkey not found")
+	ErrPasskeyRegistered      = apperror.New(http.StatusConflict, "passkey_already_registered", "passkey is already registered")
+)
+
+type PasskeyService struct {
+	passkeys    repository.PasskeyRepository
+	userService *UserService
+	webAuthn    *webauthn.WebAuthn
+}
+
+// passkeyUser adapts model.User to the webauthn.User interface.
+type passkeyUser struct {
+	user        *model.User
+	credentials []webauthn.Credential
+}
+
+func (u *passkeyUser) WebAuthnID() []byte {
+	return u.user.ID[:]
+}
+
+func (u *passkeyUser) WebAuthnName() string {
+	return u.user.Username
+}
+
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	return strings.TrimSpace(u.user.FirstName + " " + u.user.LastName)
+}
+
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+func NewPasskeyService(passkeys repository.PasskeyRepository, userService *UserService, cfg config.WebAuthnConfig) (*PasskeyService, error) {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.RPID,
+		RPDisplayName: cfg.RPDisplayName,
+		RPOrigins:     cfg.RPOrigins,
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        webauthn.TimeoutConfig{Enforce: true, Timeout: 5 * time.Minute},
+			Registration: webauthn.TimeoutConfig{Enforce: true, Timeout: 5 * time.Minute},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasskeyService{
+		passkeys:    passkeys,
+		userService: userService,
+		webAuthn:    w,
+	}, nil
+}
+
+// BeginRegistration
+func (s *PasskeyService) BeginRegistration(ctx context.Context, userID uuid.UUID) (*protocol.CredentialCreation, uuid.UUID, error) {
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(user.credentials))
+	for _, cred := range user.credentials {
+		exclusions = append(exclusions, cred.Descriptor())
+	}
+
+	creation, session, err := s.webAuthn.BeginRegistration(user,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+	)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	sessionID, err := s.saveSession(ctx, &userID, session)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	return creation, sessionID, nil
+}
+
+// FinishRegistration
+func (s *PasskeyService) FinishRegistration(ctx context.Context, userID, sessionID uuid.UUID, name string, response []byte) (*model.Passkey, error) {
+	session, err := s.takeSession(ctx, sessionID, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(response))
+	if err != nil {
+		return nil, registrationError(err)
+	}
+
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := s.webAuthn.CreateCredential(user, *session, parsed)
+	if err != nil {
+		return nil, registrationError(err)
+	}
+
+	raw, err := json.Marshal(credential)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = "Passkey"
+	}
+
+	passkey := &model.Passkey{
+		UserID:       userID,
+		CredentialID: credential.ID,
+		Name:         name,
+		Credential:   raw,
+	}
+
+	if err := s.passkeys.Create(ctx, passkey); err != nil {
+		if apperror.From(err).Code == apperror.CodeAlreadyExists {
+			return nil, ErrPasskeyRegistered
+		}
+		return nil, err
+	}
+
+	return passkey, nil
+}
+
+// registrationError reports a response that failed to parse or verify as a
+// client error, keeping the reason from the webauthn library.
+func registrationError(err error) error {
+	e := apperror.New(http.StatusBadRequest, "passkey_registration_failed", "passkey registration failed")
+	e.Err = err
+	return e
+}
+
+// BeginLogin starts a login ceremony. An empty username starts a
+// discoverable (usernameless) login.
+func (s *PasskeyService) BeginLogin(ctx context.Context, username string) (*protocol.CredentialAssertion, uuid.UUID, error) {
+	var (
+		assertion *protocol.CredentialAssertion
+		session   *webauthn.SessionData
+		userID    *uuid.UUID
+		err       error
+	)
+
+	if username == "" {
+		assertion, session, err = s.webAuthn.BeginDiscoverableLogin()
+	} else {
+		var user *model.User
+		user, err = s.userService.GetByUsername(ctx, username)
+		if err != nil {
+			return nil, uuid.Nil, errors.New("invalid credentials")
+		}
+
+		var pkUser *passkeyUser
+		pkUser, err = s.loadUser(ctx, user.ID)
+		if err != nil {
+			return nil, uuid.Nil, err
+		}
+
+		assertion, session, err = s.webAuthn.BeginLogin(pkUser)
+		userID = &user.ID
+	}
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	sessionID, err := s.saveSession(ctx, userID, session)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	return assertion, sessionID, nil
+}
+
+// FinishLogin validates an assertion and returns the authenticated user.
+func (s *PasskeyService) FinishLogin(ctx context.Context, sessionID uuid.UUID, response []byte) (*model.User, error) {
+	session, err := s.takeSession(ctx, sessionID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(response))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		user       *passkeyUser
+		credential *webauthn.Credential
+	)
+
+	if session.UserID == nil {
+		var found webauthn.User
+		found, credential, err = s.webAuthn.ValidatePasskeyLogin(func(rawID, userHandle []byte) (webauthn.User, error) {
+			id, err := uuid.FromBytes(userHandle)
+			if err != nil {
+				return nil, err
+			}
+			return s.loadUser(ctx, id)
+		}, *session, parsed)
+		if err != nil {
+			return nil, err
+		}
+		user = found.(*passkeyUser)
+	} else {
+		id, err := uuid.FromBytes(session.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		user, err = s.loadUser(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		credential, err = s.webAuthn.ValidateLogin(user, *session, parsed)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !user.user.IsActive {
+		return nil, errors.New("user account is disabled")
+	}
+
+	if credential.Authenticator.CloneWarning {
+		return nil, errors.New("authenticator may be cloned")
+	}
+
+	raw, err := json.Marshal(credential)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.passkeys.UpdateCredential(ctx, user.user.ID, credential.ID, raw); err != nil {
+		return nil, err
+	}
+
+	return user.user, nil
+}
+
+// ListByUser
+func (s *PasskeyService) ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.Passkey, error) {
+	return s.passkeys.ListByUser(ctx, userID)
+}
+
+// GetByID returns one of the user's passkeys.
+func (s *PasskeyService) GetByID(ctx context.Context, userID, id uuid.UUID) (*model.Passkey, error) {
+	passkey, err := s.passkeys.GetByID(ctx, userID, id)
+	return passkey, passkeyError(err)
+}
+
+// Rename
+func (s *PasskeyService) Rename(ctx context.Context, userID, id uuid.UUID, name string) error {
+	return passkeyError(s.passkeys.Rename(ctx, userID, id, name))
+}
+
+// Delete
+func (s *PasskeyService) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	return passkeyError(s.passkeys.Delete(ctx, userID, id))
+}
+
+func (s *PasskeyService) loadUser(ctx context.Context, userID uuid.UUID) (*passkeyUser, error) {
+	user, err := s.userService.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	passkeys, err := s.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]webauthn.Credential, 0, len(passkeys))
+	for _, passkey := range passkeys {
+		var credential webauthn.Credential
+		if err := json.Unmarshal(passkey.Credential, &credential); err != nil {
+			return nil, err
+		}
+		credentials = append(credentials, credential)
+	}
+
+	return &passkeyUser{user: user, credentials: credentials}, nil
+}
+
+func (s *PasskeyService) saveSession(ctx context.Context, userID *uuid.UUID, session *webauthn.SessionData) (uuid.UUID, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return s.passkeys.CreateSession(ctx, userID, data, session.Expires)
+}
+
+// takeSession loads and deletes a ceremony session so that every challenge
+// can be answered at most once.
+func (s *PasskeyService) takeSession(ctx context.Context, id uuid.UUID, userID *uuid.UUID) (*webauthn.SessionData, error) {
+	owner, data, err := s.passkeys.TakeSession(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrPasskeySessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if userID != nil && (owner == nil || *owner != *userID) {
+		return nil, ErrPasskeySessionNotFound
+	}
+
+	var session webauthn.SessionData
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func passkeyError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrPasskeyNotFound
+	}
+	return err
+}
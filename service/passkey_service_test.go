@@ -0,0 +1,436 @@
+package service_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"customize_crm/apperror"
+	"customize_crm/config"
+	"customize_crm/model"
+	"customize_crm/repository/memory"
+	"customize_crm/service"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/google/uuid"
+)
+
+const (
+	testRPID   = "localhost"
+	testOrigin = "http://localhost"
+)
+
+// Authenticator data flags.
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttested     = 0x40
+)
+
+// authenticator is a software ES256 authenticator with "none" attestation.
+type authenticator struct {
+	t            *testing.T
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+}
+
+func newAuthenticator(t *testing.T) *authenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credentialID := make([]byte, 16)
+	if _, err := rand.Read(credentialID); err != nil {
+		t.Fatal(err)
+	}
+
+	return &authenticator{t: t, key: key, credentialID: credentialID}
+}
+
+// register answers a registration challenge and remembers the user handle,
+// as a resident key would.
+func (a *authenticator) register(creation *protocol.CredentialCreation) []byte {
+	a.t.Helper()
+
+	a.userHandle = []byte(creation.Response.User.ID.(protocol.URLEncodedBase64))
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  1, // P-256
+		XCoord: a.key.X.FillBytes(make([]byte, 32)),
+		YCoord: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+
+	authData := a.authData(flagUserPresent | flagUserVerified | flagAttested)
+	authData = append(authData, make([]byte, 16)...) // AAGUID
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.credentialID)))
+	authData = append(authData, a.credentialID...)
+	authData = append(authData, publicKey...)
+
+	attestation, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+
+	clientData := a.clientData(protocol.CreateCeremony, creation.Response.Challenge)
+
+	return a.marshal(map[string]any{
+		"clientDataJSON":    encode(clientData),
+		"attestationObject": encode(attestation),
+	})
+}
+
+// login answers a login challenge with the given signature counter.
+func (a *authenticator) login(assertion *protocol.CredentialAssertion, signCount uint32) []byte {
+	a.t.Helper()
+
+	a.signCount = signCount
+	authData := a.authData(flagUserPresent | flagUserVerified)
+	clientData := a.clientData(protocol.AssertCeremony, assertion.Response.Challenge)
+
+	hash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(authData, hash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		a.t.Fatal(err)
+	}
+
+	return a.marshal(map[string]any{
+		"clientDataJSON":    encode(clientData),
+		"authenticatorData": encode(authData),
+		"signature":         encode(signature),
+		"userHandle":        encode(a.userHandle),
+	})
+}
+
+func (a *authenticator) authData(flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+
+	data := append(rpIDHash[:], flags)
+	return binary.BigEndian.AppendUint32(data, a.signCount)
+}
+
+func (a *authenticator) clientData(ceremony protocol.CeremonyType, challenge protocol.URLEncodedBase64) []byte {
+	data, err := json.Marshal(map[string]string{
+		"type":      string(ceremony),
+		"challenge": challenge.String(),
+		"origin":    testOrigin,
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return data
+}
+
+func (a *authenticator) marshal(response map[string]any) []byte {
+	data, err := json.Marshal(map[string]any{
+		"id":       encode(a.credentialID),
+		"rawId":    encode(a.credentialID),
+		"type":     "public-key",
+		"response": response,
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return data
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func newPasskeyService(t *testing.T) (*service.PasskeyService, *model.User) {
+	t.Helper()
+
+	ctx := context.Background()
+	store := memory.NewStore()
+	userService := service.NewUserService(store.Users(), store.Roles())
+
+	role := &model.Role{Name: "Sales"}
+	if _, err := store.Roles().CreateIfMissing(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+
+	user := &model.User{Username: "alice", Email: "alice@example.com", FirstName: "Alice", LastName: "Sales", RoleID: role.ID, IsActive: true}
+	if err := userService.Create(ctx, user, "correct-horse"); err != nil {
+		t.Fatal(err)
+	}
+
+	passkeys, err := service.NewPasskeyService(store.Passkeys(), userService, config.WebAuthnConfig{
+		RPID:          testRPID,
+		RPDisplayName: "CRM",
+		RPOrigins:     []string{testOrigin},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return passkeys, user
+}
+
+// registerPasskey runs a registration ceremony for the user.
+func registerPasskey(t *testing.T, passkeys *service.PasskeyService, userID uuid.UUID, a *authenticator) *model.Passkey {
+	t.Helper()
+
+	ctx := context.Background()
+	creation, sessionID, err := passkeys.BeginRegistration(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	passkey, err := passkeys.FinishRegistration(ctx, userID, sessionID, "Laptop", a.register(creation))
+	if err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+
+	return passkey
+}
+
+func TestPasskeyRegistration(t *testing.T) {
+	ctx := context.Background()
+	passkeys, user := newPasskeyService(t)
+	a := newAuthenticator(t)
+
+	creation, sessionID, err := passkeys.BeginRegistration(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creation.Response.RelyingParty.ID != testRPID {
+		t.Errorf("relying party = %q, want %q", creation.Response.RelyingParty.ID, testRPID)
+	}
+
+	response := a.register(creation)
+	passkey, err := passkeys.FinishRegistration(ctx, user.ID, sessionID, "Laptop", response)
+	if err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+	if passkey.Name != "Laptop" || string(passkey.CredentialID) != string(a.credentialID) {
+		t.Errorf("passkey = %+v", passkey)
+	}
+
+	// The session is consumed, so the same challenge cannot be answered twice.
+	if _, err := passkeys.FinishRegistration(ctx, user.ID, sessionID, "Laptop", response); !errors.Is(err, service.ErrPasskeySessionNotFound) {
+		t.Errorf("replayed FinishRegistration error = %v, want %v", err, service.ErrPasskeySessionNotFound)
+	}
+
+	listed, err := passkeys.ListByUser(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 || listed[0].ID != passkey.ID {
+		t.Errorf("ListByUser = %+v, want the registered passkey", listed)
+	}
+
+	// A registered credential is excluded from further registrations.
+	creation, _, err = passkeys.BeginRegistration(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(creation.Response.CredentialExcludeList) != 1 {
+		t.Errorf("exclude list = %+v, want the registered credential", creation.Response.CredentialExcludeList)
+	}
+}
+
+func TestPasskeyRegistrationRejectsOtherUsersSession(t *testing.T) {
+	ctx := context.Background()
+	passkeys, user := newPasskeyService(t)
+
+	creation, sessionID, err := passkeys.BeginRegistration(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = passkeys.FinishRegistration(ctx, uuid.New(), sessionID, "Laptop", newAuthenticator(t).register(creation))
+	if !errors.Is(err, service.ErrPasskeySessionNotFound) {
+		t.Errorf("FinishRegistration error = %v, want %v", err, service.ErrPasskeySessionNotFound)
+	}
+}
+
+func TestPasskeyRegistrationErrors(t *testing.T) {
+	ctx := context.Background()
+	passkeys, user := newPasskeyService(t)
+	a := newAuthenticator(t)
+
+	creation, _, err := passkeys.BeginRegistration(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	answered := a.register(creation)
+
+	tests := []struct {
+		name     string
+		response []byte
+	}{
+		{"malformed response", []byte(`{"id":"x"}`)},
+		{"answer to another challenge", answered},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, sessionID, err := passkeys.BeginRegistration(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = passkeys.FinishRegistration(ctx, user.ID, sessionID, "Laptop", tt.response)
+			if e := apperror.From(err); e.Status != http.StatusBadRequest || e.Code != "passkey_registration_failed" {
+				t.Errorf("FinishRegistration error = %v (%d %s), want 400 passkey_registration_failed", err, e.Status, e.Code)
+			}
+		})
+	}
+
+	registerPasskey(t, passkeys, user.ID, a)
+
+	// The exclusion list is only advisory, so the store has to catch a
+	// credential registered twice.
+	creation, sessionID, err := passkeys.BeginRegistration(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := passkeys.FinishRegistration(ctx, user.ID, sessionID, "Again", a.register(creation)); !errors.Is(err, service.ErrPasskeyRegistered) {
+		t.Errorf("duplicate FinishRegistration error = %v, want %v", err, service.ErrPasskeyRegistered)
+	}
+}
+
+func TestPasskeyLogin(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+	}{
+		{"by username", "alice"},
+		{"discoverable", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			passkeys, user := newPasskeyService(t)
+			a := newAuthenticator(t)
+			registerPasskey(t, passkeys, user.ID, a)
+
+			assertion, sessionID, err := passkeys.BeginLogin(ctx, tt.username)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if allowed := len(assertion.Response.AllowedCredentials); (tt.username == "") != (allowed == 0) {
+				t.Errorf("allowed credentials = %d for username %q", allowed, tt.username)
+			}
+
+			response := a.login(assertion, 1)
+			loggedIn, err := passkeys.FinishLogin(ctx, sessionID, response)
+			if err != nil {
+				t.Fatalf("FinishLogin: %v", err)
+			}
+			if loggedIn.ID != user.ID {
+				t.Errorf("logged in as %s, want %s", loggedIn.ID, user.ID)
+			}
+
+			listed, err := passkeys.ListByUser(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(listed) != 1 || listed[0].LastUsedAt == nil {
+				t.Errorf("passkey not marked as used: %+v", listed)
+			}
+
+			// Replaying the same assertion fails because the session is gone.
+			if _, err := passkeys.FinishLogin(ctx, sessionID, response); !errors.Is(err, service.ErrPasskeySessionNotFound) {
+				t.Errorf("replayed FinishLogin error = %v, want %v", err, service.ErrPasskeySessionNotFound)
+			}
+		})
+	}
+}
+
+func TestPasskeyLoginUnknownUsername(t *testing.T) {
+	passkeys, _ := newPasskeyService(t)
+
+	if _, _, err := passkeys.BeginLogin(context.Background(), "mallory"); err == nil {
+		t.Error("BeginLogin succeeded for an unknown user")
+	}
+}
+
+func TestPasskeyLoginRejectsSignCountRegression(t *testing.T) {
+	ctx := context.Background()
+	passkeys, user := newPasskeyService(t)
+	a := newAuthenticator(t)
+	registerPasskey(t, passkeys, user.ID, a)
+
+	assertion, sessionID, err := passkeys.BeginLogin(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := passkeys.FinishLogin(ctx, sessionID, a.login(assertion, 5)); err != nil {
+		t.Fatalf("FinishLogin: %v", err)
+	}
+
+	// A counter that goes backwards suggests a cloned authenticator.
+	assertion, sessionID, err = passkeys.BeginLogin(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := passkeys.FinishLogin(ctx, sessionID, a.login(assertion, 3)); err == nil {
+		t.Error("FinishLogin accepted a sign count that went backwards")
+	}
+
+	// The rejected login must not have stored the lower counter.
+	assertion, sessionID, err = passkeys.BeginLogin(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := passkeys.FinishLogin(ctx, sessionID, a.login(assertion, 4)); err == nil {
+		t.Error("FinishLogin accepted a sign count below the last accepted one")
+	}
+
+	assertion, sessionID, err = passkeys.BeginLogin(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := passkeys.FinishLogin(ctx, sessionID, a.login(assertion, 6)); err != nil {
+		t.Errorf("FinishLogin with an advancing sign count: %v", err)
+	}
+}
+
+func TestPasskeyLoginRejectsForeignSignature(t *testing.T) {
+	ctx := context.Background()
+	passkeys, user := newPasskeyService(t)
+	a := newAuthenticator(t)
+	registerPasskey(t, passkeys, user.ID, a)
+
+	assertion, sessionID, err := passkeys.BeginLogin(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same credential ID, different private key.
+	impostor := newAuthenticator(t)
+	impostor.credentialID, impostor.userHandle = a.credentialID, a.userHandle
+
+	if _, err := passkeys.FinishLogin(ctx, sessionID, impostor.login(assertion, 1)); err == nil {
+		t.Error("FinishLogin accepted a signature from another key")
+	}
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+)
+
+type ProductService struct {
+	products repository.ProductRepository
+}
+
+func NewProductService(products repository.ProductRepository) *ProductService {
+	return &ProductService{products: products}
+}
+
+// List returns the products matching the list query, newest first unless it
+// sets another order.
+func (s *ProductService) List(ctx context.Context, params repository.PageParams) (*model.ProductListResponse, error) {
+	return s.products.List(ctx, params)
+}
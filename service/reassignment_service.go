@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"customize_crm/apperror"
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidEntityType = apperror.New(http.StatusBadRequest, "invalid_entity_type", "entity types must be customers, opportunities or tasks")
+
+type ReassignmentService struct {
+	transactor    repository.Transactor
+	reassignments repository.ReassignmentRepository
+	activityLogs  repository.ActivityLogRepository
+}
+
+func NewReassignmentService(transactor repository.Transactor, reassignments repository.ReassignmentRepository, activityLogs repository.ActivityLogRepository) *ReassignmentService {
+	return &ReassignmentService{transactor: transactor, reassignments: reassignments, activityLogs: activityLogs}
+}
+
+// Reassign moves every record matching the filter to req.ToUserID in a single
+// transaction and stores an audit record listing the affected IDs. A dry run
+// performs the same updates, reports them, and rolls back.
+func (s *ReassignmentService) Reassign(ctx context.Context, req model.ReassignmentRequest, performedBy uuid.UUID) (*model.Reassignment, error) {
+	entityTypes := req.EntityTypes
+	if len(entityTypes) == 0 {
+		entityTypes = []string{model.EntityCustomers, model.EntityOpportunities, model.EntityTasks}
+	}
+
+	for _, entityType := range entityTypes {
+		if entityType != model.EntityCustomers && entityType != model.EntityOpportunities && entityType != model.EntityTasks {
+			return nil, ErrInvalidEntityType
+		}
+	}
+
+	if req.ToUserID == req.FromUserID {
+		return nil, ErrInvalidReassignTarget
+	}
+
+	filter, err := json.Marshal(req.ReassignmentFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	reassignment := &model.Reassignment{
+		FromUserID:     req.FromUserID,
+		ToUserID:       req.ToUserID,
+		PerformedBy:    performedBy,
+		Filter:         filter,
+		CustomerIDs:    []uuid.UUID{},
+		OpportunityIDs: []uuid.UUID{},
+		TaskIDs:        []uuid.UUID{},
+		DryRun:         req.DryRun,
+	}
+
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.reassignments.Reassign(ctx, reassignment, entityTypes, req); err != nil {
+			return err
+		}
+		if req.DryRun {
+			return nil
+		}
+
+		return s.activityLogs.Create(ctx, &model.ActivityLog{
+			UserID:       &performedBy,
+			ActivityType: model.ActivityRecordsReassigned,
+			EntityType:   "reassignment",
+			EntityID:     reassignment.ID,
+			Description: fmt.Sprintf("Reassigned %d customers, %d opportunities and %d tasks",
+				len(reassignment.CustomerIDs), len(reassignment.OpportunityIDs), len(reassignment.TaskIDs)),
+			Metadata: filter,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reassignment, nil
+}
+
+// GetAll
+func (s *ReassignmentService) GetAll(ctx context.Context) ([]*model.Reassignment, error) {
+	return s.reassignments.GetAll(ctx)
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+)
+
+type ReportService struct {
+	opportunities repository.OpportunityRepository
+	tasks         repository.TaskRepository
+}
+
+func NewReportService(opportunities repository.OpportunityRepository, tasks repository.TaskRepository) *ReportService {
+	return &ReportService{opportunities: opportunities, tasks: tasks}
+}
+
+// Pipeline summarizes open opportunities visible within scope by stage.
+func (s *ReportService) Pipeline(ctx context.Context, scope *model.VisibilityScope) ([]*model.PipelineStageSummary, error) {
+	return s.opportunities.Pipeline(ctx, scope)
+}
+
+// Tasks summarizes tasks visible within scope by status.
+func (s *ReportService) Tasks(ctx context.Context, scope *model.VisibilityScope) ([]*model.TaskStatusSummary, error) {
+	return s.tasks.StatusSummary(ctx, scope)
+}
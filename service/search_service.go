@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"html"
+	"strings"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+)
+
+type SearchService struct {
+	search repository.SearchRepository
+}
+
+func NewSearchService(search repository.SearchRepository) *SearchService {
+	return &SearchService{search: search}
+}
+
+// Search returns the records visible in scope that match the query, best
+// match first.
+func (s *SearchService) Search(ctx context.Context, scope *model.VisibilityScope, params model.SearchParams) (*model.SearchResponse, error) {
+	results, err := s.search.Search(ctx, scope, params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		result.Highlight = escapeHighlight(result.Highlight)
+	}
+
+	return &model.SearchResponse{Data: results}, nil
+}
+
+// escapeHighlight HTML-escapes the record text around the <mark> tags the
+// repositories add, so clients can render the highlight as HTML.
+func escapeHighlight(highlight string) string {
+	var b strings.Builder
+	for _, marked := range strings.Split(highlight, "</mark>") {
+		before, match, found := strings.Cut(marked, "<mark>")
+		b.WriteString(html.EscapeString(before))
+		if found {
+			b.WriteString("<mark>" + html.EscapeString(match) + "</mark>")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"customize_crm/apperror"
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTagNotFound  = apperror.New(http.StatusNotFound, "tag_not_found", "tag not found")
+	ErrTagMergeSelf = apperror.New(http.StatusUnprocessableEntity, "tag_merge_self", "a tag cannot be merged into itself")
+)
+
+type TagService struct {
+	tags repository.TagRepository
+}
+
+func NewTagService(tags repository.TagRepository) *TagService {
+	return &TagService{tags: tags}
+}
+
+// Create
+func (s *TagService) Create(ctx context.Context, tag *model.Tag) error {
+	return s.tags.Create(ctx, tag)
+}
+
+// GetAll returns the vocabulary with the number of customers using each tag.
+func (s *TagService) GetAll(ctx context.Context) ([]*model.Tag, error) {
+	return s.tags.GetAll(ctx)
+}
+
+// GetByID
+func (s *TagService) GetByID(ctx context.Context, id uuid.UUID) (*model.Tag, error) {
+	tag, err := s.tags.GetByID(ctx, id)
+	return tag, tagError(err)
+}
+
+// Update saves the tag's name and color while its updated_at still equals
+// updatedAt, and returns repository.ErrStale once someone else has changed it.
+// A new name replaces the old one on every customer.
+func (s *TagService) Update(ctx context.Context, tag *model.Tag, updatedAt time.Time) error {
+	return tagError(s.tags.Update(ctx, tag, updatedAt))
+}
+
+// Delete removes the tag from the vocabulary and from every customer. Like
+// Update, it only proceeds while the tag's updated_at still equals updatedAt.
+func (s *TagService) Delete(ctx context.Context, id uuid.UUID, updatedAt time.Time) error {
+	return tagError(s.tags.Delete(ctx, id, updatedAt))
+}
+
+// Merge replaces the source tag with the target on every customer and then
+// deletes the source, returning the updated target. Like Delete, it only
+// proceeds while the source's updated_at still equals sourceUpdatedAt.
+func (s *TagService) Merge(ctx context.Context, sourceID, targetID uuid.UUID, sourceUpdatedAt time.Time) (*model.Tag, error) {
+	if sourceID == targetID {
+		return nil, ErrTagMergeSelf
+	}
+
+	tag, err := s.tags.Merge(ctx, sourceID, targetID, sourceUpdatedAt)
+	return tag, tagError(err)
+}
+
+// UpdateCustomerTags adds and removes tags on the customers visible in scope,
+// skipping the others, and returns how many customers changed. Added tags
+// must exist in the vocabulary.
+func (s *TagService) UpdateCustomerTags(ctx context.Context, scope *model.VisibilityScope, req *model.CustomerTagsRequest) (*model.CustomerTagsResult, error) {
+	updated, unknown, err := s.tags.UpdateCustomerTags(ctx, scope, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(unknown) > 0 {
+		return nil, unknownTags(req.Add, unknown)
+	}
+
+	return &model.CustomerTagsResult{Updated: updated}, nil
+}
+
+func tagError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrTagNotFound
+	}
+	return err
+}
+
+// unknownTags reports every position of the unknown names in the added tags.
+func unknownTags(names, unknown []string) error {
+	missing := map[string]bool{}
+	for _, name := range unknown {
+		missing[name] = true
+	}
+
+	var fields []apperror.FieldError
+	for i, name := range names {
+		if missing[name] {
+			field := "add[" + strconv.Itoa(i) + "]"
+			fields = append(fields, apperror.FieldError{Field: field, Code: "unknown_tag", Message: field + " is not a known tag: " + name})
+		}
+	}
+	if len(fields) > 0 {
+		return apperror.Validation(fields...)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+type TaskService struct {
+	tasks         repository.TaskRepository
+	customers     repository.CustomerRepository
+	opportunities repository.OpportunityRepository
+}
+
+func NewTaskService(tasks repository.TaskRepository, customers repository.CustomerRepository, opportunities repository.OpportunityRepository) *TaskService {
+	return &TaskService{tasks: tasks, customers: customers, opportunities: opportunities}
+}
+
+// List returns the tasks visible within scope that match the list query, with
+// the visible customers and opportunities it asks to include.
+func (s *TaskService) List(ctx context.Context, scope *model.VisibilityScope, params repository.PageParams) (*model.TaskListResponse, error) {
+	page, err := s.tasks.List(ctx, scope, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Query.Includes("customer") {
+		var ids []uuid.UUID
+		for _, task := range page.Data {
+			if task.CustomerID != nil {
+				ids = append(ids, *task.CustomerID)
+			}
+		}
+
+		customers, err := customersByID(ctx, s.customers, scope, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range page.Data {
+			if task.CustomerID != nil {
+				task.Customer = customers[*task.CustomerID]
+			}
+		}
+	}
+
+	if params.Query.Includes("opportunity") {
+		var ids []uuid.UUID
+		for _, task := range page.Data {
+			if task.OpportunityID != nil {
+				ids = append(ids, *task.OpportunityID)
+			}
+		}
+
+		if len(ids) > 0 {
+			opportunities, err := s.opportunities.GetByIDs(ctx, scope, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			byID := make(map[uuid.UUID]*model.Opportunity, len(opportunities))
+			for _, opportunity := range opportunities {
+				byID[opportunity.ID] = opportunity
+			}
+			for _, task := range page.Data {
+				if task.OpportunityID != nil {
+					task.Opportunity = byID[*task.OpportunityID]
+				}
+			}
+		}
+	}
+
+	return page, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"customize_crm/apperror"
+	"customize_crm/model"
+	"customize_crm/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTeamNotFound       = apperror.New(http.StatusNotFound, "team_not_found", "team not found")
+	ErrTeamMemberNotFound = apperror.New(http.StatusNotFound, "team_member_not_found", "team member not found")
+)
+
+type TeamService struct {
+	teams         repository.TeamRepository
+	opportunities repository.OpportunityRepository
+	tasks         repository.TaskRepository
+}
+
+func NewTeamService(teams repository.TeamRepository, opportunities repository.OpportunityRepository, tasks repository.TaskRepository) *TeamService {
+	return &TeamService{teams: teams, opportunities: opportunities, tasks: tasks}
+}
+
+// Create
+func (s *TeamService) Create(ctx context.Context, team *model.Team) error {
+	return s.teams.Create(ctx, team)
+}
+
+// GetAll
+func (s *TeamService) GetAll(ctx context.Context) ([]*model.Team, error) {
+	return s.teams.GetAll(ctx)
+}
+
+// GetByID returns the team together with its members.
+func (s *TeamService) GetByID(ctx context.Context, id uuid.UUID) (*model.Team, error) {
+	team, err := s.teams.GetByID(ctx, id)
+	if err != nil {
+		return nil, teamError(err)
+	}
+
+	team.Members, err = s.GetMembers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return team, nil
+}
+
+// Update saves the team while its updated_at still equals team.UpdatedAt,
+// and returns repository.ErrStale once someone else has changed it.
+func (s *TeamService) Update(ctx context.Context, team *model.Team) error {
+	return teamError(s.teams.Update(ctx, team))
+}
+
+// Delete removes the team and its memberships and clears the team from any
+// customers and opportunities assigned to it. Like Update, it only proceeds
+// while the team's updated_at still equals updatedAt.
+func (s *TeamService) Delete(ctx context.Context, id uuid.UUID, updatedAt time.Time) error {
+	return teamError(s.teams.Delete(ctx, id, updatedAt))
+}
+
+// GetMembers
+func (s *TeamService) GetMembers(ctx context.Context, teamID uuid.UUID) ([]*model.TeamMember, error) {
+	return s.teams.GetMembers(ctx, teamID)
+}
+
+// GetMemberRole returns the role of a user in a team, or ErrTeamMemberNotFound.
+func (s *TeamService) GetMemberRole(ctx context.Context, teamID, userID uuid.UUID) (string, error) {
+	role, err := s.teams.GetMemberRole(ctx, teamID, userID)
+	return role, teamMemberError(err)
+}
+
+// SetMember adds a user to the team or changes their membership role. The
+// team's updated_at is bumped, so its ETag changes with its members.
+func (s *TeamService) SetMember(ctx context.Context, teamID, userID uuid.UUID, role string) error {
+	return teamMemberError(s.teams.SetMember(ctx, teamID, userID, role))
+}
+
+// RemoveMember removes the user from the team and, like SetMember, bumps the
+// team's updated_at. Like Delete, it only proceeds while the team's
+// updated_at still equals updatedAt.
+func (s *TeamService) RemoveMember(ctx context.Context, teamID, userID uuid.UUID, updatedAt time.Time) error {
+	return teamMemberError(s.teams.RemoveMember(ctx, teamID, userID, updatedAt))
+}
+
+// AssignRecords assigns the given customers and opportunities to the team,
+// or clears the team from those currently held by it when assign is false.
+// With ownedOnly set, only records already owned by the team or its members
+// are touched, which keeps team leads from claiming other teams' records.
+// The team's updated_at is bumped; when updatedAt is given, the team must not
+// have changed since.
+func (s *TeamService) AssignRecords(ctx context.Context, teamID uuid.UUID, req model.TeamRecordsRequest, assign, ownedOnly bool, updatedAt *time.Time) error {
+	return teamError(s.teams.AssignRecords(ctx, teamID, req, assign, ownedOnly, updatedAt))
+}
+
+// Pipeline summarizes the team's open opportunities by stage.
+func (s *TeamService) Pipeline(ctx context.Context, teamID uuid.UUID) ([]*model.PipelineStageSummary, error) {
+	return s.opportunities.TeamPipeline(ctx, teamID)
+}
+
+// Opportunities lists the team's opportunities, newest first.
+func (s *TeamService) Opportunities(ctx context.Context, teamID uuid.UUID, params repository.PageParams) (*model.OpportunityListResponse, error) {
+	return s.opportunities.ListByTeam(ctx, teamID, params)
+}
+
+// Tasks lists tasks assigned to team members or linked to the team's
+// customers and opportunities, newest first.
+func (s *TeamService) Tasks(ctx context.Context, teamID uuid.UUID, params repository.PageParams) (*model.TaskListResponse, error) {
+	return s.tasks.ListByTeam(ctx, teamID, params)
+}
+
+func teamError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrTeamNotFound
+	}
+	return err
+}
+
+func teamMemberError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrTeamMemberNotFound
+	}
+	return err
+}
@@ -2,128 +2,106 @@ package service
 
 import (
 	"context"
-	"database/sql"
 	"errors"
+	"net/http"
 
+	"customize_crm/apperror"
 	"customize_crm/model"
+	"customize_crm/repository"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrUserNotFound          = apperror.New(http.StatusNotFound, "user_not_found", "user not found")
+	ErrUnknownRole           = apperror.Validation(apperror.FieldError{Field: "role_id", Code: "not_found", Message: "role_id does not name a role"})
+	ErrInvalidReassignTarget = repository.ErrInvalidReassignTarget
+	ErrManagerCycle          = repository.ErrManagerCycle
+)
+
 type UserService struct {
-	db *pgxpool.Pool
+	users repository.UserRepository
+	roles repository.RoleRepository
 }
 
-func NewUserService(db *pgxpool.Pool) *UserService {
-	return &UserService{db: db}
+func NewUserService(users repository.UserRepository, roles repository.RoleRepository) *UserService {
+	return &UserService{users: users, roles: roles}
 }
 
 // GetByID
 func (s *UserService) GetByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
-	query := `
-		SELECT id, username, email, password_hash, first_name, last_name, 
-			   role_id, department, created_at, updated_at, is_active
-		FROM users
-		WHERE id = $1
-	`
-
-	var user model.User
-	var department sql.NullString
-
-	err := s.db.QueryRow(ctx, query, id).Scan(
-		&user.ID, &user.Username, &user.Email, &user.PasswordHash,
-		&user.FirstName, &user.LastName, &user.RoleID, &department,
-		&user.CreatedAt, &user.UpdatedAt, &user.IsActive,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	if department.Valid {
-		dept := department.String
-		user.Department = &dept
-	}
+	user, err := s.users.GetByID(ctx, id)
+	return user, userError(err)
+}
 
-	return &user, nil
+// GetDeletedByID returns a soft-deleted user.
+func (s *UserService) GetDeletedByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	user, err := s.users.GetDeletedByID(ctx, id)
+	return user, userError(err)
 }
 
 // GetByUsername
 func (s *UserService) GetByUsername(ctx context.Context, username string) (*model.User, error) {
-	query := `
-		SELECT id, username, email, password_hash, first_name, last_name, 
-			   role_id, department, created_at, updated_at, is_active
-		FROM users
-		WHERE username = $1
-	`
-
-	var user model.User
-	var department sql.NullString
-
-	err := s.db.QueryRow(ctx, query, username).Scan(
-		&user.ID, &user.Username, &user.Email, &user.PasswordHash,
-		&user.FirstName, &user.LastName, &user.RoleID, &department,
-		&user.CreatedAt, &user.UpdatedAt, &user.IsActive,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	if department.Valid {
-		dept := department.String
-		user.Department = &dept
-	}
-
-	return &user, nil
+	user, err := s.users.GetByUsername(ctx, username)
+	return user, userError(err)
 }
 
 // GetAll
 func (s *UserService) GetAll(ctx context.Context) ([]*model.User, error) {
-	query := `
-		SELECT id, username, email, password_hash, first_name, last_name, 
-			   role_id, department, created_at, updated_at, is_active
-		FROM users
-	`
+	return s.users.GetAll(ctx)
+}
 
-	rows, err := s.db.Query(ctx, query)
+// List returns one page of the user directory together with the total number
+// of users matching the filters, with the roles and managers the query asks to
+// include.
+func (s *UserService) List(ctx context.Context, params repository.UserListParams) (*model.UserListResponse, error) {
+	page, err := s.users.List(ctx, params)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var users []*model.User
 
-	for rows.Next() {
-		var user model.User
-		var department sql.NullString
-
-		err := rows.Scan(
-			&user.ID, &user.Username, &user.Email, &user.PasswordHash,
-			&user.FirstName, &user.LastName, &user.RoleID, &department,
-			&user.CreatedAt, &user.UpdatedAt, &user.IsActive,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		if department.Valid {
-			dept := department.String
-			user.Department = &dept
+	if params.Query.Includes("role") {
+		roles := map[uuid.UUID]*model.Role{}
+		for _, user := range page.Data {
+			role, ok := roles[user.RoleID]
+			if !ok {
+				if role, err = s.roles.GetByID(ctx, user.RoleID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+					return nil, err
+				}
+				roles[user.RoleID] = role
+			}
+			user.Role = role
 		}
-
-		users = append(users, &user)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+	if params.Query.Includes("manager") {
+		managers := map[uuid.UUID]*model.User{}
+		for _, user := range page.Data {
+			if user.ManagerID == nil {
+				continue
+			}
+
+			manager, ok := managers[*user.ManagerID]
+			if !ok {
+				if manager, err = s.users.GetByID(ctx, *user.ManagerID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+					return nil, err
+				}
+				managers[*user.ManagerID] = manager
+			}
+			user.Manager = manager
+		}
 	}
 
-	return users, nil
+	return page, nil
 }
 
 // Create
 func (s *UserService) Create(ctx context.Context, user *model.User, password string) error {
+	if err := s.checkRole(ctx, user.RoleID); err != nil {
+		return err
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return err
@@ -131,34 +109,16 @@ func (s *UserService) Create(ctx context.Context, user *model.User, password str
 
 	user.PasswordHash = string(hashedPassword)
 
-	query := `
-		INSERT INTO users (username, email, password_hash, first_name, last_name, role_id, department, is_active)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, created_at, updated_at
-	`
-
-	err = s.db.QueryRow(ctx, query,
-		user.Username, user.Email, user.PasswordHash, user.FirstName, user.LastName,
-		user.RoleID, user.Department, user.IsActive,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
-
-	return err
+	return s.users.Create(ctx, user)
 }
 
 // Update
 func (s *UserService) Update(ctx context.Context, user *model.User) error {
-	query := `
-		UPDATE users
-		SET first_name = $1, last_name = $2, department = $3, role_id = $4, is_active = $5, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $6
-		RETURNING updated_at
-	`
-
-	err := s.db.QueryRow(ctx, query,
-		user.FirstName, user.LastName, user.Department, user.RoleID, user.IsActive, user.ID,
-	).Scan(&user.UpdatedAt)
+	if err := s.checkRole(ctx, user.RoleID); err != nil {
+		return err
+	}
 
-	return err
+	return userError(s.users.Update(ctx, user))
 }
 
 // UpdatePassword
@@ -168,21 +128,22 @@ func (s *UserService) UpdatePassword(ctx context.Context, id uuid.UUID, newPassw
 		return err
 	}
 
-	query := `
-		UPDATE users
-		SET password_hash = $1, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $2
-	`
+	return userError(s.users.UpdatePasswordHash(ctx, id, string(hashedPassword)))
+}
 
-	_, err = s.db.Exec(ctx, query, string(hashedPassword), id)
-	return err
+// Delete soft-deletes the given users. Their open customers, opportunities
+// and tasks are handed over to reassignTo, or unassigned when it is nil.
+func (s *UserService) Delete(ctx context.Context, ids []uuid.UUID, reassignTo *uuid.UUID) error {
+	return userError(s.users.Delete(ctx, ids, reassignTo))
 }
 
-// Delete
-func (s *UserService) Delete(ctx context.Context, ids []uuid.UUID) error {
-	query := `DELETE FROM users WHERE id = ANY($1)`
-	_, err := s.db.Exec(ctx, query, ids)
-	return err
+// Restore
+func (s *UserService) Restore(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	if err := s.users.Restore(ctx, id); err != nil {
+		return nil, userError(err)
+	}
+
+	return s.GetByID(ctx, id)
 }
 
 // Authenticate
@@ -204,29 +165,116 @@ func (s *UserService) Authenticate(ctx context.Context, username, password strin
 	return user, nil
 }
 
-func (s *UserService) GetRoleByID(ctx context.Context, id uuid.UUID) (*model.Role, error) {
-	query := `
-		SELECT id, name, description, permissions, created_at, updated_at
-		FROM roles
-		WHERE id = $1
-	`
-
-	var role model.Role
-	err := s.db.QueryRow(ctx, query, id).Scan(
-		&role.ID,
-		&role.Name,
-		&role.Description,
-		&role.Permissions,
-		&role.CreatedAt,
-		&role.UpdatedAt,
-	)
+// SetManager changes the reporting line of a user. A nil managerID removes the
+// manager. Assignments that would make the user report to themselves,
+// directly or through the chain, are rejected.
+func (s *UserService) SetManager(ctx context.Context, userID uuid.UUID, managerID *uuid.UUID) error {
+	return userError(s.users.SetManager(ctx, userID, managerID))
+}
+
+// GetReportIDs returns the IDs of everyone reporting to any of the given
+// users, directly or indirectly.
+func (s *UserService) GetReportIDs(ctx context.Context, userIDs ...uuid.UUID) ([]uuid.UUID, error) {
+	return s.users.GetReportIDs(ctx, userIDs...)
+}
+
+// GetActiveDelegatorIDs returns the users who currently delegate their
+// records to the given user.
+func (s *UserService) GetActiveDelegatorIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	return s.users.GetActiveDelegatorIDs(ctx, userID)
+}
+
+// VisibilityScope returns the record owners visible to a user: everyone for
+// admins, otherwise the user, anyone currently delegating to them, and the
+// direct and indirect reports of all of those.
+func (s *UserService) VisibilityScope(ctx context.Context, userID uuid.UUID, role string) (*model.VisibilityScope, error) {
+	if role == "Admin" {
+		return &model.VisibilityScope{All: true}, nil
+	}
+
+	delegators, err := s.GetActiveDelegatorIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := append([]uuid.UUID{userID}, delegators...)
+
+	reports, err := s.GetReportIDs(ctx, roots...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &role, nil
+	return &model.VisibilityScope{UserIDs: append(roots, reports...)}, nil
+}
+
+// GetOrgChart builds the reporting tree. With a nil root every top-level user
+// (one without a manager) becomes a root of the returned forest.
+func (s *UserService) GetOrgChart(ctx context.Context, root *uuid.UUID) ([]*model.OrgChartNode, error) {
+	ordered, err := s.users.GetOrgChartNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := map[uuid.UUID]*model.OrgChartNode{}
+	for _, node := range ordered {
+		nodes[node.ID] = node
+	}
+
+	roots := []*model.OrgChartNode{}
+	for _, node := range ordered {
+		if node.ManagerID != nil {
+			if manager, ok := nodes[*node.ManagerID]; ok {
+				manager.Reports = append(manager.Reports, node)
+				continue
+			}
+		}
+
+		roots = append(roots, node)
+	}
+
+	if root != nil {
+		node, ok := nodes[*root]
+		if !ok {
+			return nil, ErrUserNotFound
+		}
+		return []*model.OrgChartNode{node}, nil
+	}
+
+	return roots, nil
+}
+
+func (s *UserService) GetRoleByID(ctx context.Context, id uuid.UUID) (*model.Role, error) {
+	return s.roles.GetByID(ctx, id)
+}
+
+// GetRoleByName
+func (s *UserService) GetRoleByName(ctx context.Context, name string) (*model.Role, error) {
+	return s.roles.GetByName(ctx, name)
+}
+
+// CreateRoleIfMissing inserts the role unless one with the same name exists,
+// and reports whether it was created. Existing roles are left untouched.
+func (s *UserService) CreateRoleIfMissing(ctx context.Context, role *model.Role) (bool, error) {
+	return s.roles.CreateIfMissing(ctx, role)
 }
 
 func (s *UserService) GetUserByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
 	return s.GetByID(ctx, id)
 }
+
+// checkRole reports a role_id that does not name a role as a validation error.
+func (s *UserService) checkRole(ctx context.Context, roleID uuid.UUID) error {
+	_, err := s.roles.GetByID(ctx, roleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrUnknownRole
+	}
+	return err
+}
+
+// userError reports a missing record as ErrUserNotFound.
+func userError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrUserNotFound
+	}
+	return err
+}
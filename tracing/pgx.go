@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// QueryTracer creates a client span for every pgx query and COPY. Install it
+// as the ConnConfig.Tracer of the pool; queries become children of the span
+// in the context passed to them.
+type QueryTracer struct{}
+
+var (
+	_ pgx.QueryTracer    = QueryTracer{}
+	_ pgx.CopyFromTracer = QueryTracer{}
+)
+
+func (QueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := queryOperation(data.SQL)
+
+	ctx, _ = Tracer().Start(ctx, spanName(operation, conn),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBNamespace(conn.Config().Database),
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(strings.TrimSpace(data.SQL)),
+		),
+	)
+
+	return ctx
+}
+
+func (QueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	endSpan(ctx, data.CommandTag.RowsAffected(), data.Err)
+}
+
+func (QueryTracer) TraceCopyFromStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	ctx, _ = Tracer().Start(ctx, spanName("COPY", conn),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBNamespace(conn.Config().Database),
+			semconv.DBOperationName("COPY"),
+			semconv.DBCollectionName(data.TableName.Sanitize()),
+		),
+	)
+
+	return ctx
+}
+
+func (QueryTracer) TraceCopyFromEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	endSpan(ctx, data.CommandTag.RowsAffected(), data.Err)
+}
+
+func endSpan(ctx context.Context, rows int64, err error) {
+	span := trace.SpanFromContext(ctx)
+
+	// No rows is an expected outcome for lookups, not a failure.
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.SetAttributes(attribute.Int64("db.rows_affected", rows))
+	span.End()
+}
+
+// queryOperation returns the leading SQL keyword, such as SELECT or WITH.
+func queryOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
+
+func spanName(operation string, conn *pgx.Conn) string {
+	if database := conn.Config().Database; database != "" {
+		return operation + " " + database
+	}
+	return operation
+}
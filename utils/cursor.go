@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"customize_crm/apperror"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCursor = apperror.New(http.StatusBadRequest, "invalid_cursor", "invalid cursor")
+
+// Cursor is the keyset position of the last row of a page: the value of the
+// sort column and the row ID used as a tie-breaker.
+type Cursor struct {
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+func EncodeCursor(c Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
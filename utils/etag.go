@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"customize_crm/apperror"
+)
+
+// ETag derives a strong entity tag from a record's updated_at, which every
+// write to the record advances.
+func ETag(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(updatedAt.UnixMicro(), 36) + `"`
+}
+
+// CheckIfMatch requires an If-Match header naming etag, or "*". It returns
+// apperror.ErrPreconditionRequired when the header is missing and
+// apperror.ErrPreconditionFailed when no listed tag matches. Weak tags never
+// match, as If-Match uses strong comparison.
+func CheckIfMatch(r *http.Request, etag string) error {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return apperror.ErrPreconditionRequired
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag == "*" || tag == etag {
+			return nil
+		}
+	}
+
+	return apperror.ErrPreconditionFailed
+}
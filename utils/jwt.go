@@ -2,21 +2,31 @@ package utils
 
 import (
 	"errors"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func ValidateToken(tokenString string) (*jwt.RegisteredClaims, error) {
+// ActorClaim identifies the party acting on behalf of the token subject
+// (RFC 8693 "act" claim). It is only present on impersonation tokens.
+type ActorClaim struct {
+	Subject string `json:"sub"`
+}
+
+type Claims struct {
+	jwt.RegisteredClaims
+	Actor *ActorClaim `json:"act,omitempty"`
+}
+
+func ValidateToken(tokenString, secret string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(
 		tokenString,
-		&jwt.RegisteredClaims{},
+		&Claims{},
 		func(token *jwt.Token) (interface{}, error) {
 			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 				return nil, errors.New("unexpected signing method")
 			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
+			return []byte(secret), nil
 		},
 	)
 
@@ -24,7 +34,7 @@ func ValidateToken(tokenString string) (*jwt.RegisteredClaims, error) {
 		return nil, err
 	}
 
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
+	claims, ok := token.Claims.(*Claims)
 	if !ok || !token.Valid {
 		return nil, errors.New("invalid token")
 	}
@@ -3,6 +3,13 @@ package utils
 import (
 	"encoding/json"
 	"net/http"
+	"unicode"
+	"unicode/utf8"
+
+	"customize_crm/apperror"
+	"customize_crm/logging"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
 )
 
 func RespondWithJSON(w http.ResponseWriter, statusCode int, data interface{}) {
@@ -11,10 +18,57 @@ func RespondWithJSON(w http.ResponseWriter, statusCode int, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
-func RespondWithError(w http.ResponseWriter, statusCode int, message string) {
-	RespondWithJSON(w, statusCode, map[string]string{"error": message})
+// Problem is an RFC 7807 problem details body. Code is a stable identifier
+// clients can branch on; Detail is meant for people.
+type Problem struct {
+	Type      string                `json:"type"`
+	Title     string                `json:"title"`
+	Status    int                   `json:"status"`
+	Detail    string                `json:"detail,omitempty"`
+	Instance  string                `json:"instance,omitempty"`
+	Code      string                `json:"code"`
+	RequestID string                `json:"request_id,omitempty"`
+	Errors    []apperror.FieldError `json:"errors,omitempty"`
+}
+
+// RespondWithError answers with a problem whose code is derived from the
+// status, for errors that have no domain code of their own.
+func RespondWithError(w http.ResponseWriter, r *http.Request, statusCode int, message string) {
+	respondWithProblem(w, r, &apperror.Error{Status: statusCode, Code: apperror.CodeForStatus(statusCode), Message: message})
+}
+
+// RespondWithAppError maps err through apperror.From. Errors that map to a
+// 5xx status are logged, as their detail is not shown to the client.
+func RespondWithAppError(w http.ResponseWriter, r *http.Request, err error) {
+	appErr := apperror.From(err)
+	if appErr.Status >= http.StatusInternalServerError {
+		logging.FromContext(r.Context()).Error("request failed", "code", appErr.Code, "error", err)
+	}
+	respondWithProblem(w, r, appErr)
+}
+
+func respondWithProblem(w http.ResponseWriter, r *http.Request, e *apperror.Error) {
+	problem := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(e.Status),
+		Status:    e.Status,
+		Detail:    capitalize(e.Message),
+		Instance:  r.URL.Path,
+		Code:      e.Code,
+		RequestID: chimiddleware.GetReqID(r.Context()),
+		Errors:    e.Fields,
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(e.Status)
+	json.NewEncoder(w).Encode(problem)
 }
 
-type ErrorResponse struct {
-	Error string `json:"error"`
+// capitalize turns a Go-style error message into a sentence.
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
 }
@@ -0,0 +1,279 @@
+// Package validation decodes JSON request bodies and checks them against
+// `validate` struct tags, reporting every failing field at once.
+//
+// Rules are comma separated:
+//
+//	required   the value must be present and non-zero; strings must not be blank
+//	nonempty   like required, but only when an Optional or pointer field is present
+//	nullable   an Optional field may be sent as null
+//	email      a bare email address
+//	username   3-50 letters, digits, '.', '_' or '-', starting with a letter or digit
+//	hexcolor   a #RRGGBB color
+//	min=N      minimum length for strings and slices, minimum value for numbers
+//	max=N      maximum length for strings and slices, maximum value for numbers
+//	oneof=a b  one of the space separated values
+//	dive       validate each element of a slice with the remaining rules
+//
+// Zero values of fields that are not required skip the other rules, as do
+// Optional fields that were absent.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"customize_crm/apperror"
+)
+
+// maxBodyBytes bounds request bodies read by DecodeJSON.
+const maxBodyBytes = 1 << 20
+
+var (
+	usernamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{2,49}$`)
+	hexColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+)
+
+// optional is implemented by model.Optional.
+type optional interface {
+	IsSet() bool
+	IsNull() bool
+}
+
+// DecodeJSON decodes the request body into dst and validates it. Malformed
+// bodies are reported as 400 invalid_payload, values of the wrong JSON type
+// and failing rules as 422 validation_failed.
+func DecodeJSON(r *http.Request, dst any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodyBytes))
+	if err := decoder.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+
+	return Struct(dst)
+}
+
+func decodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return apperror.Validation(apperror.FieldError{
+			Field:   typeErr.Field,
+			Code:    "type",
+			Message: fmt.Sprintf("%s must be a JSON %s", typeErr.Field, jsonType(typeErr.Type)),
+		})
+	}
+
+	e := apperror.New(http.StatusBadRequest, "invalid_payload", "invalid request payload")
+	var maxErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, io.EOF):
+		e.Message = "request body is required"
+	case errors.As(err, &maxErr):
+		e = apperror.New(http.StatusRequestEntityTooLarge, "payload_too_large", "request body is too large")
+	}
+	e.Err = err
+	return e
+}
+
+// Struct validates v, a struct or a pointer to one, and returns an
+// *apperror.Error listing the failing fields, or nil.
+func Struct(v any) error {
+	var fields []apperror.FieldError
+	validateStruct(reflect.Indirect(reflect.ValueOf(v)), "", &fields)
+
+	if len(fields) > 0 {
+		return apperror.Validation(fields...)
+	}
+	return nil
+}
+
+func validateStruct(v reflect.Value, prefix string, fields *[]apperror.FieldError) {
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		spec := v.Type().Field(i)
+		if !spec.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(spec.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if spec.Anonymous && name == "" {
+			validateStruct(reflect.Indirect(v.Field(i)), prefix, fields)
+			continue
+		}
+		if name == "" {
+			name = spec.Name
+		}
+
+		validateField(v.Field(i), prefix+name, spec.Tag.Get("validate"), fields)
+	}
+}
+
+func validateField(field reflect.Value, name, tag string, fields *[]apperror.FieldError) {
+	var rules []string
+	if tag != "" {
+		rules = strings.Split(tag, ",")
+	}
+
+	fail := func(code, message string) {
+		*fields = append(*fields, apperror.FieldError{Field: name, Code: code, Message: name + " " + message})
+	}
+
+	if opt, ok := field.Interface().(optional); ok {
+		switch {
+		case !opt.IsSet():
+			return
+		case opt.IsNull():
+			if !hasRule(rules, "nullable") {
+				fail("null", "must not be null")
+			}
+			return
+		}
+		field = field.FieldByName("Value")
+	}
+
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			if hasRule(rules, "required") {
+				fail("required", "is required")
+			}
+			return
+		}
+		field = field.Elem()
+	}
+
+	if isBlank(field) {
+		switch {
+		case hasRule(rules, "required"):
+			fail("required", "is required")
+		case hasRule(rules, "nonempty"):
+			fail("nonempty", "must not be empty")
+		}
+		return
+	}
+
+	for i, rule := range rules {
+		key, param, _ := strings.Cut(rule, "=")
+
+		switch key {
+		case "required", "nonempty", "nullable":
+		case "email":
+			if addr, err := mail.ParseAddress(field.String()); err != nil || addr.Address != field.String() {
+				fail("email", "must be a valid email address")
+			}
+		case "username":
+			if !usernamePattern.MatchString(field.String()) {
+				fail("username", "must be 3-50 letters, digits, '.', '_' or '-', starting with a letter or digit")
+			}
+		case "hexcolor":
+			if !hexColorPattern.MatchString(field.String()) {
+				fail("hexcolor", "must be a #RRGGBB color")
+			}
+		case "min":
+			if n, _ := strconv.ParseFloat(param, 64); size(field) < n {
+				fail("min", "must be at least "+param+sizeUnit(field))
+			}
+		case "max":
+			if n, _ := strconv.ParseFloat(param, 64); size(field) > n {
+				fail("max", "must be at most "+param+sizeUnit(field))
+			}
+		case "oneof":
+			if !hasRule(strings.Fields(param), fmt.Sprint(field.Interface())) {
+				fail("oneof", "must be one of "+strings.Join(strings.Fields(param), ", "))
+			}
+		case "dive":
+			elemTag := strings.Join(rules[i+1:], ",")
+			for j := 0; j < field.Len(); j++ {
+				elem := field.Index(j)
+				elemName := fmt.Sprintf("%s[%d]", name, j)
+				if reflect.Indirect(elem).Kind() == reflect.Struct {
+					validateStruct(reflect.Indirect(elem), elemName+".", fields)
+					continue
+				}
+				validateField(elem, elemName, elemTag, fields)
+			}
+			return
+		default:
+			panic("validation: unknown rule " + key)
+		}
+	}
+
+	if field.Kind() == reflect.Struct && field.Type().PkgPath() != "time" {
+		validateStruct(field, name+".", fields)
+	}
+}
+
+func hasRule(rules []string, rule string) bool {
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// isBlank reports zero values, treating whitespace-only strings as empty.
+func isBlank(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func size(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String()))
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	return 0
+}
+
+func sizeUnit(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return " items"
+	}
+	return ""
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Array {
+			return "string"
+		}
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return "number"
+}
@@ -0,0 +1,162 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"customize_crm/apperror"
+	"customize_crm/model"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type createRequest struct {
+	Name     string    `json:"name" validate:"required,max=5"`
+	Email    string    `json:"email" validate:"email"`
+	Username string    `json:"username" validate:"username"`
+	Color    string    `json:"color" validate:"hexcolor"`
+	Status   string    `json:"status" validate:"oneof=open closed"`
+	Score    int       `json:"score" validate:"min=1,max=10"`
+	Tags     []string  `json:"tags" validate:"max=2,dive,min=2"`
+	Address  *address  `json:"address"`
+	Contacts []address `json:"contacts" validate:"dive"`
+}
+
+type patchRequest struct {
+	Name    model.Optional[string]  `json:"name" validate:"nonempty,max=5"`
+	Note    model.Optional[string]  `json:"note" validate:"nullable,max=5"`
+	Manager model.Optional[*string] `json:"manager" validate:"nullable"`
+}
+
+func decode(t *testing.T, body string, dst any) error {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	return DecodeJSON(r, dst)
+}
+
+// failures returns the field and code of each failing field.
+func failures(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) || appErr.Status != http.StatusUnprocessableEntity {
+		t.Fatalf("error = %v, want a 422 validation error", err)
+	}
+	var got []string
+	for _, f := range appErr.Fields {
+		got = append(got, f.Field+":"+f.Code)
+	}
+	return got
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"valid", `{"name":"ann","email":"a@example.com","username":"ann.b","color":"#a0B1c2","status":"open","score":10,"tags":["ab"],"address":{"city":"Oslo"},"contacts":[{"city":"Rome"}]}`, nil},
+		{"zero values skip rules", `{"name":"ann"}`, nil},
+		{"missing required", `{}`, []string{"name:required"}},
+		{"blank required", `{"name":"   "}`, []string{"name:required"}},
+		{"max counts runes", `{"name":"ååååå"}`, nil},
+		{"too long", `{"name":"annabel"}`, []string{"name:max"}},
+		{"email with display name", `{"name":"ann","email":"Ann <a@example.com>"}`, []string{"email:email"}},
+		{"username", `{"name":"ann","username":"_ann"}`, []string{"username:username"}},
+		{"hexcolor", `{"name":"ann","color":"#abc"}`, []string{"color:hexcolor"}},
+		{"oneof", `{"name":"ann","status":"pending"}`, []string{"status:oneof"}},
+		{"number range", `{"name":"ann","score":11}`, []string{"score:max"}},
+		{"slice length", `{"name":"ann","tags":["ab","cd","ef"]}`, []string{"tags:max"}},
+		{"dive into strings", `{"name":"ann","tags":["ab","c"]}`, []string{"tags[1]:min"}},
+		{"nested struct", `{"name":"ann","address":{"city":" "}}`, []string{"address.city:required"}},
+		{"dive into structs", `{"name":"ann","contacts":[{"city":"Rome"},{}]}`, []string{"contacts[1].city:required"}},
+		{"every failure reported", `{"email":"nope","score":-1}`, []string{"name:required", "email:email", "score:min"}},
+		{"wrong JSON type", `{"name":1}`, []string{"name:type"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req createRequest
+			if got := failures(t, decode(t, tt.body, &req)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("failures = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptional(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"absent fields are skipped", `{}`, nil},
+		{"present values are validated", `{"name":"annabel","note":"too long"}`, []string{"name:max", "note:max"}},
+		{"nonempty rejects blank", `{"name":" "}`, []string{"name:nonempty"}},
+		{"null needs nullable", `{"name":null}`, []string{"name:null"}},
+		{"nullable accepts null", `{"note":null,"manager":null}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req patchRequest
+			if got := failures(t, decode(t, tt.body, &req)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("failures = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Absent keys leave the stored value alone; null clears a nullable one.
+	var req struct {
+		Name  model.Optional[string] `json:"name"`
+		Title model.Optional[string] `json:"title"`
+		Note  model.Optional[string] `json:"note"`
+	}
+	if err := json.Unmarshal([]byte(`{"name":"bob","note":null}`), &req); err != nil {
+		t.Fatal(err)
+	}
+
+	name, title := "ann", "keep"
+	note := &title
+	req.Name.Apply(&name)
+	req.Title.Apply(&title)
+	req.Note.ApplyPtr(&note)
+	if name != "bob" || title != "keep" || note != nil {
+		t.Errorf("after apply: name %q, title %q, note %v, want bob, keep and nil", name, title, note)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		code   string
+	}{
+		{"empty body", ``, http.StatusBadRequest, "invalid_payload"},
+		{"malformed", `{"name":`, http.StatusBadRequest, "invalid_payload"},
+		{"too large", `{"name":"` + strings.Repeat("a", maxBodyBytes) + `"}`, http.StatusRequestEntityTooLarge, "payload_too_large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req createRequest
+			var appErr *apperror.Error
+			if err := decode(t, tt.body, &req); !errors.As(err, &appErr) {
+				t.Fatalf("error = %v, want an *apperror.Error", err)
+			}
+			if appErr.Status != tt.status || appErr.Code != tt.code {
+				t.Errorf("error = %d %s, want %d %s", appErr.Status, appErr.Code, tt.status, tt.code)
+			}
+		})
+	}
+}